@@ -1,7 +1,9 @@
 package v1beta1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type CapabilityDisplayName string
@@ -80,10 +82,6 @@ type ActiveGateSpec struct {
 	// name. If not specified the setting will be removed from the StatefulSet.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority Class name",order=23,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:PriorityClass"}
 	PriorityClassName string `json:"priorityClassName,omitempty"`
-
-	// Optional: Adds additional annotations to the ActiveGate pods
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Annotations",order=27,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
-	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // CapabilityProperties is a struct which can be embedded by ActiveGate capabilities
@@ -102,8 +100,8 @@ type CapabilityProperties struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Activation group",order=31,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
 	Group string `json:"group,omitempty"`
 
-	// Optional: Add a custom properties file by providing it as a value or reference it from a secret
-	// If referenced from a secret, make sure the key is called 'customProperties'
+	// Optional: Add a custom properties file by providing it as a value or reference it from a secret or config map
+	// If referenced from a secret or config map, make sure the key is called 'customProperties'
 	CustomProperties *DynaKubeValueSource `json:"customProperties,omitempty"`
 
 	// Optional: define resources requests and limits for single ActiveGate pods
@@ -118,10 +116,14 @@ type CapabilityProperties struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations",order=36,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
-	// Optional: Adds additional labels for the ActiveGate pods
+	// Optional: Adds additional labels for the ActiveGate StatefulSet and pods
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels",order=37,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// Optional: Adds additional annotations to the ActiveGate StatefulSet and pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Annotations",order=38,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Annotations map[string]string `json:"annotations,omitempty"`
+
 	// Optional: List of environment variables to set for the ActiveGate
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Environment variables",order=39,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
@@ -132,4 +134,39 @@ type CapabilityProperties struct {
 	// Optional: Adds TopologySpreadConstraints for the ActiveGate pods
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="topologySpreadConstraints",order=40,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Optional: Defines how the ActiveGate pods are updated, defaults to RollingUpdate
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Update strategy",order=41,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	UpdateStrategy *appsv1.StatefulSetUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// Optional: Scales the amount of replicas with the amount of nodes in the cluster, overrides Replicas if set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Auto sizing",order=42,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	AutoSizing *AutoSizingSpec `json:"autoSizing,omitempty"`
+
+	// Optional: Runs the ActiveGate image by the digest resolved from the registry and rolls the pods whenever it changes, defaults to false
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Auto update",order=43,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	AutoUpdate *bool `json:"autoUpdate,omitempty"`
+
+	// Optional: Restricts automatic updates to a recurring maintenance window, without it updates are rolled out as soon as they are found
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Maintenance window",order=44,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+type AutoSizingSpec struct {
+	// Amount of nodes a single ActiveGate replica is responsible for, defaults to 50
+	NodesPerReplica int32 `json:"nodesPerReplica,omitempty"`
+
+	// Minimum amount of replicas, defaults to 1
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// Maximum amount of replicas, unbounded if not set
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+}
+
+type MaintenanceWindow struct {
+	// Cron expression (minute hour day-of-month month day-of-week) at which the window opens, evaluated in UTC
+	Schedule string `json:"schedule"`
+
+	// How long the window stays open after each start, e.g. 2h
+	Duration metav1.Duration `json:"duration"`
 }
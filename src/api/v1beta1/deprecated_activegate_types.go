@@ -1,5 +1,10 @@
 package v1beta1
 
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
 // Deprecated: Use ActiveGateSpec instead
 type RoutingSpec struct {
 	// Enables Capability
@@ -15,5 +20,123 @@ type KubernetesMonitoringSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Capability",order=29,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:booleanSwitch"
 	Enabled bool `json:"enabled,omitempty"`
 
+	// Optional: If specified, indicates the priority of the monitoring pod. Name must be defined by creating a PriorityClass object with that
+	// name. If not specified the priority class of the ActiveGate is used.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority Class name",order=30,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:PriorityClass"}
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Optional: If specified, the monitoring pod runs with this service account instead of the one created by the operator.
+	// The service account must be bound to the RBAC permissions required for Kubernetes monitoring.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service account name",order=31,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ServiceAccount"}
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Optional: Additional volumes for the monitoring pod, their names must not collide with the volumes managed by the operator
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Volumes",order=32,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// Optional: Additional volume mounts for the ActiveGate container, their names and mount paths must not collide with the ones managed by the operator
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Volume mounts",order=33,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Optional: Persists the data directory of the ActiveGate in a PersistentVolumeClaim per pod, so its caches survive restarts.
+	// Without it an emptyDir is used. Changing it recreates the StatefulSet, existing claims are kept.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Volume claim template",order=34,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	VolumeClaimTemplate *corev1.PersistentVolumeClaimSpec `json:"volumeClaimTemplate,omitempty"`
+
+	// Optional: Affinity for the monitoring pod, e.g. to run it on dedicated infrastructure nodes.
+	// If no node affinity is given, the monitoring pod is restricted to the supported architectures and operating systems.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Affinity",order=34,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Optional: Overrides the timings of the readiness probe of the ActiveGate container, unset values keep their defaults
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Readiness probe",order=35,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	ReadinessProbe *ProbeSettings `json:"readinessProbe,omitempty"`
+
+	// Optional: Adds a liveness probe to the ActiveGate container, which checks the same endpoint as the readiness probe.
+	// It starts with the timings of the readiness probe, unset values keep them.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Liveness probe",order=36,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	LivenessProbe *ProbeSettings `json:"livenessProbe,omitempty"`
+
+	// Optional: Additional arguments for the ActiveGate container, a flag replaces the argument of the operator with the same flag name.
+	// Additional environment variables are set via env, the ones managed by the operator take precedence over them.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Arguments",order=37,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	Args []string `json:"args,omitempty"`
+
+	// Optional: Security context of the monitoring pod
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod security context",order=38,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// Optional: Security context of the ActiveGate container, set values replace the hardened defaults of the operator
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Security context",order=39,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// Optional: Time the ActiveGate gets to shut down before it is killed, e.g. to flush the data it collected.
+	// If not specified the default of Kubernetes, 30 seconds, is used.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Termination grace period",order=40,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// Optional: Handler that is run in the ActiveGate container before it is stopped, it has to finish within the termination grace period
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="PreStop hook",order=41,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PreStop *corev1.LifecycleHandler `json:"preStop,omitempty"`
+
+	// Optional: Lets a HorizontalPodAutoscaler owned by the operator scale the monitoring pods, replicas and autoSizing must not be set along with it
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Autoscaling",order=42,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
 	CapabilityProperties `json:",inline"`
 }
+
+// AutoscalingSpec configures the HorizontalPodAutoscaler of the monitoring pods
+type AutoscalingSpec struct {
+	// Optional: Minimum amount of replicas, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// Maximum amount of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Optional: Average CPU utilization of the pods in percent of their requests the autoscaler aims for, defaults to 80.
+	// Ignored if metrics are given.
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// Optional: Metrics the autoscaler scales on instead of the CPU utilization, e.g. the queue size of the ActiveGate exposed via the custom metrics API
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=array
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// ProbeSettings overrides the timings of a probe, the handler of the probe is always managed by the operator
+type ProbeSettings struct {
+	// Optional: Number of seconds after the container has started before the probe is initiated
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// Optional: How often (in seconds) to perform the probe
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// Optional: Number of seconds after which the probe times out
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Optional: Minimum consecutive failures for the probe to be considered failed after having succeeded
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
@@ -39,6 +39,18 @@ type DynaKubeStatus struct {
 	// KubeSystemUUID contains the UUID of the current Kubernetes cluster
 	KubeSystemUUID string `json:"kubeSystemUUID,omitempty"`
 
+	// KubernetesSettingObjectID contains the ID of the Kubernetes settings object created for automatic Kubernetes API monitoring
+	KubernetesSettingObjectID string `json:"kubernetesSettingObjectID,omitempty"`
+
+	// ActiveGateAuthTokenID contains the ID of the ActiveGate auth token created for the DynaKube, it is revoked when the DynaKube is deleted
+	ActiveGateAuthTokenID string `json:"activeGateAuthTokenID,omitempty"`
+
+	// NetworkZone contains the network zone of the spec once it is known to exist in the tenant, so it isn't checked again
+	NetworkZone string `json:"networkZone,omitempty"`
+
+	// KubernetesMonitoring reports the outcome of the registration for automatic Kubernetes API monitoring
+	KubernetesMonitoring KubernetesMonitoringStatus `json:"kubernetesMonitoring,omitempty"`
+
 	// ConnectionInfo caches information about the tenant and its communication hosts
 	ConnectionInfo ConnectionInfoStatus `json:"connectionInfo,omitempty"`
 
@@ -54,12 +66,37 @@ type DynaKubeStatus struct {
 	// Conditions includes status about the current state of the instance
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
+	// LastError contains the error of the last failed reconciliation, it is cleared once a reconciliation succeeds
+	LastError *ErrorStatus `json:"lastError,omitempty"`
+
+	// ActiveGateReady indicates whether all replicas of the ActiveGate StatefulSets are ready
+	ActiveGateReady bool `json:"activeGateReady,omitempty"`
+
 	ActiveGate          ActiveGateStatus `json:"activeGate,omitempty"`
 	ExtensionController EecStatus        `json:"eec,omitempty"`
 	Statsd              StatsdStatus     `json:"statsd,omitempty"`
 	OneAgent            OneAgentStatus   `json:"oneAgent,omitempty"`
 }
 
+type ErrorStatus struct {
+	// Message contains the error message
+	Message string `json:"message,omitempty"`
+
+	// Timestamp indicates when the error occurred first
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+}
+
+type KubernetesMonitoringStatus struct {
+	// RegisteredClusterLabel contains the cluster label the Kubernetes settings object was last created or updated with
+	RegisteredClusterLabel string `json:"registeredClusterLabel,omitempty"`
+
+	// LastError contains the error of the last failed registration, it is cleared once a registration succeeds
+	LastError string `json:"lastError,omitempty"`
+
+	// LastTransitionTimestamp indicates when the outcome of the registration changed last
+	LastTransitionTimestamp *metav1.Time `json:"lastTransitionTimestamp,omitempty"`
+}
+
 type ConnectionInfoStatus struct {
 	CommunicationHosts              []CommunicationHostStatus `json:"communicationHosts,omitempty"`
 	TenantUUID                      string                    `json:"tenantUUID,omitempty"`
@@ -82,11 +119,41 @@ type VersionStatus struct {
 	// ImageHash contains the last image hash seen.
 	ImageHash string `json:"imageHash,omitempty"`
 
+	// ArchitectureHashes contains the image hash per architecture of a multi-arch image, it is empty for single-arch images.
+	ArchitectureHashes map[string]string `json:"architectureHashes,omitempty"`
+
+	// Image contains the fully-qualified image reference pinned to the last image hash seen.
+	Image string `json:"image,omitempty"`
+
+	// ProbedImage contains the image reference of the last version lookup, a different image is probed regardless of the probe interval
+	ProbedImage string `json:"probedImage,omitempty"`
+
 	// Version contains the version to be deployed.
 	Version string `json:"version,omitempty"`
 
 	// LastUpdateProbeTimestamp defines the last timestamp when the querying for updates have been done
 	LastUpdateProbeTimestamp *metav1.Time `json:"lastUpdateProbeTimestamp,omitempty"`
+
+	// UpdateHistory contains the most recent image updates, the oldest entries are dropped first
+	UpdateHistory []ImageUpdate `json:"updateHistory,omitempty"`
+}
+
+// ImageUpdate records a change of the resolved image
+type ImageUpdate struct {
+	// OldVersion contains the version before the update
+	OldVersion string `json:"oldVersion,omitempty"`
+
+	// NewVersion contains the version after the update
+	NewVersion string `json:"newVersion,omitempty"`
+
+	// OldHash contains the image hash before the update
+	OldHash string `json:"oldHash,omitempty"`
+
+	// NewHash contains the image hash after the update
+	NewHash string `json:"newHash,omitempty"`
+
+	// Timestamp defines when the update was found
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
 }
 
 func (verStatus *VersionStatus) Status() VersionStatus {
@@ -165,3 +232,19 @@ func (dk *DynaKubeStatus) SetPhaseOnError(err error) bool {
 	}
 	return false
 }
+
+// SetLastError records err as the last reconciliation error, the timestamp is kept as long as the message doesn't change
+func (dk *DynaKubeStatus) SetLastError(err error, now metav1.Time) {
+	if dk.LastError != nil && dk.LastError.Message == err.Error() {
+		return
+	}
+	dk.LastError = &ErrorStatus{
+		Message:   err.Error(),
+		Timestamp: now,
+	}
+}
+
+// ClearLastError removes the last reconciliation error
+func (dk *DynaKubeStatus) ClearLastError() {
+	dk.LastError = nil
+}
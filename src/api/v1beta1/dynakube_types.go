@@ -16,6 +16,39 @@ const (
 
 	// DataIngestTokenConditionType identifies the DataIngest Token validity condition
 	DataIngestTokenConditionType string = "DataIngestToken"
+
+	// ImageVersionProviderConditionType identifies the condition reporting a non-default image version provider
+	ImageVersionProviderConditionType string = "ImageVersionProvider"
+
+	// KubeSystemUUIDConditionType identifies the condition reporting that the UID of the kube-system namespace could not be read
+	KubeSystemUUIDConditionType string = "KubeSystemUUID"
+
+	// CustomPropertiesConditionType identifies the condition reporting invalid ActiveGate custom properties
+	CustomPropertiesConditionType string = "CustomProperties"
+
+	// PullSecretConditionType identifies the condition reporting whether the pull secret for Dynatrace images is in place
+	PullSecretConditionType string = "PullSecretReady"
+
+	// StatefulSetConditionType identifies the condition reporting whether all ActiveGate replicas are ready
+	StatefulSetConditionType string = "StatefulSetReady"
+
+	// ImageConditionType identifies the condition reporting whether the versions of all used images could be resolved
+	ImageConditionType string = "ImageResolved"
+
+	// ReadyConditionType identifies the condition reporting whether the ActiveGate of the DynaKube is ready to serve traffic
+	ReadyConditionType string = "Ready"
+
+	// PausedConditionType identifies the condition reporting that reconciliation of the DynaKube is paused by annotation
+	PausedConditionType string = "Paused"
+
+	// APIReachableConditionType identifies the condition reporting whether the Dynatrace API can be reached with the api token
+	APIReachableConditionType string = "APIReachable"
+
+	// ImagePullFailedConditionType identifies the condition reporting that ActiveGate pods can't pull their image, it is removed once they can
+	ImagePullFailedConditionType string = "ImagePullFailed"
+
+	// DegradedConditionType identifies the condition reporting that the last reconcile of the DynaKube was aborted by a panic
+	DegradedConditionType string = "Degraded"
 )
 
 // Possible reasons for ApiToken and PaaSToken conditions
@@ -25,6 +58,102 @@ const (
 
 	// ReasonTokenError is set when an unknown error has been found when verifying the token
 	ReasonTokenError string = "TokenError"
+
+	// ReasonTokenScopeMissing is set when a token lacks scopes required by the DynaKube
+	ReasonTokenScopeMissing string = "TokenScopeMissing"
+)
+
+// Possible reasons for KubeSystemUUID condition
+const (
+	// ReasonKubeSystemUUIDUnavailable is set when the kube-system namespace can't be read, e.g. due to missing permissions
+	ReasonKubeSystemUUIDUnavailable string = "KubeSystemUUIDUnavailable"
+)
+
+// Possible reasons for CustomProperties condition
+const (
+	// ReasonCustomPropertiesDuplicateKey is set when a key is defined more than once with conflicting values
+	ReasonCustomPropertiesDuplicateKey string = "CustomPropertiesDuplicateKey"
+
+	// ReasonCustomPropertiesTooLarge is set when the custom properties exceed the maximum size accepted by the ActiveGate
+	ReasonCustomPropertiesTooLarge string = "CustomPropertiesTooLarge"
+
+	// ReasonCustomPropertiesMalformed is set when lines of the custom properties are not valid key=value pairs
+	ReasonCustomPropertiesMalformed string = "CustomPropertiesMalformed"
+
+	// ReasonCustomPropertiesSourceUnavailable is set when the referenced secret or config map or its 'customProperties' key is missing
+	ReasonCustomPropertiesSourceUnavailable string = "CustomPropertiesSourceUnavailable"
+)
+
+// Possible reasons for PullSecretReady condition
+const (
+	// ReasonPullSecretReconciled is set when the pull secret is up to date or a custom pull secret is used
+	ReasonPullSecretReconciled string = "PullSecretReconciled"
+
+	// ReasonPullSecretError is set when the pull secret could not be created or updated
+	ReasonPullSecretError string = "PullSecretError"
+)
+
+// Possible reasons for StatefulSetReady condition
+const (
+	// ReasonReplicasReady is set when all ActiveGate replicas are ready
+	ReasonReplicasReady string = "ReplicasReady"
+
+	// ReasonReplicasNotReady is set when some ActiveGate replicas are not ready yet
+	ReasonReplicasNotReady string = "ReplicasNotReady"
+)
+
+// Possible reasons for Ready condition
+const (
+	// ReasonActiveGateReady is set when all ActiveGate StatefulSets report their desired replicas as ready
+	ReasonActiveGateReady string = "ActiveGateReady"
+
+	// ReasonActiveGateNotReady is set while ActiveGate pods are still starting or failing their readiness probe
+	ReasonActiveGateNotReady string = "ActiveGateNotReady"
+)
+
+// Possible reasons for Paused condition
+const (
+	// ReasonReconcilePaused is set while the DynaKube carries the reconcile-paused annotation
+	ReasonReconcilePaused string = "ReconcilePaused"
+)
+
+// Possible reasons for APIReachable condition
+const (
+	// ReasonAPIReachable is set when the connectivity check against the Dynatrace API succeeded
+	ReasonAPIReachable string = "APIReachable"
+
+	// ReasonAPIUnreachable is set when the Dynatrace API returned an error or could not be reached at all
+	ReasonAPIUnreachable string = "APIUnreachable"
+)
+
+// Possible reasons for ImageResolved condition
+const (
+	// ReasonImagesResolved is set when the versions of all probed images were resolved
+	ReasonImagesResolved string = "ImagesResolved"
+
+	// ReasonImageResolutionFailed is set when the version of at least one image could not be resolved
+	ReasonImageResolutionFailed string = "ImageResolutionFailed"
+)
+
+// Possible reasons for ImageVersionProvider condition
+const (
+	// ReasonImageVersionProviderOverridden is set when the image versions are not resolved by the default provider
+	ReasonImageVersionProviderOverridden string = "ImageVersionProviderOverridden"
+)
+
+// Possible reasons for Degraded condition
+const (
+	// ReasonReconcilePanicked is set when a panic during the reconcile was recovered
+	ReasonReconcilePanicked string = "ReconcilePanicked"
+)
+
+// Possible reasons for ImagePullFailed condition, the waiting reasons of the kubelet
+const (
+	// ReasonErrImagePull is set when pulling the image of an ActiveGate container failed
+	ReasonErrImagePull string = "ErrImagePull"
+
+	// ReasonImagePullBackOff is set when the kubelet backs off pulling the image of an ActiveGate container after failures
+	ReasonImagePullBackOff string = "ImagePullBackOff"
 )
 
 type DynaKubeProxy struct {
@@ -41,6 +170,11 @@ type DynaKubeValueSource struct {
 
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom properties secret",order=33,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:Secret"}
 	ValueFrom string `json:"valueFrom,omitempty"`
+
+	// Optional: Name of a ConfigMap holding the custom properties under the key 'customProperties'
+	// Only one of value, valueFrom and configMapRef may be set. If several are set anyway, valueFrom takes precedence over value, and value over configMapRef
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom properties config map",order=34,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ConfigMap"}
+	ConfigMapRef string `json:"configMapRef,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -82,6 +216,12 @@ type DynaKubeSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom PullSecret",order=8,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:Secret"}
 	CustomPullSecret string `json:"customPullSecret,omitempty"`
 
+	// Optional: Registry (and path) that mirrors the Dynatrace images, e.g. "registry.example.com:5000/dynatrace".
+	// Replaces the host of the API URL in all default images, so "<registryOverride>/linux/activegate:latest" is pulled instead.
+	// Images that are explicitly configured are not changed. Use a custom pull secret to authenticate against the mirror.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Registry override",order=9,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	RegistryOverride string `json:"registryOverride,omitempty"`
+
 	// Disable certificate validation checks for installer download and API communication
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Skip Certificate Check",order=3,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	SkipCertCheck bool `json:"skipCertCheck,omitempty"`
@@ -90,9 +230,8 @@ type DynaKubeSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Proxy",order=3,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	Proxy *DynaKubeProxy `json:"proxy,omitempty"`
 
-	// Optional: Adds custom RootCAs from a configmap
-	// This property only affects certificates used to communicate with the Dynatrace API.
-	// The property is not applied to the ActiveGate
+	// Optional: Adds custom RootCAs from a configmap, the certificates are expected under the key 'certs'.
+	// They are used to communicate with the Dynatrace API and are mounted into the ActiveGate.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Trusted CAs",order=6,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ConfigMap"}
 	TrustedCAs string `json:"trustedCAs,omitempty"`
 
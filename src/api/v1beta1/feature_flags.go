@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
 )
@@ -45,7 +46,10 @@ const (
 	AnnotationFeatureActiveGateReadOnlyFilesystem         = AnnotationFeaturePrefix + "activegate-readonly-fs"
 	AnnotationFeatureAutomaticK8sApiMonitoring            = AnnotationFeaturePrefix + "automatic-kubernetes-api-monitoring"
 	AnnotationFeatureAutomaticK8sApiMonitoringClusterName = AnnotationFeaturePrefix + "automatic-kubernetes-api-monitoring-cluster-name"
+	AnnotationFeatureAutomaticK8sApiMonitoringStatus      = AnnotationFeaturePrefix + "automatic-kubernetes-api-monitoring-status-suffix"
 	AnnotationFeatureActiveGateIgnoreProxy                = AnnotationFeaturePrefix + "activegate-ignore-proxy"
+	AnnotationFeatureActiveGateInjectedContainers         = AnnotationFeaturePrefix + "activegate-injected-containers"
+	AnnotationFeatureActiveGateCustomPropertiesMaxSize    = AnnotationFeaturePrefix + "activegate-custom-properties-max-size"
 
 	// statsD
 
@@ -58,6 +62,7 @@ const (
 	// Deprecated: AnnotationFeatureDisableHostsRequests use AnnotationFeatureHostsRequests instead
 	AnnotationFeatureDisableHostsRequests = AnnotationFeaturePrefix + "disable-hosts-requests"
 	AnnotationFeatureHostsRequests        = AnnotationFeaturePrefix + "hosts-requests"
+	AnnotationFeatureCreateNetworkZone    = AnnotationFeaturePrefix + "create-network-zone"
 
 	// oneAgent
 
@@ -90,10 +95,20 @@ const (
 
 	// CSI
 	AnnotationFeatureMaxFailedCsiMountAttempts = AnnotationFeaturePrefix + "max-csi-mount-attempts"
+
+	// image versions
+	AnnotationFeatureImageProbeInterval    = AnnotationFeaturePrefix + "image-probe-interval"
+	AnnotationFeatureSkipRegistryCertCheck = AnnotationFeaturePrefix + "skip-registry-cert-check"
 )
 
 const (
 	DefaultMaxFailedCsiMountAttempts = 10
+
+	// DefaultImageProbeInterval is the minimum time between two registry lookups for the version of an unchanged image
+	DefaultImageProbeInterval = 15 * time.Minute
+
+	// DefaultCustomPropertiesMaxSize is the maximum size in bytes of the custom.properties file accepted by the ActiveGate
+	DefaultCustomPropertiesMaxSize = 64 * 1024
 )
 
 var (
@@ -115,6 +130,12 @@ func (dk *DynaKube) FeatureDisableHostsRequests() bool {
 	return dk.getDisableFlagWithDeprecatedAnnotation(AnnotationFeatureHostsRequests, AnnotationFeatureDisableHostsRequests)
 }
 
+// FeatureCreateNetworkZone is a feature flag to create the network zone of the spec in Dynatrace if it doesn't exist yet,
+// requires the networkZones.read and networkZones.write scopes on the api token
+func (dk *DynaKube) FeatureCreateNetworkZone() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureCreateNetworkZone) == "true"
+}
+
 // FeatureOneAgentMaxUnavailable is a feature flag to configure maxUnavailable on the OneAgent DaemonSets rolling upgrades.
 func (dk *DynaKube) FeatureOneAgentMaxUnavailable() int {
 	raw := dk.getFeatureFlagRaw(AnnotationFeatureOneAgentMaxUnavailable)
@@ -167,6 +188,30 @@ func (dk *DynaKube) getDefaultIgnoredNamespaces() []string {
 	return defaultIgnoredNamespaces
 }
 
+// FeatureActiveGateInjectedContainers is a feature flag for containers that are added to the ActiveGate StatefulSet by other webhooks
+// and must be kept when the operator updates it.
+// defaults to "[ \"istio-proxy\", \"istio-init\" ]"
+func (dk *DynaKube) FeatureActiveGateInjectedContainers() []string {
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureActiveGateInjectedContainers)
+	if raw == "" {
+		return getDefaultInjectedContainers()
+	}
+	injectedContainers := &[]string{}
+	err := json.Unmarshal([]byte(raw), injectedContainers)
+	if err != nil {
+		log.Error(err, "failed to unmarshal activeGateInjectedContainers feature-flag")
+		return getDefaultInjectedContainers()
+	}
+	return *injectedContainers
+}
+
+func getDefaultInjectedContainers() []string {
+	return []string{
+		"istio-proxy",
+		"istio-init",
+	}
+}
+
 // FeatureAutomaticKubernetesApiMonitoring is a feature flag to enable automatic kubernetes api monitoring,
 // which ensures that settings for this kubernetes cluster exist in Dynatrace
 func (dk *DynaKube) FeatureAutomaticKubernetesApiMonitoring() bool {
@@ -178,6 +223,12 @@ func (dk *DynaKube) FeatureAutomaticKubernetesApiMonitoringClusterName() string
 	return dk.getFeatureFlagRaw(AnnotationFeatureAutomaticK8sApiMonitoringClusterName)
 }
 
+// FeatureAutomaticKubernetesApiMonitoringStatusSuffix is a feature flag to append the ActiveGate version and readiness
+// to the cluster name used for automatic-kubernetes-api-monitoring
+func (dk *DynaKube) FeatureAutomaticKubernetesApiMonitoringStatusSuffix() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureAutomaticK8sApiMonitoringStatus) == "true"
+}
+
 // FeatureDisableMetadataEnrichment is a feature flag to disable metadata enrichment,
 func (dk *DynaKube) FeatureDisableMetadataEnrichment() bool {
 	return dk.getDisableFlagWithDeprecatedAnnotation(AnnotationFeatureMetadataEnrichment, AnnotationFeatureDisableMetadataEnrichment)
@@ -307,3 +358,41 @@ func (dk *DynaKube) FeatureMaxFailedCsiMountAttempts() int {
 
 	return maxCsiMountAttempts
 }
+
+// FeatureImageProbeInterval is a feature flag to configure the minimum time between registry lookups for image versions, e.g. '5m'
+func (dk *DynaKube) FeatureImageProbeInterval() time.Duration {
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureImageProbeInterval)
+	if raw == "" {
+		return DefaultImageProbeInterval
+	}
+
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval < 0 {
+		log.Info("invalid image probe interval, using default", "value", raw)
+		return DefaultImageProbeInterval
+	}
+
+	return interval
+}
+
+// FeatureSkipRegistryCertCheck is a feature flag to disable certificate validation when the operator looks up image versions in the registry.
+// Pulling the images by the kubelet is not affected.
+func (dk *DynaKube) FeatureSkipRegistryCertCheck() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureSkipRegistryCertCheck) == "true"
+}
+
+// FeatureActiveGateCustomPropertiesMaxSize is a feature flag to configure the maximum size in bytes of the ActiveGate custom properties
+func (dk *DynaKube) FeatureActiveGateCustomPropertiesMaxSize() int {
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureActiveGateCustomPropertiesMaxSize)
+	if raw == "" {
+		return DefaultCustomPropertiesMaxSize
+	}
+
+	maxSize, err := strconv.Atoi(raw)
+	if err != nil || maxSize <= 0 {
+		log.Info("invalid custom properties max size, using default", "value", raw)
+		return DefaultCustomPropertiesMaxSize
+	}
+
+	return maxSize
+}
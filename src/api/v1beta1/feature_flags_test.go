@@ -3,6 +3,7 @@ package v1beta1
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -180,6 +181,48 @@ func TestMaxMountAttempts(t *testing.T) {
 	assert.Equal(t, DefaultMaxFailedCsiMountAttempts, dynakube.FeatureMaxFailedCsiMountAttempts())
 }
 
+func TestImageProbeInterval(t *testing.T) {
+	dynakube := createDynakubeWithAnnotation(
+		AnnotationFeatureImageProbeInterval, "5m")
+
+	assert.Equal(t, 5*time.Minute, dynakube.FeatureImageProbeInterval())
+
+	dynakube = createDynakubeWithAnnotation()
+
+	assert.Equal(t, DefaultImageProbeInterval, dynakube.FeatureImageProbeInterval())
+
+	dynakube = createDynakubeWithAnnotation(
+		AnnotationFeatureImageProbeInterval, "5")
+
+	assert.Equal(t, DefaultImageProbeInterval, dynakube.FeatureImageProbeInterval())
+
+	dynakube = createDynakubeWithAnnotation(
+		AnnotationFeatureImageProbeInterval, "-5m")
+
+	assert.Equal(t, DefaultImageProbeInterval, dynakube.FeatureImageProbeInterval())
+}
+
+func TestActiveGateCustomPropertiesMaxSize(t *testing.T) {
+	dynakube := createDynakubeWithAnnotation(
+		AnnotationFeatureActiveGateCustomPropertiesMaxSize, "1024")
+
+	assert.Equal(t, 1024, dynakube.FeatureActiveGateCustomPropertiesMaxSize())
+
+	dynakube = createDynakubeWithAnnotation()
+
+	assert.Equal(t, DefaultCustomPropertiesMaxSize, dynakube.FeatureActiveGateCustomPropertiesMaxSize())
+
+	dynakube = createDynakubeWithAnnotation(
+		AnnotationFeatureActiveGateCustomPropertiesMaxSize, "a")
+
+	assert.Equal(t, DefaultCustomPropertiesMaxSize, dynakube.FeatureActiveGateCustomPropertiesMaxSize())
+
+	dynakube = createDynakubeWithAnnotation(
+		AnnotationFeatureActiveGateCustomPropertiesMaxSize, "0")
+
+	assert.Equal(t, DefaultCustomPropertiesMaxSize, dynakube.FeatureActiveGateCustomPropertiesMaxSize())
+}
+
 func TestDynaKube_FeatureIgnoredNamespaces(t *testing.T) {
 	dynakube := DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
@@ -203,3 +246,19 @@ func TestDynaKube_FeatureIgnoredNamespaces(t *testing.T) {
 
 	assert.True(t, dynakubeNamespaceMatches)
 }
+
+func TestDynaKube_FeatureActiveGateInjectedContainers(t *testing.T) {
+	dynakube := createDynakubeWithAnnotation()
+
+	assert.Equal(t, []string{"istio-proxy", "istio-init"}, dynakube.FeatureActiveGateInjectedContainers())
+
+	dynakube = createDynakubeWithAnnotation(
+		AnnotationFeatureActiveGateInjectedContainers, `["linkerd-proxy"]`)
+
+	assert.Equal(t, []string{"linkerd-proxy"}, dynakube.FeatureActiveGateInjectedContainers())
+
+	dynakube = createDynakubeWithAnnotation(
+		AnnotationFeatureActiveGateInjectedContainers, "linkerd-proxy")
+
+	assert.Equal(t, []string{"istio-proxy", "istio-init"}, dynakube.FeatureActiveGateInjectedContainers())
+}
@@ -0,0 +1,84 @@
+package v1beta1
+
+import (
+	"github.com/containers/image/v5/docker/reference"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	errorConflictingValueSources = "only one of value, valueFrom and configMapRef may be set"
+	errorReplicasWithAutoscaling = "must not be set if autoscaling is enabled, the replicas are managed by the autoscaler"
+)
+
+// SourceCount returns the number of sources set in the DynaKubeValueSource, 0 if it is nil
+func (source *DynaKubeValueSource) SourceCount() int {
+	if source == nil {
+		return 0
+	}
+
+	count := 0
+	for _, value := range []string{source.Value, source.ValueFrom, source.ConfigMapRef} {
+		if value != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// Validate checks the fields of the KubernetesMonitoringSpec that would otherwise only fail during reconciliation.
+// fldPath is the path of the spec within the DynaKube, the returned errors are relative to it.
+func (spec *KubernetesMonitoringSpec) Validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.Image != "" {
+		if _, err := reference.ParseNormalizedNamed(spec.Image); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("image"), spec.Image, err.Error()))
+		}
+	}
+
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if spec.TerminationGracePeriodSeconds != nil && *spec.TerminationGracePeriodSeconds < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("terminationGracePeriodSeconds"), *spec.TerminationGracePeriodSeconds, "must be greater than or equal to 0"))
+	}
+
+	if spec.CustomProperties.SourceCount() > 1 {
+		errs = append(errs, field.Forbidden(fldPath.Child("customProperties"), errorConflictingValueSources))
+	}
+
+	if spec.Autoscaling != nil {
+		errs = append(errs, spec.validateAutoscaling(fldPath)...)
+	}
+
+	return errs
+}
+
+// validateAutoscaling checks that the autoscaler gets a valid range of replicas and is the only source of the replica count
+func (spec *KubernetesMonitoringSpec) validateAutoscaling(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	autoscalingPath := fldPath.Child("autoscaling")
+
+	if spec.Replicas != nil {
+		errs = append(errs, field.Forbidden(fldPath.Child("replicas"), errorReplicasWithAutoscaling))
+	}
+
+	if spec.AutoSizing != nil {
+		errs = append(errs, field.Forbidden(fldPath.Child("autoSizing"), errorReplicasWithAutoscaling))
+	}
+
+	if spec.Autoscaling.MaxReplicas < 1 {
+		errs = append(errs, field.Invalid(autoscalingPath.Child("maxReplicas"), spec.Autoscaling.MaxReplicas, "must be greater than or equal to 1"))
+	}
+
+	if minReplicas := spec.Autoscaling.MinReplicas; minReplicas != nil && (*minReplicas < 1 || *minReplicas > spec.Autoscaling.MaxReplicas) {
+		errs = append(errs, field.Invalid(autoscalingPath.Child("minReplicas"), *minReplicas, "must be between 1 and maxReplicas"))
+	}
+
+	if target := spec.Autoscaling.TargetCPUUtilizationPercentage; target != nil && *target < 1 {
+		errs = append(errs, field.Invalid(autoscalingPath.Child("targetCPUUtilizationPercentage"), *target, "must be greater than or equal to 1"))
+	}
+
+	return errs
+}
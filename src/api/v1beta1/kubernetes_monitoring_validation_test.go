@@ -0,0 +1,168 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestKubernetesMonitoringSpecValidate(t *testing.T) {
+	fldPath := field.NewPath("spec", "kubernetesMonitoring")
+	replicas := func(replicas int32) *int32 { return &replicas }
+	negativeGracePeriod := int64(-1)
+
+	testCases := []struct {
+		name           string
+		spec           KubernetesMonitoringSpec
+		expectedFields []string
+	}{
+		{
+			name: "empty spec",
+			spec: KubernetesMonitoringSpec{},
+		},
+		{
+			name: "valid spec",
+			spec: KubernetesMonitoringSpec{
+				Enabled: true,
+				CapabilityProperties: CapabilityProperties{
+					Image:            "registry.example.com/linux/activegate:1.2.3",
+					Replicas:         replicas(2),
+					CustomProperties: &DynaKubeValueSource{ConfigMapRef: "custom-properties"},
+				},
+			},
+		},
+		{
+			name: "image with digest",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{
+					Image: "registry.example.com/linux/activegate@sha256:7173b809ca12ec5dee4506cd86be934c4596dd234ee82c0662eac04a8c2c71dc",
+				},
+			},
+		},
+		{
+			name: "zero replicas",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{Replicas: replicas(0)},
+			},
+		},
+		{
+			name: "malformed image",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{Image: "registry.example.com/Activegate:1.2.3"},
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.image"},
+		},
+		{
+			name: "image with invalid tag",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{Image: "registry.example.com/activegate:"},
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.image"},
+		},
+		{
+			name: "negative replicas",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{Replicas: replicas(-1)},
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.replicas"},
+		},
+		{
+			name: "negative termination grace period",
+			spec: KubernetesMonitoringSpec{
+				TerminationGracePeriodSeconds: &negativeGracePeriod,
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.terminationGracePeriodSeconds"},
+		},
+		{
+			name: "value and config map as custom properties sources",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{
+					CustomProperties: &DynaKubeValueSource{Value: "[connectivity]", ConfigMapRef: "custom-properties"},
+				},
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.customProperties"},
+		},
+		{
+			name: "value and secret as custom properties sources",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{
+					CustomProperties: &DynaKubeValueSource{Value: "[connectivity]", ValueFrom: "custom-properties"},
+				},
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.customProperties"},
+		},
+		{
+			name: "valid autoscaling",
+			spec: KubernetesMonitoringSpec{
+				Autoscaling: &AutoscalingSpec{MinReplicas: replicas(2), MaxReplicas: 5, TargetCPUUtilizationPercentage: replicas(70)},
+			},
+		},
+		{
+			name: "autoscaling with replicas and auto sizing",
+			spec: KubernetesMonitoringSpec{
+				Autoscaling: &AutoscalingSpec{MaxReplicas: 3},
+				CapabilityProperties: CapabilityProperties{
+					Replicas:   replicas(2),
+					AutoSizing: &AutoSizingSpec{},
+				},
+			},
+			expectedFields: []string{
+				"spec.kubernetesMonitoring.replicas",
+				"spec.kubernetesMonitoring.autoSizing",
+			},
+		},
+		{
+			name: "autoscaling with invalid bounds",
+			spec: KubernetesMonitoringSpec{
+				Autoscaling: &AutoscalingSpec{MinReplicas: replicas(3), MaxReplicas: 2, TargetCPUUtilizationPercentage: replicas(0)},
+			},
+			expectedFields: []string{
+				"spec.kubernetesMonitoring.autoscaling.minReplicas",
+				"spec.kubernetesMonitoring.autoscaling.targetCPUUtilizationPercentage",
+			},
+		},
+		{
+			name: "autoscaling without max replicas",
+			spec: KubernetesMonitoringSpec{
+				Autoscaling: &AutoscalingSpec{},
+			},
+			expectedFields: []string{"spec.kubernetesMonitoring.autoscaling.maxReplicas"},
+		},
+		{
+			name: "all fields invalid",
+			spec: KubernetesMonitoringSpec{
+				CapabilityProperties: CapabilityProperties{
+					Image:            "not a valid image",
+					Replicas:         replicas(-3),
+					CustomProperties: &DynaKubeValueSource{Value: "a", ValueFrom: "b", ConfigMapRef: "c"},
+				},
+			},
+			expectedFields: []string{
+				"spec.kubernetesMonitoring.image",
+				"spec.kubernetesMonitoring.replicas",
+				"spec.kubernetesMonitoring.customProperties",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			errs := testCase.spec.Validate(fldPath)
+
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, testCase.expectedFields, fields)
+		})
+	}
+}
+
+func TestDynaKubeValueSourceSourceCount(t *testing.T) {
+	var nilSource *DynaKubeValueSource
+	assert.Equal(t, 0, nilSource.SourceCount())
+	assert.Equal(t, 0, (&DynaKubeValueSource{}).SourceCount())
+	assert.Equal(t, 1, (&DynaKubeValueSource{ValueFrom: "secret"}).SourceCount())
+	assert.Equal(t, 3, (&DynaKubeValueSource{Value: "a", ValueFrom: "b", ConfigMapRef: "c"}).SourceCount())
+}
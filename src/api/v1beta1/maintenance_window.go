@@ -0,0 +1,43 @@
+package v1beta1
+
+import (
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/cron"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// IsAutoUpdateEnabled returns true if the pods of the capability should follow the image digest resolved from the registry
+func (properties *CapabilityProperties) IsAutoUpdateEnabled() bool {
+	return properties.AutoUpdate != nil && *properties.AutoUpdate
+}
+
+// IsOpen returns true if updates may be rolled out at the given time, a nil window is always open
+func (window *MaintenanceWindow) IsOpen(now time.Time) (bool, error) {
+	if window == nil {
+		return true, nil
+	}
+
+	schedule, err := cron.Parse(window.Schedule)
+	if err != nil {
+		return false, err
+	}
+	return schedule.IsWithin(now.UTC(), window.Duration.Duration), nil
+}
+
+// Validate checks the schedule and duration of the window, fldPath is the path of the window within the DynaKube
+func (window *MaintenanceWindow) Validate(fldPath *field.Path) field.ErrorList {
+	if window == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if _, err := cron.Parse(window.Schedule); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("schedule"), window.Schedule, err.Error()))
+	}
+
+	if window.Duration.Duration <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("duration"), window.Duration.String(), "must be greater than 0"))
+	}
+	return errs
+}
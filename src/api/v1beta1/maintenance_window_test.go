@@ -0,0 +1,67 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestMaintenanceWindowIsOpen(t *testing.T) {
+	start := time.Date(2022, time.October, 3, 22, 0, 0, 0, time.UTC)
+
+	t.Run("nil window is always open", func(t *testing.T) {
+		var window *MaintenanceWindow
+		isOpen, err := window.IsOpen(start)
+		require.NoError(t, err)
+		assert.True(t, isOpen)
+	})
+	t.Run("open for the duration after the schedule", func(t *testing.T) {
+		window := &MaintenanceWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}}
+
+		isOpen, err := window.IsOpen(start.Add(30 * time.Minute))
+		require.NoError(t, err)
+		assert.True(t, isOpen)
+
+		isOpen, err = window.IsOpen(start.Add(time.Hour))
+		require.NoError(t, err)
+		assert.False(t, isOpen)
+	})
+	t.Run("schedule is evaluated in utc", func(t *testing.T) {
+		window := &MaintenanceWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}}
+
+		isOpen, err := window.IsOpen(start.In(time.FixedZone("UTC+2", 2*60*60)))
+		require.NoError(t, err)
+		assert.True(t, isOpen)
+	})
+	t.Run("invalid schedule", func(t *testing.T) {
+		window := &MaintenanceWindow{Schedule: "every night", Duration: metav1.Duration{Duration: time.Hour}}
+
+		_, err := window.IsOpen(start)
+		assert.Error(t, err)
+	})
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	fldPath := field.NewPath("spec", "activeGate", "maintenanceWindow")
+
+	var window *MaintenanceWindow
+	assert.Empty(t, window.Validate(fldPath))
+	assert.Empty(t, (&MaintenanceWindow{Schedule: "0 22 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}}).Validate(fldPath))
+
+	errs := (&MaintenanceWindow{Schedule: "0 25 * * *"}).Validate(fldPath)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "spec.activeGate.maintenanceWindow.schedule", errs[0].Field)
+	assert.Equal(t, "spec.activeGate.maintenanceWindow.duration", errs[1].Field)
+}
+
+func TestCapabilityPropertiesIsAutoUpdateEnabled(t *testing.T) {
+	enabled, disabled := true, false
+
+	assert.False(t, (&CapabilityProperties{}).IsAutoUpdateEnabled())
+	assert.False(t, (&CapabilityProperties{AutoUpdate: &disabled}).IsAutoUpdateEnabled())
+	assert.True(t, (&CapabilityProperties{AutoUpdate: &enabled}).IsAutoUpdateEnabled())
+}
@@ -44,6 +44,32 @@ const (
 	TlsCertKey   = "server.crt"
 )
 
+// AnnotationReconcilePaused stops the operator from reconciling the DynaKube while it is set to "true", e.g. during maintenance
+const AnnotationReconcilePaused = "dynatrace.com/reconcile-paused"
+
+// IsReconcilePaused returns true if reconciliation of the DynaKube is paused by AnnotationReconcilePaused
+func (dk *DynaKube) IsReconcilePaused() bool {
+	return dk.Annotations[AnnotationReconcilePaused] == "true"
+}
+
+// AnnotationForceResync makes the operator apply the desired ActiveGate StatefulSets once, even if their hash didn't change,
+// e.g. to roll out new defaults after an operator upgrade. The operator removes the annotation afterwards.
+const AnnotationForceResync = "dynatrace.com/force-resync"
+
+// IsForceResyncRequested returns true if AnnotationForceResync is set to "true"
+func (dk *DynaKube) IsForceResyncRequested() bool {
+	return dk.Annotations[AnnotationForceResync] == "true"
+}
+
+// AnnotationRefreshImage makes the operator resolve the versions of all images once, regardless of the probe interval,
+// e.g. after a new image was pushed under the same tag. The operator removes the annotation afterwards.
+const AnnotationRefreshImage = "dynatrace.com/refresh-image"
+
+// IsImageRefreshRequested returns true if AnnotationRefreshImage is set to "true"
+func (dk *DynaKube) IsImageRefreshRequested() bool {
+	return dk.Annotations[AnnotationRefreshImage] == "true"
+}
+
 // ApiUrl is a getter for dk.Spec.APIURL
 func (dk *DynaKube) ApiUrl() string {
 	return dk.Spec.APIURL
@@ -62,6 +88,15 @@ func (dk *DynaKube) ApiUrlHost() string {
 	return parsedUrl.Host
 }
 
+// registryHost returns the registry the default images are pulled from,
+// which is dk.Spec.RegistryOverride if set and the host of dk.Spec.APIURL otherwise
+func (dk *DynaKube) registryHost() string {
+	if dk.Spec.RegistryOverride != "" {
+		return strings.TrimSuffix(dk.Spec.RegistryOverride, "/")
+	}
+	return dk.ApiUrlHost()
+}
+
 // NeedsActiveGate returns true when a feature requires ActiveGate instances.
 func (dk *DynaKube) NeedsActiveGate() bool {
 	return dk.DeprecatedActiveGateMode() || dk.ActiveGateMode()
@@ -212,13 +247,13 @@ func (dk *DynaKube) ActiveGateImage() string {
 		return dk.CustomActiveGateImage()
 	}
 
-	apiUrlHost := dk.ApiUrlHost()
+	registryHost := dk.registryHost()
 
-	if apiUrlHost == "" {
+	if registryHost == "" {
 		return ""
 	}
 
-	return apiUrlHost + defaultActiveGateImage
+	return registryHost + defaultActiveGateImage
 }
 
 func (dk *DynaKube) deprecatedActiveGateImage() string {
@@ -245,13 +280,13 @@ func (dk *DynaKube) EecImage() string {
 		return dk.FeatureCustomEecImage()
 	}
 
-	apiUrlHost := dk.ApiUrlHost()
+	registryHost := dk.registryHost()
 
-	if apiUrlHost == "" {
+	if registryHost == "" {
 		return ""
 	}
 
-	return apiUrlHost + defaultEecImage
+	return registryHost + defaultEecImage
 }
 
 // StatsdImage returns the StatsD data source image to be used with the dk DynaKube instance.
@@ -260,13 +295,13 @@ func (dk *DynaKube) StatsdImage() string {
 		return dk.FeatureCustomStatsdImage()
 	}
 
-	apiUrlHost := dk.ApiUrlHost()
+	registryHost := dk.registryHost()
 
-	if apiUrlHost == "" {
+	if registryHost == "" {
 		return ""
 	}
 
-	return apiUrlHost + defaultStatsDImage
+	return registryHost + defaultStatsDImage
 }
 
 func (dk *DynaKube) NeedsReadOnlyOneAgents() bool {
@@ -389,13 +424,13 @@ func (dk *DynaKube) OneAgentImage() string {
 		tag = truncatedVersion
 	}
 
-	apiUrlHost := dk.ApiUrlHost()
+	registryHost := dk.registryHost()
 
-	if apiUrlHost == "" {
+	if registryHost == "" {
 		return ""
 	}
 
-	return fmt.Sprintf("%s/linux/oneagent:%s", apiUrlHost, tag)
+	return fmt.Sprintf("%s/linux/oneagent:%s", registryHost, tag)
 }
 
 func truncateBuildDate(version string) string {
@@ -488,6 +523,11 @@ func (dk *DynaKube) UseActiveGateAuthToken() bool {
 	return dk.FeatureActiveGateAuthToken() && dk.NeedsActiveGate()
 }
 
+// NeedsNetworkZone returns if the network zone of the spec should be created in Dynatrace for the activeGate
+func (dk *DynaKube) NeedsNetworkZone() bool {
+	return dk.FeatureCreateNetworkZone() && dk.Spec.NetworkZone != "" && dk.NeedsActiveGate()
+}
+
 func splitArg(arg string) (key, value string) {
 	split := strings.Split(arg, "=")
 	if len(split) != 2 {
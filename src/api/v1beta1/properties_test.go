@@ -45,6 +45,26 @@ func TestActiveGateImage(t *testing.T) {
 		}}}}
 		assert.Equal(t, customImg, dk.ActiveGateImage())
 	})
+
+	t.Run(`ActiveGateImage with registry override`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL, RegistryOverride: "mirror.example.com/dynatrace/"}}
+		assert.Equal(t, "mirror.example.com/dynatrace/linux/activegate:latest", dk.ActiveGateImage())
+	})
+
+	t.Run(`ActiveGateImage with registry override including a port`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL, RegistryOverride: "mirror.example.com:5000/dynatrace"}}
+		assert.Equal(t, "mirror.example.com:5000/dynatrace/linux/activegate:latest", dk.ActiveGateImage())
+		assert.Equal(t, "mirror.example.com:5000/dynatrace/linux/dynatrace-eec:latest", dk.EecImage())
+		assert.Equal(t, "mirror.example.com:5000/dynatrace/linux/dynatrace-datasource-statsd:latest", dk.StatsdImage())
+	})
+
+	t.Run(`ActiveGateImage with custom image ignores registry override`, func(t *testing.T) {
+		customImg := "registry/my/activegate:latest"
+		dk := DynaKube{Spec: DynaKubeSpec{RegistryOverride: "mirror.example.com:5000", ActiveGate: ActiveGateSpec{CapabilityProperties: CapabilityProperties{
+			Image: customImg,
+		}}}}
+		assert.Equal(t, customImg, dk.ActiveGateImage())
+	})
 }
 
 func TestDynaKube_UseCSIDriver(t *testing.T) {
@@ -418,3 +438,33 @@ func TestGetOneAgentEnvironment(t *testing.T) {
 		assert.Len(t, env, 0)
 	})
 }
+
+func TestIsReconcilePaused(t *testing.T) {
+	t.Run("is false by default", func(t *testing.T) {
+		dynakube := DynaKube{}
+
+		assert.False(t, dynakube.IsReconcilePaused())
+	})
+	t.Run("is true when annotation is set to true", func(t *testing.T) {
+		dynakube := DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					AnnotationReconcilePaused: "true",
+				},
+			},
+		}
+
+		assert.True(t, dynakube.IsReconcilePaused())
+	})
+	t.Run("is false when annotation is set to anything else", func(t *testing.T) {
+		dynakube := DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					AnnotationReconcilePaused: "yes",
+				},
+			},
+		}
+
+		assert.False(t, dynakube.IsReconcilePaused())
+	})
+}
@@ -3,6 +3,7 @@ package operator
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
 	cmdManager "github.com/Dynatrace/dynatrace-operator/src/cmd/manager"
@@ -17,7 +18,17 @@ import (
 )
 
 const (
-	use = "operator"
+	use                     = "operator"
+	FlagDynatraceApiTimeout = "dynatrace-api-timeout"
+	FlagReconcileInterval   = "reconcile-interval"
+
+	defaultDynatraceApiTimeout = 30 * time.Second
+	defaultReconcileInterval   = 30 * time.Minute
+)
+
+var (
+	dynatraceApiTimeout time.Duration
+	reconcileInterval   time.Duration
 )
 
 type CommandBuilder struct {
@@ -71,7 +82,7 @@ func (builder CommandBuilder) setClient(client client.Client) CommandBuilder {
 
 func (builder CommandBuilder) getOperatorManagerProvider(isDeployedByOlm bool) cmdManager.Provider {
 	if builder.operatorManagerProvider == nil {
-		builder.operatorManagerProvider = NewOperatorManagerProvider(isDeployedByOlm)
+		builder.operatorManagerProvider = NewOperatorManagerProvider(isDeployedByOlm, dynatraceApiTimeout, reconcileInterval)
 	}
 
 	return builder.operatorManagerProvider
@@ -93,10 +104,19 @@ func (builder CommandBuilder) getSignalHandler() context.Context {
 }
 
 func (builder CommandBuilder) Build() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:  use,
 		RunE: builder.buildRun(),
 	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().DurationVar(&dynatraceApiTimeout, FlagDynatraceApiTimeout, defaultDynatraceApiTimeout, "Timeout for requests to the Dynatrace API, 0 disables it.")
+	cmd.PersistentFlags().DurationVar(&reconcileInterval, FlagReconcileInterval, defaultReconcileInterval, "Interval in which every DynaKube is reconciled again after a successful reconcile, so manual changes to the deployed objects are reverted. Every reconcile calls the Dynatrace API.")
 }
 
 func (builder CommandBuilder) setClientFromConfig(kubeCfg *rest.Config) (CommandBuilder, error) {
@@ -31,6 +31,15 @@ func TestCommandBuilder(t *testing.T) {
 		assert.Equal(t, use, operatorCommand.Use)
 		assert.NotNil(t, operatorCommand.RunE)
 	})
+	t.Run("reconcile interval flag", func(t *testing.T) {
+		operatorCommand := NewOperatorCommandBuilder().Build()
+
+		flag := operatorCommand.PersistentFlags().Lookup(FlagReconcileInterval)
+
+		if assert.NotNil(t, flag) {
+			assert.Equal(t, defaultReconcileInterval.String(), flag.DefValue)
+		}
+	})
 	t.Run("set config provider", func(t *testing.T) {
 		builder := NewOperatorCommandBuilder()
 
@@ -1,6 +1,8 @@
 package operator
 
 import (
+	"time"
+
 	cmdManager "github.com/Dynatrace/dynatrace-operator/src/cmd/manager"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
@@ -42,12 +44,16 @@ func (provider bootstrapManagerProvider) CreateManager(namespace string, config
 }
 
 type operatorManagerProvider struct {
-	deployedViaOlm bool
+	deployedViaOlm      bool
+	dynatraceApiTimeout time.Duration
+	reconcileInterval   time.Duration
 }
 
-func NewOperatorManagerProvider(deployedViaOlm bool) cmdManager.Provider {
+func NewOperatorManagerProvider(deployedViaOlm bool, dynatraceApiTimeout time.Duration, reconcileInterval time.Duration) cmdManager.Provider {
 	return operatorManagerProvider{
-		deployedViaOlm: deployedViaOlm,
+		deployedViaOlm:      deployedViaOlm,
+		dynatraceApiTimeout: dynatraceApiTimeout,
+		reconcileInterval:   reconcileInterval,
 	}
 }
 
@@ -68,7 +74,7 @@ func (provider operatorManagerProvider) CreateManager(namespace string, cfg *res
 		return nil, err
 	}
 
-	err = dynakube.Add(mgr, namespace)
+	err = dynakube.Add(mgr, namespace, provider.dynatraceApiTimeout, provider.reconcileInterval)
 	if err != nil {
 		return nil, err
 	}
@@ -6,6 +6,8 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type baseFunc func() *capabilityBase
@@ -48,8 +50,13 @@ func (c *capabilityBase) ArgName() string {
 	return c.argName
 }
 
+// maxStatefulSetNameLength keeps the controller-revision-hash label of the pods, '<name>-<10 character hash>', within 63 characters
+const maxStatefulSetNameLength = 52
+
+// CalculateStatefulSetName returns '<dynakube name>-<capability>', names that are too long are truncated and
+// get a hash of the full name as suffix, so they stay unique and stable across reconciles
 func CalculateStatefulSetName(capability Capability, dynakubeName string) string {
-	return dynakubeName + "-" + capability.ShortName()
+	return kubeobjects.TruncateName(dynakubeName+"-"+capability.ShortName(), maxStatefulSetNameLength)
 }
 
 // Deprecated: Use MultiCapability instead
@@ -166,13 +173,17 @@ func GenerateActiveGateCapabilities(dynakube *dynatracev1beta1.DynaKube) []Capab
 }
 
 func BuildEecConfigMapName(dynakubeName string, module string) string {
-	return regexp.MustCompile(`[^\w\-]`).ReplaceAllString(dynakubeName+"-"+module+"-eec-config", "_")
+	name := kubeobjects.TruncateName(dynakubeName+"-"+module+"-eec-config", validation.DNS1123SubdomainMaxLength)
+	return regexp.MustCompile(`[^\w\-]`).ReplaceAllString(name, "_")
 }
 
-func BuildProxySecretName() string {
-	return "dynatrace" + "-" + consts.MultiActiveGateName + "-" + consts.ProxySecretSuffix
+// BuildProxySecretName returns the name of the parsed proxy secret of the given DynaKube,
+// it is prefixed with the DynaKube name so multiple DynaKubes in the same namespace don't share it
+func BuildProxySecretName(dynakubeName string) string {
+	return dynakubeName + "-" + consts.MultiActiveGateName + "-" + consts.ProxySecretSuffix
 }
 
+// BuildServiceName returns '<dynakube name>-<module>', truncated like the StatefulSet name if it exceeds the limit of service names
 func BuildServiceName(dynakubeName string, module string) string {
-	return dynakubeName + "-" + module
+	return kubeobjects.TruncateName(dynakubeName+"-"+module, validation.DNS1035LabelMaxLength)
 }
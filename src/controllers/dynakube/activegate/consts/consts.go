@@ -40,6 +40,15 @@ const (
 	AnnotationActiveGateConfigurationHash = dynatracev1beta1.InternalFlagPrefix + "activegate-configuration-hash"
 	AnnotationActiveGateContainerAppArmor = "container.apparmor.security.beta.kubernetes.io/" + ActiveGateContainerName
 
+	// AnnotationVolumeClaimTemplateHash holds the hash of the volume claim templates, which can't be updated in place
+	AnnotationVolumeClaimTemplateHash = dynatracev1beta1.InternalFlagPrefix + "volume-claim-template-hash"
+
+	// AnnotationPullSecretHash holds the hash of the pull secret contents, so rotated credentials roll the ActiveGate pods
+	AnnotationPullSecretHash = dynatracev1beta1.InternalFlagPrefix + "pull-secret-hash"
+
+	// AnnotationActiveGateRestart can be set on the DynaKube, changing its value triggers a rolling restart of the ActiveGate pods
+	AnnotationActiveGateRestart = "dynatrace.com/restart"
+
 	InternalProxySecretMountPath = "/var/lib/dynatrace/secrets/internal-proxy"
 
 	InternalProxySecretVolumeName = "internal-proxy-secret-volume"
@@ -68,4 +77,21 @@ const (
 	GatewaySslMountPoint     = "/var/lib/dynatrace/gateway/ssl"
 	GatewayLogMountPoint     = "/var/log/dynatrace/gateway"
 	GatewayTmpMountPoint     = "/var/tmp/dynatrace/gateway"
+
+	TrustedCAsVolumeName = "trusted-cas"
+	TrustedCAsMountPoint = "/var/lib/dynatrace/secrets/rootca"
+	TrustedCAsFile       = "rootca.pem"
 )
+
+// ReservedEnvVars are always set on the ActiveGate container by the operator and can't be overridden by the user
+var ReservedEnvVars = []string{
+	EnvDtServer,
+	EnvDtTenant,
+	EnvDtCapabilities,
+	EnvDtIdSeedNamespace,
+	EnvDtIdSeedClusterId,
+	EnvDtDeploymentMetadata,
+	EnvDtDnsEntryPoint,
+	EnvDtGroup,
+	EnvDtNetworkZone,
+}
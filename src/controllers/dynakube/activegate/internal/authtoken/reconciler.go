@@ -67,15 +67,35 @@ func (r *Reconciler) reconcileAuthTokenSecret() error {
 	}
 	if isSecretOutdated(&secret) {
 		log.Info("activeGateAuthToken is outdated, creating new one")
+		r.revokeAuthToken(&secret)
 		if err := r.deleteSecret(&secret); err != nil {
 			return errors.WithStack(err)
 		}
 		return r.ensureAuthTokenSecret()
 	}
 
+	if r.dynakube.Status.ActiveGateAuthTokenID == "" {
+		// tokens created by older versions of the operator have no id in the status yet
+		r.dynakube.Status.ActiveGateAuthTokenID = dtclient.ActiveGateAuthTokenID(string(secret.Data[ActiveGateAuthTokenName]))
+	}
+
 	return nil
 }
 
+// revokeAuthToken revokes the token of the outdated secret, failures are only logged as the token expires on its own
+func (r *Reconciler) revokeAuthToken(secret *corev1.Secret) {
+	tokenID := dtclient.ActiveGateAuthTokenID(string(secret.Data[ActiveGateAuthTokenName]))
+	if tokenID == "" {
+		return
+	}
+
+	if err := r.dtc.DeleteActiveGateAuthToken(tokenID); err != nil {
+		log.Info("could not revoke outdated activeGateAuthToken, it will expire on its own", "authTokenID", tokenID, "error", err.Error())
+		return
+	}
+	log.Info("revoked outdated activeGateAuthToken", "authTokenID", tokenID)
+}
+
 func (r *Reconciler) ensureAuthTokenSecret() error {
 	agSecretData, err := r.getActiveGateAuthToken()
 	if err != nil {
@@ -89,6 +109,7 @@ func (r *Reconciler) getActiveGateAuthToken() (map[string][]byte, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	r.dynakube.Status.ActiveGateAuthTokenID = authTokenInfo.TokenId
 	return map[string][]byte{
 		ActiveGateAuthTokenName: []byte(authTokenInfo.Token),
 	}, nil
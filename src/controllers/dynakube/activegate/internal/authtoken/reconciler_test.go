@@ -22,6 +22,7 @@ const (
 	testNamespace    = "test-namespace"
 	secretName       = testDynakubeName + dynatracev1beta1.AuthTokenSecretSuffix
 	testToken        = "dt.testtoken.test"
+	testTokenID      = "dt.testtoken"
 )
 
 var (
@@ -43,6 +44,7 @@ func newTestReconcilerWithInstance(client client.Client) *Reconciler {
 	}
 	dtc := &dtclient.MockDynatraceClient{}
 	dtc.On("GetActiveGateAuthToken", mock.Anything).Return(testAgAuthTokenResponse, nil)
+	dtc.On("DeleteActiveGateAuthToken", mock.Anything).Return(nil)
 
 	r := NewReconciler(client, client, scheme.Scheme, instance, dtc)
 	return r
@@ -58,6 +60,7 @@ func TestReconcile(t *testing.T) {
 		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: r.dynakube.ActiveGateAuthTokenSecret(), Namespace: testNamespace}, &authToken)
 
 		assert.NotEmpty(t, authToken.Data[ActiveGateAuthTokenName])
+		assert.Equal(t, testAgAuthTokenResponse.TokenId, r.dynakube.Status.ActiveGateAuthTokenID)
 	})
 	t.Run(`reconcile outdated auth token`, func(t *testing.T) {
 		clt := fake.NewClientBuilder().
@@ -80,6 +83,8 @@ func TestReconcile(t *testing.T) {
 		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: r.dynakube.ActiveGateAuthTokenSecret(), Namespace: testNamespace}, &authToken)
 
 		assert.NotEqual(t, authToken.Data[ActiveGateAuthTokenName], []byte(testToken))
+		assert.Equal(t, testAgAuthTokenResponse.TokenId, r.dynakube.Status.ActiveGateAuthTokenID)
+		r.dtc.(*dtclient.MockDynatraceClient).AssertCalled(t, "DeleteActiveGateAuthToken", testTokenID)
 	})
 	t.Run(`reconcile valid auth token`, func(t *testing.T) {
 		clt := fake.NewClientBuilder().
@@ -102,5 +107,7 @@ func TestReconcile(t *testing.T) {
 		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: r.dynakube.ActiveGateAuthTokenSecret(), Namespace: testNamespace}, &authToken)
 
 		assert.Equal(t, authToken.Data[ActiveGateAuthTokenName], []byte(testToken))
+		assert.Equal(t, testTokenID, r.dynakube.Status.ActiveGateAuthTokenID)
+		r.dtc.(*dtclient.MockDynatraceClient).AssertNotCalled(t, "DeleteActiveGateAuthToken", mock.Anything)
 	})
 }
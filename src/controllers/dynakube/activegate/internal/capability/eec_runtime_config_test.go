@@ -2,12 +2,14 @@ package capability
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const testApiUrl = "https://demo.dev.dynatracelabs.com/api"
@@ -57,6 +59,20 @@ func TestCreateEecConfigMap(t *testing.T) {
 		assert.Empty(t, runtimeConfig.LongMap)
 	})
 
+	t.Run("long dynakube names are truncated", func(t *testing.T) {
+		longName := strings.Repeat("dynakube-", 26) + "dynakube"
+		require.Empty(t, validation.IsDNS1123Subdomain(longName))
+		instance := testBuildDynaKubeWithAnnotations(longName, true, nil)
+
+		eecConfigMap, err := CreateEecConfigMap(instance, "activegate")
+		require.NoError(t, err)
+
+		assert.Empty(t, validation.IsDNS1123Subdomain(eecConfigMap.Name))
+		for _, value := range eecConfigMap.Labels {
+			assert.Empty(t, validation.IsValidLabelValue(value))
+		}
+	})
+
 	t.Run("no valid EEC runtime properties, StatsD enabled", func(t *testing.T) {
 		instance := testBuildDynaKubeWithAnnotations("dynakube", true, map[string]string{
 			dynatracev1beta1.AnnotationFeaturePrefix + "debugExtensionDSstatsdlogoutboundminttraffic": "true",
@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -38,28 +39,41 @@ func NewReconciler(clt client.Client, capability capability.Capability, dynakube
 
 type NewReconcilerFunc = func(clt client.Client, capability capability.Capability, dynakube *dynatracev1beta1.DynaKube, statefulsetReconciler controllers.Reconciler, customPropertiesReconciler controllers.Reconciler) *Reconciler
 
+// Reconcile continues after a failed step and returns the combined errors of all failed steps,
+// so a misconfiguration in one of them doesn't hide the next one.
+// Custom properties, the service and the EEC config map are continuable, the StatefulSet is still reconciled if they fail.
+// Hard prerequisites (tokens, pull secret, auth token and proxy secret) are reconciled before and short-circuit.
 func (r *Reconciler) Reconcile() error {
+	var errs []error
+
 	err := r.customPropertiesReconciler.Reconcile()
 	if err != nil {
-		return errors.WithStack(err)
+		log.Info("could not reconcile custom properties", "module", r.capability.ShortName())
+		errs = append(errs, errors.WithMessage(err, "failed to reconcile custom properties"))
 	}
 
 	if r.dynakube.NeedsActiveGateServicePorts() {
 		err = r.createOrUpdateService()
 		if err != nil {
-			return errors.WithStack(err)
+			log.Info("could not reconcile AG service", "module", r.capability.ShortName())
+			errs = append(errs, errors.WithMessage(err, "failed to reconcile service"))
 		}
 	}
 
 	if r.dynakube.IsStatsdActiveGateEnabled() {
 		err = r.createOrUpdateEecConfigMap()
 		if err != nil {
-			return errors.WithStack(err)
+			log.Info("could not reconcile EEC config map", "module", r.capability.ShortName())
+			errs = append(errs, errors.WithMessage(err, "failed to reconcile extension controller config map"))
 		}
 	}
 
 	err = r.statefulsetReconciler.Reconcile()
-	return errors.WithStack(err)
+	if err != nil {
+		errs = append(errs, errors.WithMessage(err, "failed to reconcile stateful set"))
+	}
+
+	return utilerrors.NewAggregate(errs)
 }
 
 func (r *Reconciler) createOrUpdateService() error {
@@ -0,0 +1,66 @@
+package capability
+
+import (
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type stubReconciler struct {
+	err   error
+	calls int
+}
+
+func (reconciler *stubReconciler) Reconcile() error {
+	reconciler.calls++
+	return reconciler.err
+}
+
+func newReconcilerWithStubs(customPropertiesReconciler, statefulsetReconciler *stubReconciler) *Reconciler {
+	dynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-namespace",
+		},
+	}
+	return NewReconciler(fake.NewClient(), capability.NewMultiCapability(dynakube), dynakube, statefulsetReconciler, customPropertiesReconciler)
+}
+
+func TestReconcile_AggregatesErrors(t *testing.T) {
+	t.Run("no error if all steps succeed", func(t *testing.T) {
+		customPropertiesReconciler := &stubReconciler{}
+		statefulsetReconciler := &stubReconciler{}
+
+		err := newReconcilerWithStubs(customPropertiesReconciler, statefulsetReconciler).Reconcile()
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, customPropertiesReconciler.calls)
+		assert.Equal(t, 1, statefulsetReconciler.calls)
+	})
+	t.Run("stateful set is reconciled if custom properties fail", func(t *testing.T) {
+		customPropertiesReconciler := &stubReconciler{err: fmt.Errorf("malformed custom properties")}
+		statefulsetReconciler := &stubReconciler{}
+
+		err := newReconcilerWithStubs(customPropertiesReconciler, statefulsetReconciler).Reconcile()
+
+		require.Error(t, err)
+		assert.Equal(t, 1, statefulsetReconciler.calls)
+		assert.Equal(t, "failed to reconcile custom properties: malformed custom properties", err.Error())
+	})
+	t.Run("errors of all failed steps are returned", func(t *testing.T) {
+		customPropertiesReconciler := &stubReconciler{err: fmt.Errorf("malformed custom properties")}
+		statefulsetReconciler := &stubReconciler{err: fmt.Errorf("invalid stateful set")}
+
+		err := newReconcilerWithStubs(customPropertiesReconciler, statefulsetReconciler).Reconcile()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to reconcile custom properties: malformed custom properties")
+		assert.Contains(t, err.Error(), "failed to reconcile stateful set: invalid stateful set")
+	})
+}
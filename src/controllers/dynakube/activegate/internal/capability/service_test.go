@@ -12,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -76,6 +77,20 @@ func TestCreateService(t *testing.T) {
 		assert.Equal(t, corev1.ServiceTypeClusterIP, serviceSpec.Type)
 		assert.Equal(t, expectedSelector, serviceSpec.Selector)
 	})
+	t.Run("long dynakube names are truncated", func(t *testing.T) {
+		instance := testCreateInstance()
+		instance.Name = "dynakube-with-a-very-long-name-that-exceeds-the-kubernetes-limits"
+		service := CreateService(instance, testComponentFeature)
+
+		assert.Empty(t, validation.IsDNS1035Label(service.Name))
+		assert.Equal(t, service.Name, CreateService(instance, testComponentFeature).Name)
+		for _, value := range service.Labels {
+			assert.Empty(t, validation.IsValidLabelValue(value))
+		}
+		for _, value := range service.Spec.Selector {
+			assert.Empty(t, validation.IsValidLabelValue(value))
+		}
+	})
 
 	t.Run("check AG service if metrics ingest enabled, but not StatsD", func(t *testing.T) {
 		instance := testCreateInstance()
@@ -0,0 +1,52 @@
+package customproperties
+
+import (
+	"bufio"
+	"strings"
+)
+
+const (
+	commentPrefix      = "#"
+	altCommentPrefix   = "!"
+	sectionPrefix      = "["
+	sectionSuffix      = "]"
+	keyValueSeparators = "=:"
+)
+
+// findConflictingKey returns the first key that is defined more than once with different values.
+// Keys are scoped by the section ([section]) they are defined in, so the same key may appear in different sections.
+func findConflictingKey(customProperties string) (string, bool) {
+	values := map[string]string{}
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(customProperties))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, commentPrefix) || strings.HasPrefix(line, altCommentPrefix) {
+			continue
+		}
+
+		if strings.HasPrefix(line, sectionPrefix) && strings.HasSuffix(line, sectionSuffix) {
+			section = strings.TrimSpace(line[len(sectionPrefix) : len(line)-len(sectionSuffix)])
+			continue
+		}
+
+		key, value := line, ""
+		if separatorIndex := strings.IndexAny(line, keyValueSeparators); separatorIndex >= 0 {
+			key = strings.TrimSpace(line[:separatorIndex])
+			value = strings.TrimSpace(line[separatorIndex+1:])
+		}
+
+		qualifiedKey := key
+		if section != "" {
+			qualifiedKey = sectionPrefix + section + sectionSuffix + key
+		}
+
+		if previousValue, exists := values[qualifiedKey]; exists && previousValue != value {
+			return qualifiedKey, true
+		}
+		values[qualifiedKey] = value
+	}
+
+	return "", false
+}
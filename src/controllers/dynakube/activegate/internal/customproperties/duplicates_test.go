@@ -0,0 +1,42 @@
+package customproperties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindConflictingKey(t *testing.T) {
+	t.Run(`no duplicates`, func(t *testing.T) {
+		_, found := findConflictingKey("[connectivity]\nnetworkZone=zone\ndnsEntryPoint=https://example.com")
+
+		assert.False(t, found)
+	})
+	t.Run(`duplicates with same value are accepted`, func(t *testing.T) {
+		_, found := findConflictingKey("[connectivity]\nnetworkZone=zone\nnetworkZone = zone")
+
+		assert.False(t, found)
+	})
+	t.Run(`same key in different sections is accepted`, func(t *testing.T) {
+		_, found := findConflictingKey("[collector]\nenabled=true\n[kubernetes_monitoring]\nenabled=false")
+
+		assert.False(t, found)
+	})
+	t.Run(`comments are ignored`, func(t *testing.T) {
+		_, found := findConflictingKey("# enabled=true\n! enabled=false\nenabled=true")
+
+		assert.False(t, found)
+	})
+	t.Run(`conflicting duplicate is detected`, func(t *testing.T) {
+		key, found := findConflictingKey("[connectivity]\nnetworkZone=zone\n[collector]\nenabled=true\n[connectivity]\nnetworkZone:other-zone")
+
+		assert.True(t, found)
+		assert.Equal(t, "[connectivity]networkZone", key)
+	})
+	t.Run(`conflicting duplicate without section is detected`, func(t *testing.T) {
+		key, found := findConflictingKey("key=a\nkey=b")
+
+		assert.True(t, found)
+		assert.Equal(t, "key", key)
+	})
+}
@@ -3,14 +3,20 @@ package customproperties
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -26,84 +32,198 @@ const (
 var _ controllers.Reconciler = &Reconciler{}
 
 type Reconciler struct {
+	ctx                       context.Context
 	client                    client.Client
 	scheme                    *runtime.Scheme
 	customPropertiesSource    *dynatracev1beta1.DynaKubeValueSource
 	customPropertiesOwnerName string
 	instance                  *dynatracev1beta1.DynaKube
+	log                       logr.Logger
 }
 
-func NewReconciler(clt client.Client, instance *dynatracev1beta1.DynaKube, customPropertiesOwnerName string, scheme *runtime.Scheme, customPropertiesSource *dynatracev1beta1.DynaKubeValueSource) *Reconciler {
+func NewReconciler(ctx context.Context, clt client.Client, instance *dynatracev1beta1.DynaKube, customPropertiesOwnerName string, scheme *runtime.Scheme, customPropertiesSource *dynatracev1beta1.DynaKubeValueSource) *Reconciler {
 	return &Reconciler{
+		ctx:                       ctx,
 		client:                    clt,
 		instance:                  instance,
 		scheme:                    scheme,
 		customPropertiesSource:    customPropertiesSource,
 		customPropertiesOwnerName: customPropertiesOwnerName,
+		log:                       logger.FromContext(ctx, log),
 	}
 }
 
 func (r *Reconciler) Reconcile() error {
 	if r.customPropertiesSource == nil {
+		meta.RemoveStatusCondition(&r.instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
+		return r.deleteCustomPropertiesIfUnused()
+	}
+
+	if r.customPropertiesSource.ValueFrom != "" {
+		customProperties, err := r.getDataFromSecret()
+		if err != nil {
+			r.log.Error(err, "could not read custom properties secret", "owner", r.customPropertiesOwnerName)
+			return err
+		}
+
+		err = r.validate(customProperties)
+		if err != nil {
+			r.log.Error(err, "invalid custom properties", "owner", r.customPropertiesOwnerName)
+			return err
+		}
+		return r.deleteCustomPropertiesIfUnused()
+	}
+
+	customProperties, err := r.getManagedCustomProperties()
+	if err != nil {
+		r.log.Error(err, "could not read custom properties config map", "owner", r.customPropertiesOwnerName)
+		return err
+	}
+	if customProperties == "" {
 		return nil
 	}
 
-	if r.hasCustomPropertiesValueOnly() {
-		mustNotUpdate, err := r.createCustomPropertiesIfNotExists()
+	err = r.validate(customProperties)
+	if err != nil {
+		r.log.Error(err, "invalid custom properties", "owner", r.customPropertiesOwnerName)
+		return err
+	}
+
+	mustNotUpdate, err := r.createCustomPropertiesIfNotExists(customProperties)
+	if err != nil {
+		r.log.Error(err, "could not create custom properties", "owner", r.customPropertiesOwnerName)
+		return errors.WithStack(err)
+	}
+
+	if !mustNotUpdate {
+		err = r.updateCustomPropertiesIfOutdated(customProperties)
 		if err != nil {
-			log.Error(err, "could not create custom properties", "owner", r.customPropertiesOwnerName)
+			r.log.Error(err, "could not update custom properties", "owner", r.customPropertiesOwnerName)
 			return errors.WithStack(err)
 		}
+	}
 
-		if !mustNotUpdate {
-			err = r.updateCustomPropertiesIfOutdated()
-			if err != nil {
-				log.Error(err, "could not update custom properties", "owner", r.customPropertiesOwnerName)
-				return errors.WithStack(err)
-			}
+	return r.deleteObsoleteCustomProperties(r.buildCustomPropertiesName(r.instance.Name))
+}
+
+// getManagedCustomProperties returns the custom properties that are copied to the secret managed by the operator.
+// An inline value takes precedence over a config map, setting both is rejected by the webhook.
+func (r *Reconciler) getManagedCustomProperties() (string, error) {
+	if r.customPropertiesSource.Value != "" {
+		if r.customPropertiesSource.ConfigMapRef != "" {
+			r.log.Info("custom properties are set inline and from a config map, using the inline value",
+				"owner", r.customPropertiesOwnerName, "configMap", r.customPropertiesSource.ConfigMapRef)
 		}
+		return r.customPropertiesSource.Value, nil
 	}
 
+	if r.customPropertiesSource.ConfigMapRef != "" {
+		return r.getDataFromConfigMap()
+	}
+	return "", nil
+}
+
+func (r *Reconciler) getDataFromSecret() (string, error) {
+	customProperties, err := kubeobjects.GetDataFromSecretName(r.ctx, r.client,
+		types.NamespacedName{Name: r.customPropertiesSource.ValueFrom, Namespace: r.instance.Namespace}, DataKey, log)
+	if err != nil {
+		r.setCustomPropertiesCondition(dynatracev1beta1.ReasonCustomPropertiesSourceUnavailable, err.Error())
+		return "", err
+	}
+	return customProperties, nil
+}
+
+func (r *Reconciler) getDataFromConfigMap() (string, error) {
+	var configMap corev1.ConfigMap
+	err := r.client.Get(r.ctx,
+		client.ObjectKey{Name: r.customPropertiesSource.ConfigMapRef, Namespace: r.instance.Namespace}, &configMap)
+	if err != nil {
+		r.setCustomPropertiesCondition(dynatracev1beta1.ReasonCustomPropertiesSourceUnavailable, err.Error())
+		return "", errors.WithStack(err)
+	}
+
+	customProperties, ok := configMap.Data[DataKey]
+	if !ok {
+		err = errors.Errorf("config map %s does not contain the key %s", configMap.Name, DataKey)
+		r.setCustomPropertiesCondition(dynatracev1beta1.ReasonCustomPropertiesSourceUnavailable, err.Error())
+		return "", err
+	}
+	return customProperties, nil
+}
+
+// validate rejects custom properties the ActiveGate can't handle and reports the outcome in the CustomProperties condition.
+// Malformed lines are only reported, as the ActiveGate still starts with them.
+func (r *Reconciler) validate(customProperties string) error {
+	maxSize := r.instance.FeatureActiveGateCustomPropertiesMaxSize()
+	if len(customProperties) > maxSize {
+		err := errors.Errorf("custom properties of %s are %d bytes, which exceeds the maximum of %d bytes", r.customPropertiesOwnerName, len(customProperties), maxSize)
+		r.setCustomPropertiesCondition(dynatracev1beta1.ReasonCustomPropertiesTooLarge, err.Error())
+		return err
+	}
+
+	if key, found := findConflictingKey(customProperties); found {
+		err := errors.Errorf("custom properties of %s define key %s more than once with different values", r.customPropertiesOwnerName, key)
+		r.setCustomPropertiesCondition(dynatracev1beta1.ReasonCustomPropertiesDuplicateKey, err.Error())
+		return err
+	}
+
+	if lineNumber, line, found := findMalformedLine(customProperties); found {
+		message := fmt.Sprintf("line %d of the custom properties of %s is not a key=value pair: %s", lineNumber, r.customPropertiesOwnerName, line)
+		r.log.Info("malformed custom properties", "owner", r.customPropertiesOwnerName, "line", lineNumber)
+		r.setCustomPropertiesCondition(dynatracev1beta1.ReasonCustomPropertiesMalformed, message)
+		return nil
+	}
+
+	meta.RemoveStatusCondition(&r.instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
 	return nil
 }
 
-func (r *Reconciler) createCustomPropertiesIfNotExists() (bool, error) {
+func (r *Reconciler) setCustomPropertiesCondition(reason string, message string) {
+	meta.SetStatusCondition(&r.instance.Status.Conditions, metav1.Condition{
+		Type:    dynatracev1beta1.CustomPropertiesConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+func (r *Reconciler) createCustomPropertiesIfNotExists(data string) (bool, error) {
 	var customPropertiesSecret corev1.Secret
-	err := r.client.Get(context.TODO(),
+	err := r.client.Get(r.ctx,
 		client.ObjectKey{Name: r.buildCustomPropertiesName(r.instance.Name), Namespace: r.instance.Namespace}, &customPropertiesSecret)
 	if err != nil && k8serrors.IsNotFound(err) {
-		return true, r.createCustomProperties()
+		return true, r.createCustomProperties(data)
 	}
 	return false, errors.WithStack(err)
 }
 
-func (r *Reconciler) updateCustomPropertiesIfOutdated() error {
+func (r *Reconciler) updateCustomPropertiesIfOutdated(data string) error {
 	var customPropertiesSecret corev1.Secret
-	err := r.client.Get(context.TODO(),
+	err := r.client.Get(r.ctx,
 		client.ObjectKey{Name: r.buildCustomPropertiesName(r.instance.Name), Namespace: r.instance.Namespace},
 		&customPropertiesSecret)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	if r.isOutdated(&customPropertiesSecret) {
-		return r.updateCustomProperties(&customPropertiesSecret)
+	if isOutdated(&customPropertiesSecret, data) {
+		return r.updateCustomProperties(&customPropertiesSecret, data)
 	}
 	return nil
 }
 
-func (r *Reconciler) isOutdated(customProperties *corev1.Secret) bool {
-	return r.customPropertiesSource.Value != string(customProperties.Data[DataKey])
+func isOutdated(customProperties *corev1.Secret, data string) bool {
+	return data != string(customProperties.Data[DataKey])
 }
 
-func (r *Reconciler) updateCustomProperties(customProperties *corev1.Secret) error {
-	customProperties.Data[DataKey] = []byte(r.customPropertiesSource.Value)
-	return r.client.Update(context.TODO(), customProperties)
+func (r *Reconciler) updateCustomProperties(customProperties *corev1.Secret, data string) error {
+	customProperties.Data[DataKey] = []byte(data)
+	return r.client.Update(r.ctx, customProperties)
 }
 
-func (r *Reconciler) createCustomProperties() error {
+func (r *Reconciler) createCustomProperties(data string) error {
 	customPropertiesSecret := r.buildCustomPropertiesSecret(
 		r.buildCustomPropertiesName(r.instance.Name),
-		r.customPropertiesSource.Value,
+		data,
 	)
 
 	err := controllerutil.SetControllerReference(r.instance, customPropertiesSecret, r.scheme)
@@ -111,7 +231,42 @@ func (r *Reconciler) createCustomProperties() error {
 		return errors.WithStack(err)
 	}
 
-	return r.client.Create(context.TODO(), customPropertiesSecret)
+	return r.client.Create(r.ctx, customPropertiesSecret)
+}
+
+// deleteCustomPropertiesIfUnused removes the secret the operator created for the custom properties once they are removed
+// from the DynaKube or referenced from a secret of the user, secrets not controlled by the DynaKube are left untouched
+func (r *Reconciler) deleteCustomPropertiesIfUnused() error {
+	return r.deleteObsoleteCustomProperties("")
+}
+
+// deleteObsoleteCustomProperties removes all custom properties secrets controlled by the DynaKube except the one named inUseName,
+// e.g. the one of the previous owner after switching from the deprecated kubernetesMonitoring section to the activeGate section
+func (r *Reconciler) deleteObsoleteCustomProperties(inUseName string) error {
+	var secrets corev1.SecretList
+	err := r.client.List(r.ctx, &secrets, client.InNamespace(r.instance.Namespace))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i := range secrets.Items {
+		customPropertiesSecret := &secrets.Items[i]
+		if customPropertiesSecret.Name == inUseName || !r.isCustomPropertiesName(customPropertiesSecret.Name) {
+			continue
+		}
+
+		if !metav1.IsControlledBy(customPropertiesSecret, r.instance) {
+			r.log.Info("custom properties secret is not controlled by the dynakube, keeping it", "name", customPropertiesSecret.Name)
+			continue
+		}
+
+		r.log.Info("deleting unused custom properties secret", "name", customPropertiesSecret.Name)
+		err = r.client.Delete(r.ctx, customPropertiesSecret)
+		if client.IgnoreNotFound(err) != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
 }
 
 func (r *Reconciler) buildCustomPropertiesSecret(secretName string, data string) *corev1.Secret {
@@ -130,7 +285,7 @@ func (r *Reconciler) buildCustomPropertiesName(name string) string {
 	return fmt.Sprintf("%s-%s-%s", name, r.customPropertiesOwnerName, Suffix)
 }
 
-func (r *Reconciler) hasCustomPropertiesValueOnly() bool {
-	return r.customPropertiesSource.Value != "" &&
-		r.customPropertiesSource.ValueFrom == ""
+// isCustomPropertiesName returns true if name follows the naming scheme of buildCustomPropertiesName for any owner
+func (r *Reconciler) isCustomPropertiesName(name string) bool {
+	return strings.HasPrefix(name, r.instance.Name+"-") && strings.HasSuffix(name, "-"+Suffix)
 }
@@ -2,13 +2,18 @@ package customproperties
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -23,7 +28,7 @@ const (
 
 func TestReconciler_Reconcile(t *testing.T) {
 	t.Run(`Create works with minimal setup`, func(t *testing.T) {
-		r := NewReconciler(nil, nil, "", nil, &dynatracev1beta1.DynaKubeValueSource{})
+		r := NewReconciler(context.TODO(), nil, nil, "", nil, &dynatracev1beta1.DynaKubeValueSource{})
 		err := r.Reconcile()
 		assert.NoError(t, err)
 	})
@@ -35,7 +40,7 @@ func TestReconciler_Reconcile(t *testing.T) {
 				Namespace: testNamespace,
 			}}
 		fakeClient := fake.NewClient(instance)
-		r := NewReconciler(fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
 		err := r.Reconcile()
 
 		assert.NoError(t, err)
@@ -57,7 +62,7 @@ func TestReconciler_Reconcile(t *testing.T) {
 				Namespace: testNamespace,
 			}}
 		fakeClient := fake.NewClient(instance)
-		r := NewReconciler(fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
 		err := r.Reconcile()
 
 		assert.NoError(t, err)
@@ -96,4 +101,345 @@ func TestReconciler_Reconcile(t *testing.T) {
 		assert.Contains(t, customPropertiesSecret.Data, DataKey)
 		assert.Equal(t, customPropertiesSecret.Data[DataKey], []byte(testKey))
 	})
+	t.Run(`Create fails on conflicting duplicate keys`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: "[collector]\nenabled=true\n[collector]\nenabled=false"}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "[collector]enabled")
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonCustomPropertiesDuplicateKey, condition.Reason)
+		assert.Contains(t, condition.Message, "[collector]enabled")
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+
+		r.customPropertiesSource.Value = "[collector]\nenabled=true"
+		err = r.Reconcile()
+
+		require.NoError(t, err)
+		assert.Nil(t, meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType))
+	})
+	t.Run(`Create fails on oversized custom properties`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: "[connectivity]\nnetworkZone=" + strings.Repeat("a", 64)}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeatureActiveGateCustomPropertiesMaxSize: "32",
+				},
+			}}
+		fakeClient := fake.NewClient(instance)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the maximum of 32 bytes")
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonCustomPropertiesTooLarge, condition.Reason)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`Create warns about malformed lines`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: "[connectivity]\nnetworkZone"}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonCustomPropertiesMalformed, condition.Reason)
+		assert.Contains(t, condition.Message, "line 2")
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.NoError(t, err)
+	})
+	t.Run(`custom properties secret is validated`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{ValueFrom: testName}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeatureActiveGateCustomPropertiesMaxSize: "8",
+				},
+			}}
+		fakeClient := fake.NewClient(instance, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{
+				DataKey: []byte("[connectivity]\nnetworkZone=zone"),
+			},
+		})
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.Error(t, err)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonCustomPropertiesTooLarge, condition.Reason)
+	})
+	t.Run(`custom properties are copied from config map`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{ConfigMapRef: testName}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string]string{
+				DataKey: testValue + "=a",
+			},
+		}
+		fakeClient := fake.NewClient(instance, configMap)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testValue+"=a"), customPropertiesSecret.Data[DataKey])
+
+		configMap.Data[DataKey] = testValue + "=b"
+		require.NoError(t, fakeClient.Update(context.TODO(), configMap))
+
+		err = r.Reconcile()
+
+		require.NoError(t, err)
+
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testValue+"=b"), customPropertiesSecret.Data[DataKey])
+	})
+	t.Run(`config map without custom properties key`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{ConfigMapRef: testName}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string]string{
+				testKey: testValue,
+			},
+		})
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), DataKey)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CustomPropertiesConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonCustomPropertiesSourceUnavailable, condition.Reason)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`missing config map`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{ConfigMapRef: testName}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsNotFound(errors.Cause(err)))
+	})
+	t.Run(`inline value takes precedence over config map`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue + "=inline", ConfigMapRef: testName}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string]string{
+				DataKey: testValue + "=config-map",
+			},
+		})
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testValue+"=inline"), customPropertiesSecret.Data[DataKey])
+	})
+	t.Run(`custom properties secret is deleted once custom properties are removed`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		require.NoError(t, r.Reconcile())
+
+		r = NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, nil)
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`custom properties secret is deleted once a user secret is referenced`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testKey,
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{
+				DataKey: []byte(testValue),
+			},
+		})
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		require.NoError(t, r.Reconcile())
+
+		r.customPropertiesSource.Value = ""
+		r.customPropertiesSource.ValueFrom = testKey
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: testKey, Namespace: testNamespace}, &customPropertiesSecret)
+		assert.NoError(t, err)
+	})
+	t.Run(`custom properties secret of the previous owner is deleted`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		previous := NewReconciler(context.TODO(), fakeClient, instance, "kubernetes-monitoring", scheme.Scheme, &valueSource)
+		require.NoError(t, previous.Reconcile())
+
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.NoError(t, err)
+
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: previous.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`secrets of other dynakubes are kept`, func(t *testing.T) {
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue}
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+				UID:       "test-uid",
+			}}
+		otherInstance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName + "-other",
+				Namespace: testNamespace,
+				UID:       "other-uid",
+			}}
+		fakeClient := fake.NewClient(instance, otherInstance)
+		other := NewReconciler(context.TODO(), fakeClient, otherInstance, testOwner, scheme.Scheme, &valueSource)
+		require.NoError(t, other.Reconcile())
+
+		r := NewReconciler(context.TODO(), fakeClient, instance, testOwner, scheme.Scheme, &valueSource)
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: other.buildCustomPropertiesName(otherInstance.Name), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.NoError(t, err)
+	})
+	t.Run(`secrets not controlled by the dynakube are kept`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		r := NewReconciler(context.TODO(), nil, instance, testOwner, scheme.Scheme, nil)
+		r.client = fake.NewClient(instance, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.buildCustomPropertiesName(testName),
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{
+				DataKey: []byte(testValue),
+			},
+		})
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+
+		var customPropertiesSecret corev1.Secret
+		err = r.client.Get(context.TODO(), client.ObjectKey{Name: r.buildCustomPropertiesName(testName), Namespace: testNamespace}, &customPropertiesSecret)
+		assert.NoError(t, err)
+	})
 }
@@ -0,0 +1,35 @@
+package customproperties
+
+import (
+	"bufio"
+	"strings"
+)
+
+// findMalformedLine returns the number and content of the first line that is neither empty, a comment,
+// a [section] header nor a key=value pair, as those lines are silently dropped or misread by the ActiveGate.
+func findMalformedLine(customProperties string) (int, string, bool) {
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(customProperties))
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, commentPrefix) || strings.HasPrefix(line, altCommentPrefix) {
+			continue
+		}
+
+		if strings.HasPrefix(line, sectionPrefix) {
+			if !strings.HasSuffix(line, sectionSuffix) || strings.TrimSpace(line[len(sectionPrefix):len(line)-len(sectionSuffix)]) == "" {
+				return lineNumber, line, true
+			}
+			continue
+		}
+
+		separatorIndex := strings.IndexAny(line, keyValueSeparators)
+		if separatorIndex <= 0 || strings.TrimSpace(line[:separatorIndex]) == "" {
+			return lineNumber, line, true
+		}
+	}
+
+	return 0, "", false
+}
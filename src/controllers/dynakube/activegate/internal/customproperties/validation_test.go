@@ -0,0 +1,40 @@
+package customproperties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMalformedLine(t *testing.T) {
+	t.Run(`valid properties`, func(t *testing.T) {
+		_, _, found := findMalformedLine("# comment\n! comment\n\n[connectivity]\nnetworkZone=zone\ndnsEntryPoint: https://example.com")
+
+		assert.False(t, found)
+	})
+	t.Run(`line without separator is detected`, func(t *testing.T) {
+		lineNumber, line, found := findMalformedLine("[connectivity]\nnetworkZone=zone\nnetworkZone")
+
+		assert.True(t, found)
+		assert.Equal(t, 3, lineNumber)
+		assert.Equal(t, "networkZone", line)
+	})
+	t.Run(`line without key is detected`, func(t *testing.T) {
+		lineNumber, _, found := findMalformedLine("=zone")
+
+		assert.True(t, found)
+		assert.Equal(t, 1, lineNumber)
+	})
+	t.Run(`unterminated section is detected`, func(t *testing.T) {
+		lineNumber, line, found := findMalformedLine("networkZone=zone\n[connectivity")
+
+		assert.True(t, found)
+		assert.Equal(t, 2, lineNumber)
+		assert.Equal(t, "[connectivity", line)
+	})
+	t.Run(`empty section is detected`, func(t *testing.T) {
+		_, _, found := findMalformedLine("[ ]\nnetworkZone=zone")
+
+		assert.True(t, found)
+	})
+}
@@ -22,6 +22,9 @@ const (
 	proxyPortField     = "port"
 	proxyUsernameField = "username"
 	proxyPasswordField = "password"
+
+	// legacySecretName is the name the proxy secret had before it was named per DynaKube
+	legacySecretName = "dynatrace-" + consts.MultiActiveGateName + "-" + consts.ProxySecretSuffix
 )
 
 var _ controllers.Reconciler = &Reconciler{}
@@ -34,6 +37,10 @@ type Reconciler struct {
 }
 
 func (r *Reconciler) Reconcile() error {
+	if err := r.deleteLegacySecret(context.TODO(), r.dynakube); err != nil {
+		return errors.WithStack(err)
+	}
+
 	if r.dynakube.NeedsActiveGateProxy() {
 		return r.generateForDynakube(context.TODO(), r.dynakube)
 	}
@@ -59,7 +66,7 @@ func (r *Reconciler) generateForDynakube(ctx context.Context, dynakube *dynatrac
 	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      capability.BuildProxySecretName(),
+			Name:      capability.BuildProxySecretName(dynakube.Name),
 			Namespace: r.dynakube.Namespace,
 			Labels:    coreLabels.BuildMatchLabels(),
 		},
@@ -73,7 +80,7 @@ func (r *Reconciler) generateForDynakube(ctx context.Context, dynakube *dynatrac
 }
 
 func (r *Reconciler) ensureDeleted(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
-	secretName := capability.BuildProxySecretName()
+	secretName := capability.BuildProxySecretName(dynakube.Name)
 	secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: dynakube.Namespace}}
 	if err := r.client.Delete(ctx, &secret); err != nil && !k8serrors.IsNotFound(err) {
 		return err
@@ -84,6 +91,29 @@ func (r *Reconciler) ensureDeleted(ctx context.Context, dynakube *dynatracev1bet
 	return nil
 }
 
+// deleteLegacySecret removes the proxy secret that was shared by all DynaKubes of a namespace,
+// as long as it was last written for the given DynaKube
+func (r *Reconciler) deleteLegacySecret(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	var secret corev1.Secret
+	err := r.apiReader.Get(ctx, client.ObjectKey{Name: legacySecretName, Namespace: dynakube.Namespace}, &secret)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.WithStack(err)
+	}
+
+	coreLabels := kubeobjects.NewCoreLabels(dynakube.Name, kubeobjects.ActiveGateComponentLabel)
+	if secret.Labels[kubeobjects.AppCreatedByLabel] != coreLabels.BuildMatchLabels()[kubeobjects.AppCreatedByLabel] {
+		return nil
+	}
+
+	if err = r.client.Delete(ctx, &secret); err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
+	log.Info("removed legacy proxy secret", "namespace", dynakube.Namespace, "secret", legacySecretName)
+	return nil
+}
+
 func (r *Reconciler) createProxyMap(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (map[string][]byte, error) {
 	var err error
 	proxyUrl := ""
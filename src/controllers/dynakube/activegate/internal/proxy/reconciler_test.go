@@ -7,6 +7,7 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -50,7 +51,7 @@ func TestReconcileWithoutProxy(t *testing.T) {
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		err = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		err = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		require.Error(t, err)
 		assert.Empty(t, proxySecret)
@@ -59,7 +60,7 @@ func TestReconcileWithoutProxy(t *testing.T) {
 	t.Run(`ensure proxy secret deleted`, func(t *testing.T) {
 		var testClient = fake.NewClientBuilder().WithObjects(&corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      capability.BuildProxySecretName(),
+				Name:      capability.BuildProxySecretName(testDynakubeName),
 				Namespace: testNamespace,
 			},
 		}).Build()
@@ -69,7 +70,7 @@ func TestReconcileWithoutProxy(t *testing.T) {
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		err = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		err = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		require.Error(t, err)
 		assert.Empty(t, proxySecret)
@@ -77,6 +78,39 @@ func TestReconcileWithoutProxy(t *testing.T) {
 	})
 }
 
+func TestReconcileLegacyProxySecret(t *testing.T) {
+	newLegacySecret := func(dynakubeName string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      legacySecretName,
+				Namespace: testNamespace,
+				Labels:    kubeobjects.NewCoreLabels(dynakubeName, kubeobjects.ActiveGateComponentLabel).BuildMatchLabels(),
+			},
+		}
+	}
+
+	t.Run(`legacy proxy secret of the dynakube is removed`, func(t *testing.T) {
+		r := newTestReconcilerWithInstance(fake.NewClientBuilder().WithObjects(newLegacySecret(testDynakubeName)).Build())
+		r.dynakube.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: buildProxyUrl(proxyUsername, proxyPassword, proxyHost, proxyPort)}
+		require.NoError(t, r.Reconcile())
+
+		var proxySecret corev1.Secret
+		err := r.client.Get(context.TODO(), client.ObjectKey{Name: legacySecretName, Namespace: testNamespace}, &proxySecret)
+		assert.True(t, k8serrors.IsNotFound(err))
+
+		err = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
+		assert.NoError(t, err)
+	})
+	t.Run(`legacy proxy secret of another dynakube is kept`, func(t *testing.T) {
+		r := newTestReconcilerWithInstance(fake.NewClientBuilder().WithObjects(newLegacySecret("other-dynakube")).Build())
+		require.NoError(t, r.Reconcile())
+
+		var proxySecret corev1.Secret
+		err := r.client.Get(context.TODO(), client.ObjectKey{Name: legacySecretName, Namespace: testNamespace}, &proxySecret)
+		assert.NoError(t, err)
+	})
+}
+
 func TestReconcileProxyValue(t *testing.T) {
 	t.Run(`reconcile proxy Value`, func(t *testing.T) {
 		var proxyValue = buildProxyUrl(proxyUsername, proxyPassword, proxyHost, proxyPort)
@@ -86,7 +120,7 @@ func TestReconcileProxyValue(t *testing.T) {
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		assert.Equal(t, []byte(proxyPassword), proxySecret.Data[proxyPasswordField])
 		assert.Equal(t, []byte(proxyPort), proxySecret.Data[proxyPortField])
@@ -100,7 +134,7 @@ func TestReconcileProxyValue(t *testing.T) {
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		assert.Equal(t, []byte(nil), proxySecret.Data[proxyPasswordField])
 		assert.Equal(t, []byte(nil), proxySecret.Data[proxyPortField])
@@ -120,7 +154,7 @@ func TestReconcileProxyValueFrom(t *testing.T) {
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		assert.Equal(t, []byte(proxyPassword), proxySecret.Data[proxyPasswordField])
 		assert.Equal(t, []byte(proxyPort), proxySecret.Data[proxyPortField])
@@ -133,7 +167,7 @@ func TestReconcileProxyValueFrom(t *testing.T) {
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		assert.Equal(t, []byte(proxyPassword), proxySecret.Data[proxyPasswordField])
 		assert.Equal(t, []byte(proxyPort), proxySecret.Data[proxyPortField])
@@ -146,7 +180,7 @@ func TestReconcileProxyValueFrom(t *testing.T) {
 
 		require.NoError(t, err)
 
-		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(), Namespace: testNamespace}, &proxySecret)
+		_ = r.client.Get(context.TODO(), client.ObjectKey{Name: capability.BuildProxySecretName(testDynakubeName), Namespace: testNamespace}, &proxySecret)
 
 		assert.Equal(t, []byte(proxyPassword), proxySecret.Data[proxyPasswordField])
 		assert.Equal(t, []byte(proxyPort), proxySecret.Data[proxyPortField])
@@ -0,0 +1,52 @@
+package statefulset
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultNodesPerReplica = 50
+	defaultMinReplicas     = 1
+)
+
+// autoSizedReplicas returns the amount of replicas needed for nodeCount nodes, within the bounds of autoSizing.
+// The result only changes once the node count crosses a multiple of NodesPerReplica, so small fluctuations don't cause restarts.
+func autoSizedReplicas(nodeCount int, autoSizing dynatracev1beta1.AutoSizingSpec) int32 {
+	nodesPerReplica := autoSizing.NodesPerReplica
+	if nodesPerReplica <= 0 {
+		nodesPerReplica = defaultNodesPerReplica
+	}
+
+	minReplicas := autoSizing.MinReplicas
+	if minReplicas <= 0 {
+		minReplicas = defaultMinReplicas
+	}
+
+	replicas := (int32(nodeCount) + nodesPerReplica - 1) / nodesPerReplica
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+	if autoSizing.MaxReplicas > 0 && replicas > autoSizing.MaxReplicas {
+		replicas = autoSizing.MaxReplicas
+	}
+	return replicas
+}
+
+// getAutoSizedReplicas counts the nodes through the cache, the DynaKube controller watches nodes to reconcile once the node count changes
+func (r *Reconciler) getAutoSizedReplicas() (*int32, error) {
+	autoSizing := r.capability.Properties().AutoSizing
+	if autoSizing == nil {
+		return nil, nil
+	}
+
+	var nodes corev1.NodeList
+	err := r.client.List(r.ctx, &nodes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to count nodes for auto sizing")
+	}
+
+	replicas := autoSizedReplicas(len(nodes.Items), *autoSizing)
+	return &replicas, nil
+}
@@ -0,0 +1,74 @@
+package statefulset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestAutoSizedReplicas(t *testing.T) {
+	t.Run(`defaults`, func(t *testing.T) {
+		autoSizing := dynatracev1beta1.AutoSizingSpec{}
+
+		assert.Equal(t, int32(1), autoSizedReplicas(0, autoSizing))
+		assert.Equal(t, int32(1), autoSizedReplicas(1, autoSizing))
+		assert.Equal(t, int32(1), autoSizedReplicas(50, autoSizing))
+		assert.Equal(t, int32(2), autoSizedReplicas(51, autoSizing))
+		assert.Equal(t, int32(20), autoSizedReplicas(1000, autoSizing))
+	})
+	t.Run(`scales within bounds`, func(t *testing.T) {
+		autoSizing := dynatracev1beta1.AutoSizingSpec{
+			NodesPerReplica: 10,
+			MinReplicas:     2,
+			MaxReplicas:     5,
+		}
+
+		assert.Equal(t, int32(2), autoSizedReplicas(3, autoSizing))
+		assert.Equal(t, int32(2), autoSizedReplicas(20, autoSizing))
+		assert.Equal(t, int32(3), autoSizedReplicas(21, autoSizing))
+		assert.Equal(t, int32(5), autoSizedReplicas(50, autoSizing))
+		assert.Equal(t, int32(5), autoSizedReplicas(500, autoSizing))
+	})
+}
+
+func TestReconcile_AutoSizing(t *testing.T) {
+	r := createDefaultReconciler(t)
+	r.dynakube.Spec.Routing.AutoSizing = &dynatracev1beta1.AutoSizingSpec{NodesPerReplica: 2, MaxReplicas: 3}
+	for i := 0; i < 3; i++ {
+		addNode(t, r, i)
+	}
+
+	err := r.Reconcile()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *getReconciledStatefulSet(t, r).Spec.Replicas)
+
+	for i := 3; i < 10; i++ {
+		addNode(t, r, i)
+	}
+
+	err = r.Reconcile()
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *getReconciledStatefulSet(t, r).Spec.Replicas)
+}
+
+func addNode(t *testing.T, r *Reconciler, index int) {
+	err := r.client.Create(context.TODO(), &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", index)},
+	})
+	require.NoError(t, err)
+}
+
+func getReconciledStatefulSet(t *testing.T, r *Reconciler) *appsv1.StatefulSet {
+	statefulSet := &appsv1.StatefulSet{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Name: r.dynakube.Name + "-" + r.capability.ShortName(), Namespace: r.dynakube.Namespace}, statefulSet)
+	require.NoError(t, err)
+	return statefulSet
+}
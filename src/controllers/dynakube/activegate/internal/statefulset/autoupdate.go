@@ -0,0 +1,58 @@
+package statefulset
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getAutoUpdateImage returns the image and version the ActiveGate pods run with auto updates enabled, empty values leave both to the DynaKube.
+// The pods follow the digest resolved from the registry, a changed digest is only rolled out while the maintenance window is open,
+// until then the image of the current StatefulSet is kept.
+func (r *Reconciler) getAutoUpdateImage() (string, string, error) {
+	properties := r.capability.Properties()
+	resolvedImage := r.dynakube.Status.ActiveGate.Image
+	if !properties.IsAutoUpdateEnabled() || resolvedImage == "" {
+		return "", "", nil
+	}
+	resolvedVersion := r.dynakube.Status.ActiveGate.Version
+
+	var currentSts appsv1.StatefulSet
+	err := r.client.Get(r.ctx, client.ObjectKey{Name: capability.CalculateStatefulSetName(r.capability, r.dynakube.Name), Namespace: r.dynakube.Namespace}, &currentSts)
+	if k8serrors.IsNotFound(err) {
+		return resolvedImage, resolvedVersion, nil
+	} else if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+
+	currentImage := activeGateContainerImage(&currentSts)
+	if currentImage == "" || currentImage == resolvedImage {
+		return resolvedImage, resolvedVersion, nil
+	}
+
+	isOpen, err := properties.MaintenanceWindow.IsOpen(r.timeProvider.Now().Time)
+	if err != nil {
+		return "", "", errors.WithMessage(err, "invalid maintenance window")
+	}
+
+	if isOpen {
+		r.log.Info("rolling out ActiveGate update", "statefulSet", currentSts.Name, "currentImage", currentImage, "image", resolvedImage)
+		return resolvedImage, resolvedVersion, nil
+	}
+
+	r.log.Info("ActiveGate update postponed until the next maintenance window", "statefulSet", currentSts.Name, "currentImage", currentImage, "image", resolvedImage)
+	return currentImage, currentSts.Labels[kubeobjects.AppVersionLabel], nil
+}
+
+func activeGateContainerImage(sts *appsv1.StatefulSet) string {
+	for _, container := range sts.Spec.Template.Spec.Containers {
+		if container.Name == consts.ActiveGateContainerName {
+			return container.Image
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package statefulset
+
+import (
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testResolvedImage = "registry.example.com/linux/activegate@sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	testUpdatedImage  = "registry.example.com/linux/activegate@sha256:3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"
+)
+
+func createAutoUpdateReconciler(t *testing.T, maintenanceWindow *dynatracev1beta1.MaintenanceWindow) *Reconciler {
+	r := createDefaultReconciler(t)
+	r.dynakube.Spec.ActiveGate.AutoUpdate = address.Of(true)
+	r.dynakube.Spec.ActiveGate.MaintenanceWindow = maintenanceWindow
+	r.dynakube.Status.ActiveGate.Image = testResolvedImage
+	r.dynakube.Status.ActiveGate.Version = "1.0.0"
+	r.capability = capability.NewMultiCapability(r.dynakube)
+	return r
+}
+
+func TestReconcile_AutoUpdate(t *testing.T) {
+	maintenanceWindow := &dynatracev1beta1.MaintenanceWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}}
+	beforeWindow := metav1.NewTime(time.Date(2022, time.October, 3, 12, 0, 0, 0, time.UTC))
+	inWindow := metav1.NewTime(time.Date(2022, time.October, 3, 23, 0, 0, 0, time.UTC))
+
+	t.Run("image of the dynakube without auto update", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, nil)
+		r.dynakube.Spec.ActiveGate.AutoUpdate = nil
+		require.NoError(t, r.Reconcile())
+
+		sts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, r.dynakube.ActiveGateImage(), sts.Spec.Template.Spec.Containers[0].Image)
+	})
+	t.Run("image of the dynakube until the digest is resolved", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, nil)
+		r.dynakube.Status.ActiveGate.Image = ""
+		require.NoError(t, r.Reconcile())
+
+		sts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, r.dynakube.ActiveGateImage(), sts.Spec.Template.Spec.Containers[0].Image)
+	})
+	t.Run("new stateful set uses the resolved digest outside the maintenance window", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, maintenanceWindow)
+		r.timeProvider.SetNow(&beforeWindow)
+		require.NoError(t, r.Reconcile())
+
+		sts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, testResolvedImage, sts.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "1.0.0", sts.Labels[kubeobjects.AppVersionLabel])
+	})
+	t.Run("changed digest is rolled out immediately without maintenance window", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, nil)
+		require.NoError(t, r.Reconcile())
+
+		r.dynakube.Status.ActiveGate.Image = testUpdatedImage
+		r.dynakube.Status.ActiveGate.Version = "1.0.1"
+		require.NoError(t, r.Reconcile())
+
+		sts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, testUpdatedImage, sts.Spec.Template.Spec.Containers[0].Image)
+	})
+	t.Run("rebuilt image of the same version is rolled out", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, nil)
+		require.NoError(t, r.Reconcile())
+
+		r.dynakube.Status.ActiveGate.Image = testUpdatedImage
+		require.NoError(t, r.Reconcile())
+
+		sts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, testUpdatedImage, sts.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "1.0.0", sts.Labels[kubeobjects.AppVersionLabel])
+	})
+	t.Run("changed digest waits for the maintenance window", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, maintenanceWindow)
+		r.timeProvider.SetNow(&beforeWindow)
+		require.NoError(t, r.Reconcile())
+		sts := getReconciledStatefulSet(t, r)
+
+		r.dynakube.Status.ActiveGate.Image = testUpdatedImage
+		r.dynakube.Status.ActiveGate.Version = "1.0.1"
+		require.NoError(t, r.Reconcile())
+
+		postponedSts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, sts.ResourceVersion, postponedSts.ResourceVersion)
+		assert.Equal(t, testResolvedImage, postponedSts.Spec.Template.Spec.Containers[0].Image)
+
+		r.timeProvider.SetNow(&inWindow)
+		require.NoError(t, r.Reconcile())
+
+		updatedSts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, testUpdatedImage, updatedSts.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "1.0.1", updatedSts.Labels[kubeobjects.AppVersionLabel])
+	})
+	t.Run("other changes keep the current image outside the maintenance window", func(t *testing.T) {
+		r := createAutoUpdateReconciler(t, maintenanceWindow)
+		r.timeProvider.SetNow(&beforeWindow)
+		require.NoError(t, r.Reconcile())
+
+		r.dynakube.Status.ActiveGate.Image = testUpdatedImage
+		r.dynakube.Status.ActiveGate.Version = "1.0.1"
+		r.dynakube.Spec.ActiveGate.Labels = map[string]string{"changed": "label"}
+		require.NoError(t, r.Reconcile())
+
+		sts := getReconciledStatefulSet(t, r)
+		assert.Equal(t, "label", sts.Labels["changed"])
+		assert.Equal(t, testResolvedImage, sts.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "1.0.0", sts.Labels[kubeobjects.AppVersionLabel])
+	})
+}
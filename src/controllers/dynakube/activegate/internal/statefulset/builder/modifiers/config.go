@@ -35,10 +35,16 @@ func GenerateAllModifiers(dynakube dynatracev1beta1.DynaKube, capability capabil
 		NewServicePortModifier(dynakube, capability),
 		NewAuthTokenModifier(dynakube),
 		NewCertificatesModifier(dynakube),
+		NewTrustedCAsModifier(dynakube),
 		NewCustomPropertiesModifier(dynakube, capability),
 		NewExtensionControllerModifier(dynakube, capability),
 		NewProxyModifier(dynakube),
 		NewRawImageModifier(dynakube),
+		NewPersistentStorageModifier(dynakube, capability),
 		NewReadOnlyModifier(dynakube),
+		NewSecurityContextModifier(dynakube, capability),
+		NewProbesModifier(dynakube, capability),
+		NewCustomEnvModifier(dynakube, capability),
+		NewCustomVolumesModifier(dynakube, capability),
 	}
 }
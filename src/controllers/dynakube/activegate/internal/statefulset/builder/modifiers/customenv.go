@@ -0,0 +1,89 @@
+package modifiers
+
+import (
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ builder.Modifier = CustomEnvModifier{}
+
+func NewCustomEnvModifier(dynakube dynatracev1beta1.DynaKube, capability capability.Capability) CustomEnvModifier {
+	return CustomEnvModifier{
+		dynakube:   dynakube,
+		capability: capability,
+	}
+}
+
+// CustomEnvModifier merges the environment variables and arguments defined by the user into the ActiveGate container.
+// Environment variables managed by the operator are rejected by the webhook, see consts.ReservedEnvVars.
+// It has to run after all modifiers that add environment variables, so DynaKubes admitted without the webhook can't override them either.
+type CustomEnvModifier struct {
+	dynakube   dynatracev1beta1.DynaKube
+	capability capability.Capability
+}
+
+func (mod CustomEnvModifier) Enabled() bool {
+	return len(mod.getCustomEnvs()) > 0 || len(mod.getCustomArgs()) > 0
+}
+
+func (mod CustomEnvModifier) Modify(sts *appsv1.StatefulSet) {
+	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+
+	for _, customEnv := range mod.getCustomEnvs() {
+		if kubeobjects.EnvVarIsIn(baseContainer.Env, customEnv.Name) {
+			continue
+		}
+		baseContainer.Env = append(baseContainer.Env, customEnv)
+	}
+	baseContainer.Args = mergeArgs(baseContainer.Args, mod.getCustomArgs())
+}
+
+// mergeArgs appends customArgs to args, a custom flag replaces the flag with the same name in args.
+// Flags are compared by the part before '=', so "--foo=custom" replaces "--foo=default".
+func mergeArgs(args []string, customArgs []string) []string {
+	if len(customArgs) == 0 {
+		return args
+	}
+
+	customFlags := map[string]bool{}
+	for _, customArg := range customArgs {
+		if name, isFlag := flagName(customArg); isFlag {
+			customFlags[name] = true
+		}
+	}
+
+	merged := make([]string, 0, len(args)+len(customArgs))
+	for _, arg := range args {
+		if name, isFlag := flagName(arg); isFlag && customFlags[name] {
+			continue
+		}
+		merged = append(merged, arg)
+	}
+	return append(merged, customArgs...)
+}
+
+func flagName(arg string) (string, bool) {
+	if !strings.HasPrefix(arg, "-") {
+		return "", false
+	}
+	name, _, _ := strings.Cut(arg, "=")
+	return name, true
+}
+
+func (mod CustomEnvModifier) getCustomEnvs() []corev1.EnvVar {
+	return mod.capability.Properties().Env
+}
+
+func (mod CustomEnvModifier) getCustomArgs() []string {
+	if _, isKubeMon := mod.capability.(*capability.KubeMonCapability); !isKubeMon {
+		return nil
+	}
+	return mod.dynakube.Spec.KubernetesMonitoring.Args
+}
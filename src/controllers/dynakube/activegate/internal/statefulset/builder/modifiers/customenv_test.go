@@ -0,0 +1,149 @@
+package modifiers
+
+import (
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCustomEnvEnabled(t *testing.T) {
+	t.Run("true for envs", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		dynakube.Spec.ActiveGate.Env = []corev1.EnvVar{{Name: "CUSTOM_ENV", Value: "custom"}}
+
+		mod := NewCustomEnvModifier(dynakube, capability.NewMultiCapability(&dynakube))
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("true for kubernetes monitoring args", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.Args = []string{"--custom-flag"}
+
+		mod := NewCustomEnvModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("false", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+
+		mod := NewCustomEnvModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+
+	t.Run("args are ignored for other capabilities", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		dynakube.Spec.KubernetesMonitoring.Args = []string{"--custom-flag"}
+
+		mod := NewCustomEnvModifier(dynakube, capability.NewMultiCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+}
+
+func TestCustomEnvModify(t *testing.T) {
+	t.Run("custom envs and args are added", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.Env = []corev1.EnvVar{{Name: "CUSTOM_ENV", Value: "custom"}}
+		dynakube.Spec.KubernetesMonitoring.Args = []string{"--custom-flag"}
+		mod := NewCustomEnvModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		builder := createBuilderForTesting()
+
+		sts := builder.AddModifier(mod).Build()
+
+		container := sts.Spec.Template.Spec.Containers[0]
+		assert.Contains(t, container.Env, corev1.EnvVar{Name: "CUSTOM_ENV", Value: "custom"})
+		assert.Equal(t, []string{"--custom-flag"}, container.Args)
+	})
+
+	t.Run("envs managed by the operator take precedence", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.Env = []corev1.EnvVar{
+			{Name: consts.EnvDtCapabilities, Value: "user-value"},
+			{Name: "CUSTOM_ENV", Value: "custom"},
+		}
+		mod := NewCustomEnvModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		builder := createBuilderForTesting()
+		sts := builder.Build()
+		sts.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: consts.EnvDtCapabilities, Value: "operator-value"}}
+
+		mod.Modify(&sts)
+
+		envs := sts.Spec.Template.Spec.Containers[0].Env
+		require.Len(t, envs, 2)
+		assert.Equal(t, "operator-value", kubeobjects.FindEnvVar(envs, consts.EnvDtCapabilities).Value)
+		assert.Equal(t, "custom", kubeobjects.FindEnvVar(envs, "CUSTOM_ENV").Value)
+	})
+
+	t.Run("custom args override the arguments of the operator", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.Args = []string{"--foo=custom", "--bar"}
+		mod := NewCustomEnvModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		builder := createBuilderForTesting()
+		sts := builder.Build()
+		sts.Spec.Template.Spec.Containers[0].Args = []string{"--foo=default", "--baz=default"}
+
+		mod.Modify(&sts)
+
+		assert.Equal(t, []string{"--baz=default", "--foo=custom", "--bar"}, sts.Spec.Template.Spec.Containers[0].Args)
+	})
+}
+
+func TestMergeArgs(t *testing.T) {
+	testCases := []struct {
+		name       string
+		args       []string
+		customArgs []string
+		expected   []string
+	}{
+		{
+			name:     "no custom args",
+			args:     []string{"--foo"},
+			expected: []string{"--foo"},
+		},
+		{
+			name:       "custom args are appended",
+			args:       []string{"--foo"},
+			customArgs: []string{"--bar=1"},
+			expected:   []string{"--foo", "--bar=1"},
+		},
+		{
+			name:       "custom flag with value replaces flag",
+			args:       []string{"--foo=1", "--bar"},
+			customArgs: []string{"--foo=2"},
+			expected:   []string{"--bar", "--foo=2"},
+		},
+		{
+			name:       "custom flag without value replaces flag with value",
+			args:       []string{"-foo=1"},
+			customArgs: []string{"-foo"},
+			expected:   []string{"-foo"},
+		},
+		{
+			name:       "positional args are not treated as flags",
+			args:       []string{"start", "--foo=1"},
+			customArgs: []string{"start", "--foo=2"},
+			expected:   []string{"start", "start", "--foo=2"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, mergeArgs(testCase.args, testCase.customArgs))
+		})
+	}
+}
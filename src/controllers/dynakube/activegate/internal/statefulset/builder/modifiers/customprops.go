@@ -70,7 +70,8 @@ func (mod CustomPropertiesModifier) hasCustomProperties() bool {
 	customProperties := mod.capability.Properties().CustomProperties
 	return customProperties != nil &&
 		(customProperties.Value != "" ||
-			customProperties.ValueFrom != "")
+			customProperties.ValueFrom != "" ||
+			customProperties.ConfigMapRef != "")
 }
 
 func (mod CustomPropertiesModifier) determineCustomPropertiesSource() string {
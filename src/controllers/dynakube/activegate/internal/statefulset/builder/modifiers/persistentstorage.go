@@ -0,0 +1,62 @@
+package modifiers
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ volumeMountModifier = PersistentStorageModifier{}
+var _ builder.Modifier = PersistentStorageModifier{}
+
+func NewPersistentStorageModifier(dynakube dynatracev1beta1.DynaKube, capability capability.Capability) PersistentStorageModifier {
+	return PersistentStorageModifier{
+		dynakube:   dynakube,
+		capability: capability,
+	}
+}
+
+// PersistentStorageModifier mounts a PersistentVolumeClaim per pod as the data directory of the Kubernetes monitoring ActiveGate.
+// It has to run before the ReadOnlyModifier, which only falls back to an emptyDir if the directory isn't mounted yet.
+type PersistentStorageModifier struct {
+	dynakube   dynatracev1beta1.DynaKube
+	capability capability.Capability
+}
+
+func (mod PersistentStorageModifier) Enabled() bool {
+	_, isKubeMon := mod.capability.(*capability.KubeMonCapability)
+	return isKubeMon && mod.dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate != nil
+}
+
+func (mod PersistentStorageModifier) Modify(sts *appsv1.StatefulSet) {
+	sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates, mod.getVolumeClaimTemplates()...)
+
+	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+	baseContainer.VolumeMounts = append(baseContainer.VolumeMounts, mod.getVolumeMounts()...)
+}
+
+func (mod PersistentStorageModifier) getVolumeClaimTemplates() []corev1.PersistentVolumeClaim {
+	return []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: consts.GatewayDataVolumeName,
+			},
+			Spec: *mod.dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate.DeepCopy(),
+		},
+	}
+}
+
+func (mod PersistentStorageModifier) getVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			ReadOnly:  false,
+			Name:      consts.GatewayDataVolumeName,
+			MountPath: consts.GatewayDataMountPoint,
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package modifiers
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func setPersistentStorageUsage(dynakube *dynatracev1beta1.DynaKube, isUsed bool) {
+	if isUsed {
+		dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate = &corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		}
+	} else {
+		dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate = nil
+	}
+}
+
+func TestPersistentStorageEnabled(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setPersistentStorageUsage(&dynakube, true)
+
+		mod := NewPersistentStorageModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("false", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setPersistentStorageUsage(&dynakube, false)
+
+		mod := NewPersistentStorageModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+
+	t.Run("false for other capabilities", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		setPersistentStorageUsage(&dynakube, true)
+
+		mod := NewPersistentStorageModifier(dynakube, capability.NewMultiCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+}
+
+func TestPersistentStorageModify(t *testing.T) {
+	t.Run("successfully modified", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setPersistentStorageUsage(&dynakube, true)
+		mod := NewPersistentStorageModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		builder := createBuilderForTesting()
+
+		sts := builder.AddModifier(mod).Build()
+
+		require.Len(t, sts.Spec.VolumeClaimTemplates, 1)
+		assert.Equal(t, consts.GatewayDataVolumeName, sts.Spec.VolumeClaimTemplates[0].Name)
+		assert.Equal(t, *dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate, sts.Spec.VolumeClaimTemplates[0].Spec)
+		isSubset(t, mod.getVolumeMounts(), sts.Spec.Template.Spec.Containers[0].VolumeMounts)
+	})
+	t.Run("replaces the data emptyDir of the read-only filesystem", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setPersistentStorageUsage(&dynakube, true)
+		setReadOnlyUsage(&dynakube, true)
+		builder := createBuilderForTesting()
+
+		sts := builder.AddModifier(
+			NewPersistentStorageModifier(dynakube, capability.NewKubeMonCapability(&dynakube)),
+			NewReadOnlyModifier(dynakube),
+		).Build()
+
+		for _, volume := range sts.Spec.Template.Spec.Volumes {
+			assert.NotEqual(t, consts.GatewayDataVolumeName, volume.Name)
+		}
+		dataMounts := 0
+		for _, volumeMount := range sts.Spec.Template.Spec.Containers[0].VolumeMounts {
+			if volumeMount.Name == consts.GatewayDataVolumeName {
+				dataMounts++
+			}
+		}
+		assert.Equal(t, 1, dataMounts)
+	})
+}
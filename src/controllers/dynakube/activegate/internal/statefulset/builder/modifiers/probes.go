@@ -0,0 +1,63 @@
+package modifiers
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ builder.Modifier = ProbesModifier{}
+
+func NewProbesModifier(dynakube dynatracev1beta1.DynaKube, capability capability.Capability) ProbesModifier {
+	return ProbesModifier{
+		dynakube:   dynakube,
+		capability: capability,
+	}
+}
+
+// ProbesModifier applies the probe timings of the Kubernetes monitoring spec to the ActiveGate container.
+// It has to run after the ServicePortModifier, so the liveness probe uses the final port of the readiness probe.
+type ProbesModifier struct {
+	dynakube   dynatracev1beta1.DynaKube
+	capability capability.Capability
+}
+
+func (mod ProbesModifier) Enabled() bool {
+	_, isKubeMon := mod.capability.(*capability.KubeMonCapability)
+	return isKubeMon && (mod.dynakube.Spec.KubernetesMonitoring.ReadinessProbe != nil || mod.dynakube.Spec.KubernetesMonitoring.LivenessProbe != nil)
+}
+
+func (mod ProbesModifier) Modify(sts *appsv1.StatefulSet) {
+	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+	defaultProbe := baseContainer.ReadinessProbe.DeepCopy()
+
+	applyProbeSettings(baseContainer.ReadinessProbe, mod.dynakube.Spec.KubernetesMonitoring.ReadinessProbe)
+
+	if mod.dynakube.Spec.KubernetesMonitoring.LivenessProbe != nil {
+		baseContainer.LivenessProbe = defaultProbe
+		applyProbeSettings(baseContainer.LivenessProbe, mod.dynakube.Spec.KubernetesMonitoring.LivenessProbe)
+	}
+}
+
+// applyProbeSettings overwrites the timings of probe with the ones that are set, the handler is left untouched
+func applyProbeSettings(probe *corev1.Probe, settings *dynatracev1beta1.ProbeSettings) {
+	if settings == nil {
+		return
+	}
+	if settings.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *settings.InitialDelaySeconds
+	}
+	if settings.PeriodSeconds != nil {
+		probe.PeriodSeconds = *settings.PeriodSeconds
+	}
+	if settings.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *settings.TimeoutSeconds
+	}
+	if settings.FailureThreshold != nil {
+		probe.FailureThreshold = *settings.FailureThreshold
+	}
+}
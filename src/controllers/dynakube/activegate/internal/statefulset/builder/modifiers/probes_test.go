@@ -0,0 +1,111 @@
+package modifiers
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var testProbeHandler = corev1.ProbeHandler{
+	HTTPGet: &corev1.HTTPGetAction{
+		Path:   "/rest/health",
+		Port:   intstr.FromInt(9999),
+		Scheme: "HTTPS",
+	},
+}
+
+func createProbeTestingStatefulSetContainer(t *testing.T, mod ProbesModifier) corev1.Container {
+	builder := createBuilderForTesting()
+	sts := builder.Build()
+	sts.Spec.Template.Spec.Containers[0].ReadinessProbe = &corev1.Probe{
+		ProbeHandler:        testProbeHandler,
+		InitialDelaySeconds: 90,
+		PeriodSeconds:       15,
+		FailureThreshold:    3,
+	}
+
+	mod.Modify(&sts)
+
+	require.Len(t, sts.Spec.Template.Spec.Containers, 1)
+	return sts.Spec.Template.Spec.Containers[0]
+}
+
+func TestProbesEnabled(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.ReadinessProbe = &dynatracev1beta1.ProbeSettings{}
+
+		mod := NewProbesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("false", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+
+		mod := NewProbesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+
+	t.Run("false for other capabilities", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		dynakube.Spec.KubernetesMonitoring.ReadinessProbe = &dynatracev1beta1.ProbeSettings{}
+
+		mod := NewProbesModifier(dynakube, capability.NewMultiCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+}
+
+func TestProbesModify(t *testing.T) {
+	t.Run("overriding initialDelaySeconds keeps the handler and the other timings", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.ReadinessProbe = &dynatracev1beta1.ProbeSettings{
+			InitialDelaySeconds: address.Of(int32(300)),
+		}
+		mod := NewProbesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		container := createProbeTestingStatefulSetContainer(t, mod)
+
+		require.NotNil(t, container.ReadinessProbe)
+		assert.Equal(t, testProbeHandler, container.ReadinessProbe.ProbeHandler)
+		assert.Equal(t, int32(300), container.ReadinessProbe.InitialDelaySeconds)
+		assert.Equal(t, int32(15), container.ReadinessProbe.PeriodSeconds)
+		assert.Equal(t, int32(3), container.ReadinessProbe.FailureThreshold)
+		assert.Nil(t, container.LivenessProbe)
+	})
+	t.Run("liveness probe uses the handler and default timings of the readiness probe", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.ReadinessProbe = &dynatracev1beta1.ProbeSettings{
+			PeriodSeconds: address.Of(int32(5)),
+		}
+		dynakube.Spec.KubernetesMonitoring.LivenessProbe = &dynatracev1beta1.ProbeSettings{
+			TimeoutSeconds:   address.Of(int32(10)),
+			FailureThreshold: address.Of(int32(6)),
+		}
+		mod := NewProbesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		container := createProbeTestingStatefulSetContainer(t, mod)
+
+		require.NotNil(t, container.LivenessProbe)
+		assert.Equal(t, testProbeHandler, container.LivenessProbe.ProbeHandler)
+		assert.Equal(t, int32(90), container.LivenessProbe.InitialDelaySeconds)
+		assert.Equal(t, int32(15), container.LivenessProbe.PeriodSeconds)
+		assert.Equal(t, int32(10), container.LivenessProbe.TimeoutSeconds)
+		assert.Equal(t, int32(6), container.LivenessProbe.FailureThreshold)
+		assert.Equal(t, int32(5), container.ReadinessProbe.PeriodSeconds)
+		assert.NotSame(t, container.ReadinessProbe.HTTPGet, container.LivenessProbe.HTTPGet)
+	})
+}
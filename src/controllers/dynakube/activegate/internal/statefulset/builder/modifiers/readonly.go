@@ -31,12 +31,12 @@ func (mod ReadOnlyModifier) Enabled() bool {
 }
 
 func (mod ReadOnlyModifier) Modify(sts *appsv1.StatefulSet) {
+	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
 	mod.presentVolumes = sts.Spec.Template.Spec.Volumes
+	mod.presentMounts = baseContainer.VolumeMounts
 	sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, mod.getVolumes()...)
 
-	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
 	baseContainer.SecurityContext.ReadOnlyRootFilesystem = address.Of(true)
-	mod.presentMounts = baseContainer.VolumeMounts
 	baseContainer.VolumeMounts = append(baseContainer.VolumeMounts, mod.getVolumeMounts()...)
 }
 
@@ -67,6 +67,10 @@ func (mod ReadOnlyModifier) getVolumes() []corev1.Volume {
 			},
 		}}
 
+	if mod.isDataPersisted() {
+		volumes = removeVolume(volumes, consts.GatewayDataVolumeName)
+	}
+
 	_, err := kubeobjects.GetVolumeByName(mod.presentVolumes, consts.GatewayConfigVolumeName)
 	if err != nil {
 		volumes = append(volumes,
@@ -104,6 +108,10 @@ func (mod ReadOnlyModifier) getVolumeMounts() []corev1.VolumeMount {
 			MountPath: consts.GatewayTmpMountPoint,
 		}}
 
+	if mod.isDataPersisted() {
+		volumeMounts = removeVolumeMount(volumeMounts, consts.GatewayDataVolumeName)
+	}
+
 	neededMount := corev1.VolumeMount{
 		ReadOnly:  false,
 		Name:      consts.GatewayConfigVolumeName,
@@ -114,3 +122,32 @@ func (mod ReadOnlyModifier) getVolumeMounts() []corev1.VolumeMount {
 	}
 	return volumeMounts
 }
+
+// isDataPersisted checks if the PersistentStorageModifier mounted a volume claim as the data directory already
+func (mod ReadOnlyModifier) isDataPersisted() bool {
+	return kubeobjects.IsVolumeMountPresent(mod.presentMounts, corev1.VolumeMount{
+		ReadOnly:  false,
+		Name:      consts.GatewayDataVolumeName,
+		MountPath: consts.GatewayDataMountPoint,
+	})
+}
+
+func removeVolume(volumes []corev1.Volume, name string) []corev1.Volume {
+	kept := make([]corev1.Volume, 0, len(volumes))
+	for _, volume := range volumes {
+		if volume.Name != name {
+			kept = append(kept, volume)
+		}
+	}
+	return kept
+}
+
+func removeVolumeMount(volumeMounts []corev1.VolumeMount, name string) []corev1.VolumeMount {
+	kept := make([]corev1.VolumeMount, 0, len(volumeMounts))
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.Name != name {
+			kept = append(kept, volumeMount)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,84 @@
+package modifiers
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ builder.Modifier = SecurityContextModifier{}
+
+func NewSecurityContextModifier(dynakube dynatracev1beta1.DynaKube, capability capability.Capability) SecurityContextModifier {
+	return SecurityContextModifier{
+		dynakube:   dynakube,
+		capability: capability,
+	}
+}
+
+// SecurityContextModifier applies the security contexts of the Kubernetes monitoring spec to the pod and the ActiveGate container.
+// It has to run after the ReadOnlyModifier, so the values set by the user replace the defaults of the operator.
+type SecurityContextModifier struct {
+	dynakube   dynatracev1beta1.DynaKube
+	capability capability.Capability
+}
+
+func (mod SecurityContextModifier) Enabled() bool {
+	_, isKubeMon := mod.capability.(*capability.KubeMonCapability)
+	return isKubeMon && (mod.dynakube.Spec.KubernetesMonitoring.PodSecurityContext != nil || mod.dynakube.Spec.KubernetesMonitoring.SecurityContext != nil)
+}
+
+func (mod SecurityContextModifier) Modify(sts *appsv1.StatefulSet) {
+	if podSecurityContext := mod.dynakube.Spec.KubernetesMonitoring.PodSecurityContext; podSecurityContext != nil {
+		sts.Spec.Template.Spec.SecurityContext = podSecurityContext.DeepCopy()
+	}
+
+	if securityContext := mod.dynakube.Spec.KubernetesMonitoring.SecurityContext; securityContext != nil {
+		baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+		if baseContainer.SecurityContext == nil {
+			baseContainer.SecurityContext = &corev1.SecurityContext{}
+		}
+		mergeSecurityContext(baseContainer.SecurityContext, securityContext)
+	}
+}
+
+// mergeSecurityContext overwrites the fields of target with the ones that are set in overrides
+func mergeSecurityContext(target *corev1.SecurityContext, overrides *corev1.SecurityContext) {
+	overrides = overrides.DeepCopy()
+	if overrides.Capabilities != nil {
+		target.Capabilities = overrides.Capabilities
+	}
+	if overrides.Privileged != nil {
+		target.Privileged = overrides.Privileged
+	}
+	if overrides.SELinuxOptions != nil {
+		target.SELinuxOptions = overrides.SELinuxOptions
+	}
+	if overrides.WindowsOptions != nil {
+		target.WindowsOptions = overrides.WindowsOptions
+	}
+	if overrides.RunAsUser != nil {
+		target.RunAsUser = overrides.RunAsUser
+	}
+	if overrides.RunAsGroup != nil {
+		target.RunAsGroup = overrides.RunAsGroup
+	}
+	if overrides.RunAsNonRoot != nil {
+		target.RunAsNonRoot = overrides.RunAsNonRoot
+	}
+	if overrides.ReadOnlyRootFilesystem != nil {
+		target.ReadOnlyRootFilesystem = overrides.ReadOnlyRootFilesystem
+	}
+	if overrides.AllowPrivilegeEscalation != nil {
+		target.AllowPrivilegeEscalation = overrides.AllowPrivilegeEscalation
+	}
+	if overrides.ProcMount != nil {
+		target.ProcMount = overrides.ProcMount
+	}
+	if overrides.SeccompProfile != nil {
+		target.SeccompProfile = overrides.SeccompProfile
+	}
+}
@@ -0,0 +1,85 @@
+package modifiers
+
+import (
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSecurityContextEnabled(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.PodSecurityContext = &corev1.PodSecurityContext{}
+
+		mod := NewSecurityContextModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("false", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+
+		mod := NewSecurityContextModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+
+	t.Run("false for other capabilities", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		dynakube.Spec.KubernetesMonitoring.SecurityContext = &corev1.SecurityContext{}
+
+		mod := NewSecurityContextModifier(dynakube, capability.NewMultiCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+}
+
+func TestSecurityContextModify(t *testing.T) {
+	t.Run("pod security context is set", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.PodSecurityContext = &corev1.PodSecurityContext{
+			RunAsUser: address.Of(int64(1001)),
+			FSGroup:   address.Of(int64(1001)),
+		}
+		mod := NewSecurityContextModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		builder := createBuilderForTesting()
+
+		sts := builder.AddModifier(mod).Build()
+
+		assert.Equal(t, dynakube.Spec.KubernetesMonitoring.PodSecurityContext, sts.Spec.Template.Spec.SecurityContext)
+	})
+
+	t.Run("container security context is merged over the defaults", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.SecurityContext = &corev1.SecurityContext{
+			RunAsUser:              address.Of(int64(1001)),
+			ReadOnlyRootFilesystem: address.Of(true),
+		}
+		mod := NewSecurityContextModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := createBuilderForTesting().Build()
+		sts.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+			RunAsNonRoot: address.Of(true),
+			RunAsUser:    address.Of(int64(0)),
+			Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		}
+
+		mod.Modify(&sts)
+
+		require.Len(t, sts.Spec.Template.Spec.Containers, 1)
+		securityContext := sts.Spec.Template.Spec.Containers[0].SecurityContext
+		assert.Equal(t, int64(1001), *securityContext.RunAsUser)
+		assert.True(t, *securityContext.ReadOnlyRootFilesystem)
+		assert.True(t, *securityContext.RunAsNonRoot)
+		assert.Equal(t, []corev1.Capability{"ALL"}, securityContext.Capabilities.Drop)
+		assert.Nil(t, sts.Spec.Template.Spec.SecurityContext)
+	})
+}
@@ -0,0 +1,67 @@
+package modifiers
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ volumeModifier = TrustedCAsModifier{}
+var _ volumeMountModifier = TrustedCAsModifier{}
+var _ builder.Modifier = TrustedCAsModifier{}
+
+func NewTrustedCAsModifier(dynakube dynatracev1beta1.DynaKube) TrustedCAsModifier {
+	return TrustedCAsModifier{
+		dynakube: dynakube,
+	}
+}
+
+// TrustedCAsModifier mounts the certificates of the trusted CAs config map into the ActiveGate container,
+// so it trusts the same CAs as the operator when connecting to the Dynatrace cluster
+type TrustedCAsModifier struct {
+	dynakube dynatracev1beta1.DynaKube
+}
+
+func (mod TrustedCAsModifier) Enabled() bool {
+	return mod.dynakube.Spec.TrustedCAs != ""
+}
+
+func (mod TrustedCAsModifier) Modify(sts *appsv1.StatefulSet) {
+	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+	sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, mod.getVolumes()...)
+	baseContainer.VolumeMounts = append(baseContainer.VolumeMounts, mod.getVolumeMounts()...)
+}
+
+func (mod TrustedCAsModifier) getVolumes() []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: consts.TrustedCAsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: mod.dynakube.Spec.TrustedCAs,
+					},
+					Items: []corev1.KeyToPath{
+						{
+							Key:  dynatracev1beta1.TrustedCAKey,
+							Path: consts.TrustedCAsFile,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (mod TrustedCAsModifier) getVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			ReadOnly:  true,
+			Name:      consts.TrustedCAsVolumeName,
+			MountPath: consts.TrustedCAsMountPoint,
+		},
+	}
+}
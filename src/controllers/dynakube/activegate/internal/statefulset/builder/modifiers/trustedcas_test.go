@@ -0,0 +1,62 @@
+package modifiers
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTrustedCAsName = "test-trusted-cas"
+
+func setTrustedCAsUsage(dynakube *dynatracev1beta1.DynaKube, isUsed bool) {
+	if isUsed {
+		dynakube.Spec.TrustedCAs = testTrustedCAsName
+	} else {
+		dynakube.Spec.TrustedCAs = ""
+	}
+}
+
+func TestTrustedCAsEnabled(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		setTrustedCAsUsage(&dynakube, true)
+
+		mod := NewTrustedCAsModifier(dynakube)
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("false", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		setTrustedCAsUsage(&dynakube, false)
+
+		mod := NewTrustedCAsModifier(dynakube)
+
+		assert.False(t, mod.Enabled())
+	})
+}
+
+func TestTrustedCAsModify(t *testing.T) {
+	t.Run("successfully modified", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		setTrustedCAsUsage(&dynakube, true)
+		mod := NewTrustedCAsModifier(dynakube)
+		builder := createBuilderForTesting()
+
+		sts := builder.AddModifier(mod).Build()
+
+		require.NotEmpty(t, sts)
+		isSubset(t, mod.getVolumes(), sts.Spec.Template.Spec.Volumes)
+		isSubset(t, mod.getVolumeMounts(), sts.Spec.Template.Spec.Containers[0].VolumeMounts)
+		configMap := mod.getVolumes()[0].ConfigMap
+		assert.Equal(t, testTrustedCAsName, configMap.Name)
+		assert.Equal(t, dynatracev1beta1.TrustedCAKey, configMap.Items[0].Key)
+		assert.Equal(t, consts.TrustedCAsFile, configMap.Items[0].Path)
+	})
+}
@@ -0,0 +1,48 @@
+package modifiers
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ volumeModifier = CustomVolumesModifier{}
+var _ volumeMountModifier = CustomVolumesModifier{}
+var _ builder.Modifier = CustomVolumesModifier{}
+
+func NewCustomVolumesModifier(dynakube dynatracev1beta1.DynaKube, capability capability.Capability) CustomVolumesModifier {
+	return CustomVolumesModifier{
+		dynakube:   dynakube,
+		capability: capability,
+	}
+}
+
+// CustomVolumesModifier adds the volumes and volume mounts defined by the user for the Kubernetes monitoring ActiveGate.
+// It has to run after all other modifiers, so name collisions with operator managed volumes can be detected afterwards.
+type CustomVolumesModifier struct {
+	dynakube   dynatracev1beta1.DynaKube
+	capability capability.Capability
+}
+
+func (mod CustomVolumesModifier) Enabled() bool {
+	_, isKubeMon := mod.capability.(*capability.KubeMonCapability)
+	return isKubeMon && (len(mod.getVolumes()) > 0 || len(mod.getVolumeMounts()) > 0)
+}
+
+func (mod CustomVolumesModifier) Modify(sts *appsv1.StatefulSet) {
+	baseContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+	sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, mod.getVolumes()...)
+	baseContainer.VolumeMounts = append(baseContainer.VolumeMounts, mod.getVolumeMounts()...)
+}
+
+func (mod CustomVolumesModifier) getVolumes() []corev1.Volume {
+	return mod.dynakube.Spec.KubernetesMonitoring.Volumes
+}
+
+func (mod CustomVolumesModifier) getVolumeMounts() []corev1.VolumeMount {
+	return mod.dynakube.Spec.KubernetesMonitoring.VolumeMounts
+}
@@ -0,0 +1,81 @@
+package modifiers
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func setCustomVolumesUsage(dynakube *dynatracev1beta1.DynaKube, isUsed bool) {
+	if isUsed {
+		dynakube.Spec.KubernetesMonitoring.Volumes = []corev1.Volume{
+			{
+				Name: "custom-volume",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
+		}
+		dynakube.Spec.KubernetesMonitoring.VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      "custom-volume",
+				MountPath: "/custom",
+			},
+		}
+	} else {
+		dynakube.Spec.KubernetesMonitoring.Volumes = nil
+		dynakube.Spec.KubernetesMonitoring.VolumeMounts = nil
+	}
+}
+
+func TestCustomVolumesEnabled(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setCustomVolumesUsage(&dynakube, true)
+
+		mod := NewCustomVolumesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.True(t, mod.Enabled())
+	})
+
+	t.Run("false", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setCustomVolumesUsage(&dynakube, false)
+
+		mod := NewCustomVolumesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+
+	t.Run("false for other capabilities", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		enableKubeMonCapability(&dynakube)
+		setCustomVolumesUsage(&dynakube, true)
+
+		mod := NewCustomVolumesModifier(dynakube, capability.NewMultiCapability(&dynakube))
+
+		assert.False(t, mod.Enabled())
+	})
+}
+
+func TestCustomVolumesModify(t *testing.T) {
+	t.Run("successfully modified", func(t *testing.T) {
+		dynakube := getBaseDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		setCustomVolumesUsage(&dynakube, true)
+		mod := NewCustomVolumesModifier(dynakube, capability.NewKubeMonCapability(&dynakube))
+		builder := createBuilderForTesting()
+
+		sts := builder.AddModifier(mod).Build()
+
+		require.NotEmpty(t, sts)
+		isSubset(t, mod.getVolumes(), sts.Spec.Template.Spec.Volumes)
+		isSubset(t, mod.getVolumeMounts(), sts.Spec.Template.Spec.Containers[0].VolumeMounts)
+	})
+}
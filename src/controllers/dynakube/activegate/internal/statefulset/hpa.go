@@ -0,0 +1,155 @@
+package statefulset
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const defaultTargetCPUUtilizationPercentage = 80
+
+// autoscaling returns the autoscaling spec of the Kubernetes monitoring spec for the kubemon capability,
+// nil if autoscaling is disabled or the capability is a different one
+func (r *Reconciler) autoscaling() *dynatracev1beta1.AutoscalingSpec {
+	if _, isKubeMon := r.capability.(*capability.KubeMonCapability); !isKubeMon {
+		return nil
+	}
+	return r.dynakube.Spec.KubernetesMonitoring.Autoscaling
+}
+
+// autoscaledInitialReplicas returns the amount of replicas a new StatefulSet starts with before the autoscaler takes over
+func autoscaledInitialReplicas(autoscaling *dynatracev1beta1.AutoscalingSpec) *int32 {
+	if autoscaling.MinReplicas != nil {
+		return address.Of(*autoscaling.MinReplicas)
+	}
+	return address.Of(int32(defaultMinReplicas))
+}
+
+// buildHorizontalPodAutoscaler returns the HorizontalPodAutoscaler that scales sts within the bounds of autoscaling.
+// Without custom metrics it targets the average CPU utilization of the pods.
+func buildHorizontalPodAutoscaler(sts *appsv1.StatefulSet, autoscaling *dynatracev1beta1.AutoscalingSpec) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	metrics := make([]autoscalingv2.MetricSpec, 0, len(autoscaling.Metrics))
+	for _, metric := range autoscaling.Metrics {
+		metrics = append(metrics, *metric.DeepCopy())
+	}
+
+	if len(metrics) == 0 {
+		targetUtilization := int32(defaultTargetCPUUtilizationPercentage)
+		if autoscaling.TargetCPUUtilizationPercentage != nil {
+			targetUtilization = *autoscaling.TargetCPUUtilizationPercentage
+		}
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &targetUtilization,
+				},
+			},
+		})
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        sts.Name,
+			Namespace:   sts.Namespace,
+			Labels:      kubeobjects.MergeMap(sts.Labels),
+			Annotations: map[string]string{},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "StatefulSet",
+				Name:       sts.Name,
+			},
+			MinReplicas: autoscaledInitialReplicas(autoscaling),
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+
+	hash, err := kubeobjects.GenerateHash(hpa)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	hpa.Annotations[kubeobjects.AnnotationHash] = hash
+	return hpa, nil
+}
+
+// manageHorizontalPodAutoscaler creates or updates the HorizontalPodAutoscaler of the desired StatefulSet,
+// or removes it once autoscaling is disabled
+func (r *Reconciler) manageHorizontalPodAutoscaler(desiredSts *appsv1.StatefulSet) error {
+	autoscaling := r.autoscaling()
+	if autoscaling == nil {
+		return r.deleteHorizontalPodAutoscalerIfExists(desiredSts)
+	}
+
+	desiredHpa, err := buildHorizontalPodAutoscaler(desiredSts, autoscaling)
+	if err != nil {
+		return err
+	}
+
+	if err = controllerutil.SetControllerReference(r.dynakube, desiredHpa, r.scheme); err != nil {
+		return errors.WithStack(err)
+	}
+
+	created, err := r.createHorizontalPodAutoscalerIfNotExists(desiredHpa)
+	if created || err != nil {
+		return err
+	}
+
+	return r.updateHorizontalPodAutoscalerIfOutdated(desiredHpa)
+}
+
+func (r *Reconciler) createHorizontalPodAutoscalerIfNotExists(desiredHpa *autoscalingv2.HorizontalPodAutoscaler) (bool, error) {
+	var currentHpa autoscalingv2.HorizontalPodAutoscaler
+	err := r.client.Get(r.ctx, kubeobjects.Key(desiredHpa), &currentHpa)
+	if k8serrors.IsNotFound(err) {
+		r.log.Info("creating horizontal pod autoscaler", "horizontalPodAutoscaler", desiredHpa.Name)
+		return true, errors.WithStack(r.client.Create(r.ctx, desiredHpa))
+	}
+	return false, errors.WithStack(err)
+}
+
+func (r *Reconciler) updateHorizontalPodAutoscalerIfOutdated(desiredHpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	var currentHpa autoscalingv2.HorizontalPodAutoscaler
+	err := r.client.Get(r.ctx, kubeobjects.Key(desiredHpa), &currentHpa)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !kubeobjects.IsHashAnnotationDifferent(&currentHpa, desiredHpa) {
+		return nil
+	}
+
+	r.log.Info("updating horizontal pod autoscaler", "horizontalPodAutoscaler", desiredHpa.Name)
+	desiredHpa.ResourceVersion = currentHpa.ResourceVersion
+	return errors.WithStack(r.client.Update(r.ctx, desiredHpa))
+}
+
+func (r *Reconciler) deleteHorizontalPodAutoscalerIfExists(desiredSts *appsv1.StatefulSet) error {
+	hpa := autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desiredSts.Name,
+			Namespace: desiredSts.Namespace,
+		},
+	}
+	return errors.WithStack(kubeobjects.DeleteIfExists(r.ctx, r.client, &hpa))
+}
+
+// keepAutoscaledReplicas copies the replicas of the current StatefulSet to the desired one,
+// so an update doesn't revert the scaling of the autoscaler
+func keepAutoscaledReplicas(currentSts, desiredSts *appsv1.StatefulSet) {
+	if currentSts.Spec.Replicas != nil {
+		desiredSts.Spec.Replicas = address.Of(*currentSts.Spec.Replicas)
+	}
+}
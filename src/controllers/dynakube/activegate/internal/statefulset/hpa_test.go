@@ -0,0 +1,141 @@
+package statefulset
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createAutoscalingReconciler(t *testing.T, autoscaling *dynatracev1beta1.AutoscalingSpec) *Reconciler {
+	r := createDefaultReconciler(t)
+	r.dynakube.Spec.ActiveGate = dynatracev1beta1.ActiveGateSpec{}
+	r.dynakube.Spec.KubernetesMonitoring.Enabled = true
+	r.dynakube.Spec.KubernetesMonitoring.Autoscaling = autoscaling
+	r.capability = capability.NewKubeMonCapability(r.dynakube)
+	return r
+}
+
+func getHorizontalPodAutoscaler(t *testing.T, r *Reconciler) (*autoscalingv2.HorizontalPodAutoscaler, *appsv1.StatefulSet, error) {
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	err = r.client.Get(context.TODO(), kubeobjects.Key(desiredSts), &hpa)
+	return &hpa, desiredSts, err
+}
+
+func TestBuildHorizontalPodAutoscaler(t *testing.T) {
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "dynakube-kubemon", Namespace: testNamespace}}
+
+	t.Run("targets the cpu utilization by default", func(t *testing.T) {
+		hpa, err := buildHorizontalPodAutoscaler(sts, &dynatracev1beta1.AutoscalingSpec{MaxReplicas: 3})
+		require.NoError(t, err)
+
+		assert.Equal(t, "StatefulSet", hpa.Spec.ScaleTargetRef.Kind)
+		assert.Equal(t, sts.Name, hpa.Spec.ScaleTargetRef.Name)
+		assert.Equal(t, int32(1), *hpa.Spec.MinReplicas)
+		assert.Equal(t, int32(3), hpa.Spec.MaxReplicas)
+		require.Len(t, hpa.Spec.Metrics, 1)
+		assert.Equal(t, corev1.ResourceCPU, hpa.Spec.Metrics[0].Resource.Name)
+		assert.Equal(t, int32(defaultTargetCPUUtilizationPercentage), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+		assert.NotEmpty(t, hpa.Annotations[kubeobjects.AnnotationHash])
+	})
+	t.Run("custom metrics replace the cpu utilization", func(t *testing.T) {
+		queueMetric := autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: "activegate_queue_size"},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType},
+			},
+		}
+		hpa, err := buildHorizontalPodAutoscaler(sts, &dynatracev1beta1.AutoscalingSpec{
+			MinReplicas:                    address.Of(int32(2)),
+			MaxReplicas:                    5,
+			TargetCPUUtilizationPercentage: address.Of(int32(50)),
+			Metrics:                        []autoscalingv2.MetricSpec{queueMetric},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+		assert.Equal(t, []autoscalingv2.MetricSpec{queueMetric}, hpa.Spec.Metrics)
+	})
+}
+
+func TestReconcile_HorizontalPodAutoscaler(t *testing.T) {
+	t.Run("no horizontal pod autoscaler without autoscaling", func(t *testing.T) {
+		r := createAutoscalingReconciler(t, nil)
+
+		require.NoError(t, r.Reconcile())
+
+		_, _, err := getHorizontalPodAutoscaler(t, r)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run("no horizontal pod autoscaler for other capabilities", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		r.dynakube.Spec.KubernetesMonitoring.Autoscaling = &dynatracev1beta1.AutoscalingSpec{MaxReplicas: 3}
+
+		require.NoError(t, r.Reconcile())
+
+		_, _, err := getHorizontalPodAutoscaler(t, r)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run("autoscaling creates and updates the horizontal pod autoscaler", func(t *testing.T) {
+		r := createAutoscalingReconciler(t, &dynatracev1beta1.AutoscalingSpec{MaxReplicas: 3})
+
+		require.NoError(t, r.Reconcile())
+
+		hpa, desiredSts, err := getHorizontalPodAutoscaler(t, r)
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), hpa.Spec.MaxReplicas)
+		assert.Equal(t, int32(1), *desiredSts.Spec.Replicas)
+		assert.True(t, metav1.IsControlledBy(hpa, r.dynakube))
+
+		r.dynakube.Spec.KubernetesMonitoring.Autoscaling.MaxReplicas = 5
+		require.NoError(t, r.Reconcile())
+
+		hpa, _, err = getHorizontalPodAutoscaler(t, r)
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), hpa.Spec.MaxReplicas)
+	})
+	t.Run("disabling autoscaling removes the horizontal pod autoscaler", func(t *testing.T) {
+		r := createAutoscalingReconciler(t, &dynatracev1beta1.AutoscalingSpec{MaxReplicas: 3})
+		require.NoError(t, r.Reconcile())
+		_, _, err := getHorizontalPodAutoscaler(t, r)
+		require.NoError(t, err)
+
+		r.dynakube.Spec.KubernetesMonitoring.Autoscaling = nil
+		require.NoError(t, r.Reconcile())
+
+		_, _, err = getHorizontalPodAutoscaler(t, r)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run("update keeps the replicas of the autoscaler", func(t *testing.T) {
+		r := createAutoscalingReconciler(t, &dynatracev1beta1.AutoscalingSpec{MaxReplicas: 5})
+		require.NoError(t, r.Reconcile())
+
+		_, desiredSts, err := getHorizontalPodAutoscaler(t, r)
+		require.NoError(t, err)
+		var sts appsv1.StatefulSet
+		require.NoError(t, r.client.Get(context.TODO(), kubeobjects.Key(desiredSts), &sts))
+		sts.Spec.Replicas = address.Of(int32(4))
+		require.NoError(t, r.client.Update(context.TODO(), &sts))
+
+		r.dynakube.Spec.KubernetesMonitoring.Labels = map[string]string{"changed": "label"}
+		require.NoError(t, r.Reconcile())
+
+		require.NoError(t, r.client.Get(context.TODO(), kubeobjects.Key(desiredSts), &sts))
+		assert.Equal(t, "label", sts.Labels["changed"])
+		assert.Equal(t, int32(4), *sts.Spec.Replicas)
+	})
+}
@@ -0,0 +1,101 @@
+package statefulset
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// buildPodDisruptionBudget returns the PodDisruptionBudget for the pods of sts, which allows evicting one pod at a time.
+// nil is returned for a single replica, as the budget would block draining its node completely.
+func buildPodDisruptionBudget(sts *appsv1.StatefulSet) (*policyv1.PodDisruptionBudget, error) {
+	if sts.Spec.Replicas == nil || *sts.Spec.Replicas <= 1 {
+		return nil, nil
+	}
+
+	minAvailable := intstr.FromInt(int(*sts.Spec.Replicas) - 1)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        sts.Name,
+			Namespace:   sts.Namespace,
+			Labels:      kubeobjects.MergeMap(sts.Labels),
+			Annotations: map[string]string{},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: kubeobjects.MergeMap(sts.Spec.Template.Labels)},
+		},
+	}
+
+	hash, err := kubeobjects.GenerateHash(pdb)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pdb.Annotations[kubeobjects.AnnotationHash] = hash
+	return pdb, nil
+}
+
+// managePodDisruptionBudget creates or updates the PodDisruptionBudget of the desired StatefulSet,
+// or removes it once the StatefulSet is scaled down to a single replica
+func (r *Reconciler) managePodDisruptionBudget(desiredSts *appsv1.StatefulSet) error {
+	desiredPdb, err := buildPodDisruptionBudget(desiredSts)
+	if err != nil {
+		return err
+	}
+
+	if desiredPdb == nil {
+		return r.deletePodDisruptionBudgetIfExists(desiredSts)
+	}
+
+	if err = controllerutil.SetControllerReference(r.dynakube, desiredPdb, r.scheme); err != nil {
+		return errors.WithStack(err)
+	}
+
+	created, err := r.createPodDisruptionBudgetIfNotExists(desiredPdb)
+	if created || err != nil {
+		return err
+	}
+
+	return r.updatePodDisruptionBudgetIfOutdated(desiredPdb)
+}
+
+func (r *Reconciler) createPodDisruptionBudgetIfNotExists(desiredPdb *policyv1.PodDisruptionBudget) (bool, error) {
+	var currentPdb policyv1.PodDisruptionBudget
+	err := r.client.Get(r.ctx, kubeobjects.Key(desiredPdb), &currentPdb)
+	if k8serrors.IsNotFound(err) {
+		r.log.Info("creating pod disruption budget", "podDisruptionBudget", desiredPdb.Name)
+		return true, errors.WithStack(r.client.Create(r.ctx, desiredPdb))
+	}
+	return false, errors.WithStack(err)
+}
+
+func (r *Reconciler) updatePodDisruptionBudgetIfOutdated(desiredPdb *policyv1.PodDisruptionBudget) error {
+	var currentPdb policyv1.PodDisruptionBudget
+	err := r.client.Get(r.ctx, kubeobjects.Key(desiredPdb), &currentPdb)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !kubeobjects.IsHashAnnotationDifferent(&currentPdb, desiredPdb) {
+		return nil
+	}
+
+	r.log.Info("updating pod disruption budget", "podDisruptionBudget", desiredPdb.Name)
+	desiredPdb.ResourceVersion = currentPdb.ResourceVersion
+	return errors.WithStack(r.client.Update(r.ctx, desiredPdb))
+}
+
+func (r *Reconciler) deletePodDisruptionBudgetIfExists(desiredSts *appsv1.StatefulSet) error {
+	pdb := policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desiredSts.Name,
+			Namespace: desiredSts.Namespace,
+		},
+	}
+	return errors.WithStack(kubeobjects.Delete(r.ctx, r.client, &pdb))
+}
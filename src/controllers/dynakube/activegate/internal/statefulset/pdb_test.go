@@ -0,0 +1,85 @@
+package statefulset
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func getPodDisruptionBudget(t *testing.T, r *Reconciler) (*policyv1.PodDisruptionBudget, *appsv1.StatefulSet, error) {
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	var pdb policyv1.PodDisruptionBudget
+	err = r.client.Get(context.TODO(), kubeobjects.Key(desiredSts), &pdb)
+	return &pdb, desiredSts, err
+}
+
+func TestReconcile_PodDisruptionBudget(t *testing.T) {
+	t.Run("no pod disruption budget for a single replica", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		replicas := int32(1)
+		r.capability.Properties().Replicas = &replicas
+
+		require.NoError(t, r.Reconcile())
+
+		_, _, err := getPodDisruptionBudget(t, r)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run("scale up creates and updates the pod disruption budget", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		replicas := int32(2)
+		r.capability.Properties().Replicas = &replicas
+
+		require.NoError(t, r.Reconcile())
+
+		pdb, desiredSts, err := getPodDisruptionBudget(t, r)
+		require.NoError(t, err)
+		assert.Equal(t, intstr.FromInt(1), *pdb.Spec.MinAvailable)
+		assert.Equal(t, desiredSts.Spec.Template.Labels, pdb.Spec.Selector.MatchLabels)
+		assert.True(t, metav1.IsControlledBy(pdb, r.dynakube))
+
+		replicas = 3
+		require.NoError(t, r.Reconcile())
+
+		pdb, _, err = getPodDisruptionBudget(t, r)
+		require.NoError(t, err)
+		assert.Equal(t, intstr.FromInt(2), *pdb.Spec.MinAvailable)
+	})
+	t.Run("scale down removes the pod disruption budget", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		replicas := int32(2)
+		r.capability.Properties().Replicas = &replicas
+		require.NoError(t, r.Reconcile())
+		_, _, err := getPodDisruptionBudget(t, r)
+		require.NoError(t, err)
+
+		replicas = 1
+		require.NoError(t, r.Reconcile())
+
+		_, _, err = getPodDisruptionBudget(t, r)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run("unchanged pod disruption budget is not updated", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		replicas := int32(2)
+		r.capability.Properties().Replicas = &replicas
+		require.NoError(t, r.Reconcile())
+		pdb, _, err := getPodDisruptionBudget(t, r)
+		require.NoError(t, err)
+
+		require.NoError(t, r.Reconcile())
+
+		unchangedPdb, _, err := getPodDisruptionBudget(t, r)
+		require.NoError(t, err)
+		assert.Equal(t, pdb.ResourceVersion, unchangedPdb.ResourceVersion)
+	})
+}
@@ -9,60 +9,95 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/authtoken"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/customproperties"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/statefulset/builder"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var _ controllers.Reconciler = &Reconciler{}
 
+const statefulSetRecreatedEvent = "StatefulSetRecreated"
+
 type Reconciler struct {
+	ctx        context.Context
 	client     client.Client
 	dynakube   *dynatracev1beta1.DynaKube
 	apiReader  client.Reader
 	scheme     *runtime.Scheme
+	recorder   record.EventRecorder
 	capability capability.Capability
 	modifiers  []builder.Modifier
+
+	timeProvider *kubeobjects.TimeProvider
+
+	// log adds the capability to the correlation values of the reconcile context, so the log lines of multiple capabilities can be told apart
+	log logr.Logger
 }
 
-func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, dynakube *dynatracev1beta1.DynaKube, capability capability.Capability) *Reconciler {
+func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, dynakube *dynatracev1beta1.DynaKube, capability capability.Capability) *Reconciler {
 	return &Reconciler{
+		ctx:        ctx,
 		client:     clt,
 		apiReader:  apiReader,
 		scheme:     scheme,
+		recorder:   recorder,
 		dynakube:   dynakube,
 		capability: capability,
 		modifiers:  []builder.Modifier{},
+
+		timeProvider: kubeobjects.NewTimeProvider(),
+		log:          logger.FromContext(ctx, log).WithValues("capability", capability.ShortName()),
 	}
 }
 
-type NewReconcilerFunc = func(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, dynakube *dynatracev1beta1.DynaKube, capability capability.Capability) *Reconciler
+type NewReconcilerFunc = func(ctx context.Context, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, dynakube *dynatracev1beta1.DynaKube, capability capability.Capability) *Reconciler
 
 func (r *Reconciler) Reconcile() error {
-	err := r.manageStatefulSet()
+	desiredSts, err := r.buildDesiredStatefulSet()
 	if err != nil {
-		log.Error(err, "could not reconcile stateful set")
+		r.log.Error(err, "could not build stateful set")
 		return errors.WithStack(err)
 	}
 
-	return nil
-}
+	err = r.manageStatefulSet(desiredSts)
+	if err != nil {
+		r.log.Error(err, "could not reconcile stateful set")
+		return errors.WithStack(err)
+	}
 
-func (r *Reconciler) manageStatefulSet() error {
-	desiredSts, err := r.buildDesiredStatefulSet()
+	err = r.managePodDisruptionBudget(desiredSts)
+	if err != nil {
+		r.log.Error(err, "could not reconcile pod disruption budget")
+		return errors.WithStack(err)
+	}
+
+	err = r.manageHorizontalPodAutoscaler(desiredSts)
 	if err != nil {
+		r.log.Error(err, "could not reconcile horizontal pod autoscaler")
 		return errors.WithStack(err)
 	}
 
+	return nil
+}
+
+func (r *Reconciler) manageStatefulSet(desiredSts *appsv1.StatefulSet) error {
 	if err := controllerutil.SetControllerReference(r.dynakube, desiredSts, r.scheme); err != nil {
 		return errors.WithStack(err)
 	}
@@ -72,6 +107,11 @@ func (r *Reconciler) manageStatefulSet() error {
 		return errors.WithStack(err)
 	}
 
+	err = r.adoptStatefulSetIfUnowned(desiredSts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	deleted, err := r.deleteStatefulSetIfOldLabelsAreUsed(desiredSts)
 	if deleted || err != nil {
 		return errors.WithStack(err)
@@ -86,9 +126,11 @@ func (r *Reconciler) manageStatefulSet() error {
 }
 
 func (r *Reconciler) buildDesiredStatefulSet() (*appsv1.StatefulSet, error) {
-	kubeUID, err := kubesystem.GetUID(r.apiReader)
+	kubeUID, err := kubesystem.GetUID(r.ctx, r.apiReader)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		// the cluster ID is only used for correlation, so the ActiveGate is deployed without it rather than not at all
+		r.log.Info("could not get cluster ID, using the last known one", "error", err.Error())
+		kubeUID = types.UID(r.dynakube.Status.KubeSystemUUID)
 	}
 
 	activeGateConfigurationHash, err := r.calculateActiveGateConfigurationHash()
@@ -96,28 +138,130 @@ func (r *Reconciler) buildDesiredStatefulSet() (*appsv1.StatefulSet, error) {
 		return nil, errors.WithStack(err)
 	}
 
-	statefulSetBuilder := NewStatefulSetBuilder(kubeUID, activeGateConfigurationHash, *r.dynakube, r.capability)
+	replicas, err := r.getReplicas()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	pullSecretHash, err := r.calculatePullSecretHash()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	image, imageVersion, err := r.getAutoUpdateImage()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	statefulSetBuilder := NewStatefulSetBuilder(kubeUID, activeGateConfigurationHash, *r.dynakube, r.capability).
+		WithReplicas(replicas).
+		WithPullSecretHash(pullSecretHash).
+		WithImage(image, imageVersion)
 
 	desiredSts, err := statefulSetBuilder.CreateStatefulSet(r.modifiers)
 	return desiredSts, errors.WithStack(err)
 }
 
+// getReplicas returns the replicas a new StatefulSet is created with, nil leaves them to the capability properties.
+// With autoscaling the StatefulSet starts with the minimum of the autoscaler, which keeps the hash stable while it scales.
+func (r *Reconciler) getReplicas() (*int32, error) {
+	if autoscaling := r.autoscaling(); autoscaling != nil {
+		return autoscaledInitialReplicas(autoscaling), nil
+	}
+	return r.getAutoSizedReplicas()
+}
+
 func (r *Reconciler) getStatefulSet(desiredSts *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
 	var sts appsv1.StatefulSet
-	err := r.client.Get(context.TODO(), client.ObjectKey{Name: desiredSts.Name, Namespace: desiredSts.Namespace}, &sts)
+	err := r.client.Get(r.ctx, client.ObjectKey{Name: desiredSts.Name, Namespace: desiredSts.Namespace}, &sts)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return &sts, nil
 }
 
+// createStatefulSetIfNotExists creates the desired StatefulSet if neither the cache nor the api server know it.
+// A StatefulSet that was created shortly before may not be in the cache yet, in that case true is returned as well,
+// so the remaining steps are skipped until the cache caught up.
 func (r *Reconciler) createStatefulSetIfNotExists(desiredSts *appsv1.StatefulSet) (bool, error) {
 	_, err := r.getStatefulSet(desiredSts)
-	if err != nil && k8serrors.IsNotFound(errors.Cause(err)) {
-		log.Info("creating new stateful set for " + r.capability.ShortName())
-		return true, r.client.Create(context.TODO(), desiredSts)
+	if err == nil || !k8serrors.IsNotFound(errors.Cause(err)) {
+		return false, err
+	}
+
+	var sts appsv1.StatefulSet
+	err = r.apiReader.Get(r.ctx, client.ObjectKey{Name: desiredSts.Name, Namespace: desiredSts.Namespace}, &sts)
+	if err == nil {
+		r.log.Info("stateful set is not in the cache yet", "statefulSet", desiredSts.Name)
+		return true, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return false, errors.WithStack(err)
+	}
+
+	r.log.Info("creating new stateful set", "statefulSet", desiredSts.Name)
+	err = r.client.Create(r.ctx, desiredSts)
+	if k8serrors.IsAlreadyExists(err) {
+		r.log.Info("stateful set was created concurrently", "statefulSet", desiredSts.Name)
+		return true, nil
+	}
+	return true, errors.WithStack(err)
+}
+
+// adoptStatefulSetIfUnowned takes ownership of a StatefulSet that was created outside the operator (e.g. by a previous helm install),
+// as long as it selects the same pods as the desired one. Otherwise it returns an error, so the operator doesn't co-manage it with another controller.
+// It also repairs the controller reference if it was removed or still points to a previous incarnation of the DynaKube, e.g. after a restore from backup.
+// This runs on every reconcile, independent of the hash of the StatefulSet.
+func (r *Reconciler) adoptStatefulSetIfUnowned(desiredSts *appsv1.StatefulSet) error {
+	currentSts, err := r.getStatefulSet(desiredSts)
+	if err != nil {
+		return err
+	}
+
+	if metav1.IsControlledBy(currentSts, r.dynakube) {
+		return nil
+	}
+
+	if owner := metav1.GetControllerOf(currentSts); owner != nil {
+		if !r.isStaleDynakubeReference(*owner) {
+			return errors.Errorf("stateful set %s already exists and is controlled by %s %s", currentSts.Name, owner.Kind, owner.Name)
+		}
+		r.log.Info("replacing outdated controller reference of stateful set", "statefulSet", currentSts.Name, "outdatedUID", owner.UID)
+		currentSts.OwnerReferences = removeOwnerReference(currentSts.OwnerReferences, owner.UID)
 	}
-	return false, err
+
+	if currentSts.Spec.Selector == nil || kubeobjects.LabelsNotEqual(currentSts.Spec.Selector.MatchLabels, desiredSts.Spec.Selector.MatchLabels) {
+		return errors.Errorf("stateful set %s already exists and is not managed by the operator, its selector labels don't match the ones of the dynakube", currentSts.Name)
+	}
+
+	if err = controllerutil.SetControllerReference(r.dynakube, currentSts, r.scheme); err != nil {
+		return errors.WithStack(err)
+	}
+
+	r.log.Info("adopting existing stateful set", "statefulSet", currentSts.Name)
+	return errors.WithStack(r.client.Update(r.ctx, currentSts))
+}
+
+// isStaleDynakubeReference returns true if owner references a DynaKube with the same name, but a different UID.
+// This happens if the DynaKube was recreated while the StatefulSet was kept, e.g. by a backup and restore.
+func (r *Reconciler) isStaleDynakubeReference(owner metav1.OwnerReference) bool {
+	ownerGroupVersion, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return false
+	}
+	return ownerGroupVersion.Group == dynatracev1beta1.GroupVersion.Group &&
+		owner.Kind == "DynaKube" &&
+		owner.Name == r.dynakube.Name &&
+		owner.UID != r.dynakube.UID
+}
+
+func removeOwnerReference(ownerReferences []metav1.OwnerReference, uid types.UID) []metav1.OwnerReference {
+	filtered := make([]metav1.OwnerReference, 0, len(ownerReferences))
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.UID != uid {
+			filtered = append(filtered, ownerReference)
+		}
+	}
+	return filtered
 }
 
 func (r *Reconciler) updateStatefulSetIfOutdated(desiredSts *appsv1.StatefulSet) (bool, error) {
@@ -126,32 +270,75 @@ func (r *Reconciler) updateStatefulSetIfOutdated(desiredSts *appsv1.StatefulSet)
 		return false, err
 	}
 	if !kubeobjects.IsHashAnnotationDifferent(currentSts, desiredSts) {
-		return false, nil
+		if !r.dynakube.IsForceResyncRequested() {
+			return false, nil
+		}
+		r.log.Info("resync of stateful set forced by annotation", "statefulSet", desiredSts.Name, "annotation", dynatracev1beta1.AnnotationForceResync)
 	}
 
 	if kubeobjects.LabelsNotEqual(currentSts.Spec.Selector.MatchLabels, desiredSts.Spec.Selector.MatchLabels) {
-		return r.recreateStatefulSet(currentSts, desiredSts)
+		return r.recreateStatefulSet(currentSts, desiredSts, "its selector labels changed")
+	}
+
+	if currentSts.Annotations[consts.AnnotationVolumeClaimTemplateHash] != desiredSts.Annotations[consts.AnnotationVolumeClaimTemplateHash] {
+		return r.recreateStatefulSet(currentSts, desiredSts, "its volume claim templates changed")
 	}
 
-	log.Info("updating existing stateful set")
-	if err = r.client.Update(context.TODO(), desiredSts); err != nil {
+	keepInjectedContainers(currentSts, desiredSts, r.dynakube.FeatureActiveGateInjectedContainers())
+	if r.autoscaling() != nil {
+		keepAutoscaledReplicas(currentSts, desiredSts)
+	}
+
+	r.log.Info("updating existing stateful set", "statefulSet", desiredSts.Name)
+	if err = r.client.Update(r.ctx, desiredSts); err != nil {
 		return false, err
 	}
 	return true, err
 }
 
-func (r *Reconciler) recreateStatefulSet(currentSts, desiredSts *appsv1.StatefulSet) (bool, error) {
-	log.Info("immutable section changed on statefulset, deleting and recreating", "name", desiredSts.Name)
+// keepInjectedContainers copies containers added by other webhooks (e.g. service meshes) from the current to the desired StatefulSet,
+// so an update doesn't remove them again. The hash annotation is calculated without them, so they never cause an update on their own.
+func keepInjectedContainers(currentSts, desiredSts *appsv1.StatefulSet, injectedContainerNames []string) {
+	desiredSts.Spec.Template.Spec.Containers = appendInjectedContainers(
+		desiredSts.Spec.Template.Spec.Containers, currentSts.Spec.Template.Spec.Containers, injectedContainerNames)
+	desiredSts.Spec.Template.Spec.InitContainers = appendInjectedContainers(
+		desiredSts.Spec.Template.Spec.InitContainers, currentSts.Spec.Template.Spec.InitContainers, injectedContainerNames)
+}
+
+func appendInjectedContainers(desiredContainers, currentContainers []corev1.Container, injectedContainerNames []string) []corev1.Container {
+	for _, container := range currentContainers {
+		if slices.Contains(injectedContainerNames, container.Name) && !containsContainer(desiredContainers, container.Name) {
+			desiredContainers = append(desiredContainers, container)
+		}
+	}
+	return desiredContainers
+}
+
+func containsContainer(containers []corev1.Container, name string) bool {
+	for _, container := range containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reconciler) recreateStatefulSet(currentSts, desiredSts *appsv1.StatefulSet, reason string) (bool, error) {
+	r.log.Info("immutable section changed on statefulset, deleting and recreating", "statefulSet", desiredSts.Name)
 
-	err := r.client.Delete(context.TODO(), currentSts)
+	err := r.client.Delete(r.ctx, currentSts)
 	if err != nil {
 		return false, err
 	}
 
-	log.Info("deleted statefulset")
-	log.Info("recreating statefulset", "name", desiredSts.Name)
+	r.log.Info("deleted statefulset", "statefulSet", currentSts.Name)
+	r.log.Info("recreating statefulset", "statefulSet", desiredSts.Name)
+	r.recorder.Eventf(r.dynakube,
+		corev1.EventTypeNormal,
+		statefulSetRecreatedEvent,
+		"Recreated stateful set %s, as %s", desiredSts.Name, reason)
 
-	return true, r.client.Create(context.TODO(), desiredSts)
+	return true, r.client.Create(r.ctx, desiredSts)
 }
 
 func (r *Reconciler) deleteStatefulSetIfOldLabelsAreUsed(desiredSts *appsv1.StatefulSet) (bool, error) {
@@ -160,9 +347,9 @@ func (r *Reconciler) deleteStatefulSetIfOldLabelsAreUsed(desiredSts *appsv1.Stat
 		return false, err
 	}
 
-	if !reflect.DeepEqual(currentSts.Labels, desiredSts.Labels) {
-		log.Info("deleting existing stateful set")
-		if err = r.client.Delete(context.TODO(), desiredSts); err != nil {
+	if !reflect.DeepEqual(operatorLabels(currentSts.Labels), operatorLabels(desiredSts.Labels)) {
+		r.log.Info("deleting existing stateful set", "statefulSet", desiredSts.Name)
+		if err = r.client.Delete(r.ctx, desiredSts); err != nil {
 			return false, err
 		}
 		return true, nil
@@ -171,6 +358,24 @@ func (r *Reconciler) deleteStatefulSetIfOldLabelsAreUsed(desiredSts *appsv1.Stat
 	return false, nil
 }
 
+// operatorLabels filters the given labels down to the ones managed by the operator,
+// so changes to user defined labels are rolled out by an update instead of a recreation.
+// The version label is left out as well, an image update is rolled out by the StatefulSet like any other template change.
+func operatorLabels(labels map[string]string) map[string]string {
+	filtered := map[string]string{}
+	for _, key := range []string{
+		kubeobjects.AppNameLabel,
+		kubeobjects.AppCreatedByLabel,
+		kubeobjects.AppManagedByLabel,
+		kubeobjects.AppComponentLabel,
+	} {
+		if value, ok := labels[key]; ok {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
 func (r *Reconciler) calculateActiveGateConfigurationHash() (string, error) {
 	customPropertyData, err := r.getCustomPropertyValue()
 	if err != nil {
@@ -182,18 +387,38 @@ func (r *Reconciler) calculateActiveGateConfigurationHash() (string, error) {
 		return "", errors.WithStack(err)
 	}
 
-	if len(customPropertyData) < 1 && len(authTokenData) < 1 {
+	trustedCAsData, err := r.getTrustedCAsValue()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if len(customPropertyData) < 1 && len(authTokenData) < 1 && len(trustedCAsData) < 1 {
 		return "", nil
 	}
 
 	hash := fnv.New32()
-	if _, err := hash.Write([]byte(customPropertyData + authTokenData)); err != nil {
+	if _, err := hash.Write([]byte(customPropertyData + authTokenData + trustedCAsData)); err != nil {
 		return "", errors.WithStack(err)
 	}
 
 	return strconv.FormatUint(uint64(hash.Sum32()), 10), nil
 }
 
+// calculatePullSecretHash hashes the contents of the pull secret, a missing secret results in an empty hash,
+// as the pods can't pull their image anyway until the pull secret reconciler created it
+func (r *Reconciler) calculatePullSecretHash() (string, error) {
+	var pullSecret corev1.Secret
+	err := r.apiReader.Get(r.ctx, client.ObjectKey{Name: r.dynakube.PullSecret(), Namespace: r.dynakube.Namespace}, &pullSecret)
+	if k8serrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	hash, err := kubeobjects.GenerateHash(pullSecret.Data)
+	return hash, errors.WithStack(err)
+}
+
 func (r *Reconciler) getCustomPropertyValue() (string, error) {
 	if !needsCustomPropertyHash(r.capability.Properties().CustomProperties) {
 		return "", nil
@@ -218,17 +443,43 @@ func (r *Reconciler) getAuthTokenValue() (string, error) {
 	return authTokenData, nil
 }
 
+// getTrustedCAsValue returns the certificates of the trusted CAs config map, so the ActiveGate pods are restarted when they change
+func (r *Reconciler) getTrustedCAsValue() (string, error) {
+	if r.dynakube.Spec.TrustedCAs == "" {
+		return "", nil
+	}
+
+	var configMap corev1.ConfigMap
+	err := r.apiReader.Get(r.ctx, client.ObjectKey{Namespace: r.dynakube.Namespace, Name: r.dynakube.Spec.TrustedCAs}, &configMap)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to read trusted CAs")
+	}
+	return configMap.Data[dynatracev1beta1.TrustedCAKey], nil
+}
+
 func (r *Reconciler) getDataFromCustomProperty(customProperties *dynatracev1beta1.DynaKubeValueSource) (string, error) {
 	if customProperties.ValueFrom != "" {
-		return kubeobjects.GetDataFromSecretName(r.apiReader, types.NamespacedName{Namespace: r.dynakube.Namespace, Name: customProperties.ValueFrom}, customproperties.DataKey, log)
+		return kubeobjects.GetDataFromSecretName(r.ctx, r.apiReader, types.NamespacedName{Namespace: r.dynakube.Namespace, Name: customProperties.ValueFrom}, customproperties.DataKey, r.log)
+	}
+	if customProperties.Value == "" && customProperties.ConfigMapRef != "" {
+		return r.getDataFromConfigMap(customProperties.ConfigMapRef)
 	}
 	return customProperties.Value, nil
 }
 
+func (r *Reconciler) getDataFromConfigMap(name string) (string, error) {
+	var configMap corev1.ConfigMap
+	err := r.apiReader.Get(r.ctx, client.ObjectKey{Namespace: r.dynakube.Namespace, Name: name}, &configMap)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return configMap.Data[customproperties.DataKey], nil
+}
+
 func (r *Reconciler) getDataFromAuthTokenSecret() (string, error) {
-	return kubeobjects.GetDataFromSecretName(r.apiReader, types.NamespacedName{Namespace: r.dynakube.Namespace, Name: r.dynakube.ActiveGateAuthTokenSecret()}, authtoken.ActiveGateAuthTokenName, log)
+	return kubeobjects.GetDataFromSecretName(r.ctx, r.apiReader, types.NamespacedName{Namespace: r.dynakube.Namespace, Name: r.dynakube.ActiveGateAuthTokenSecret()}, authtoken.ActiveGateAuthTokenName, r.log)
 }
 
 func needsCustomPropertyHash(customProperties *dynatracev1beta1.DynaKubeValueSource) bool {
-	return customProperties != nil && (customProperties.Value != "" || customProperties.ValueFrom != "")
+	return customProperties != nil && (customProperties.Value != "" || customProperties.ValueFrom != "" || customProperties.ConfigMapRef != "")
 }
@@ -2,21 +2,29 @@ package statefulset
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/authtoken"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/customproperties"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/go-logr/logr/funcr"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -64,7 +72,7 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 
 	capability.NewRoutingCapability(instance)
 
-	r := NewReconciler(clt, clt, scheme.Scheme, instance, capability.NewRoutingCapability(instance))
+	r := NewReconciler(context.TODO(), clt, clt, scheme.Scheme, record.NewFakeRecorder(10), instance, capability.NewRoutingCapability(instance))
 	r.dynakube.Annotations = map[string]string{}
 	require.NotNil(t, r)
 	require.NotNil(t, r.client)
@@ -74,6 +82,44 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 	return r
 }
 
+type contextRecordingClient struct {
+	client.Client
+	contexts []context.Context
+}
+
+func (clt *contextRecordingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	clt.contexts = append(clt.contexts, ctx)
+	return clt.Client.Get(ctx, key, obj, opts...)
+}
+
+func (clt *contextRecordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	clt.contexts = append(clt.contexts, ctx)
+	return clt.Client.Create(ctx, obj, opts...)
+}
+
+func (clt *contextRecordingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	clt.contexts = append(clt.contexts, ctx)
+	return clt.Client.Update(ctx, obj, opts...)
+}
+
+type testContextKey struct{}
+
+func TestReconcile_PropagatesContext(t *testing.T) {
+	r := createDefaultReconciler(t)
+	recordingClient := &contextRecordingClient{Client: r.client}
+	ctx := context.WithValue(context.Background(), testContextKey{}, testValue)
+	r = NewReconciler(ctx, recordingClient, recordingClient, r.scheme, r.recorder, r.dynakube, r.capability)
+
+	require.NoError(t, r.Reconcile())
+	r.dynakube.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
+	require.NoError(t, r.Reconcile())
+
+	require.NotEmpty(t, recordingClient.contexts)
+	for _, recordedCtx := range recordingClient.contexts {
+		assert.Equal(t, testValue, recordedCtx.Value(testContextKey{}))
+	}
+}
+
 func TestReconcile(t *testing.T) {
 	t.Run(`create stateful set`, func(t *testing.T) {
 		r := createDefaultReconciler(t)
@@ -120,6 +166,166 @@ func TestReconcile(t *testing.T) {
 	})
 }
 
+func TestReconcile_LogContext(t *testing.T) {
+	var logLines []string
+	originalLog := log
+	log = funcr.New(func(_, args string) {
+		logLines = append(logLines, args)
+	}, funcr.Options{})
+	defer func() { log = originalLog }()
+
+	clt := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationFeatureActiveGateAuthToken: "false",
+			},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+					dynatracev1beta1.RoutingCapability.DisplayName,
+				}},
+		},
+	}
+	ctx := logger.NewCorrelationContext(context.TODO(), "dynakube", testName, "namespace", testNamespace)
+	r := NewReconciler(ctx, clt, clt, scheme.Scheme, record.NewFakeRecorder(10), instance, capability.NewRoutingCapability(instance))
+
+	require.NoError(t, r.Reconcile())
+	r.dynakube.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
+	require.NoError(t, r.Reconcile())
+
+	require.NotEmpty(t, logLines)
+	for _, logLine := range logLines {
+		assert.Contains(t, logLine, `"dynakube"="`+testName+`"`)
+		assert.Contains(t, logLine, `"namespace"="`+testNamespace+`"`)
+		assert.Contains(t, logLine, `"capability"="routing"`)
+		assert.Equal(t, 1, strings.Count(logLine, `"namespace"=`))
+	}
+}
+
+func TestReconcile_PinnedImageDigest(t *testing.T) {
+	const (
+		pinnedImage  = "registry.example.com/linux/activegate@sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+		changedImage = "registry.example.com/linux/activegate@sha256:3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"
+	)
+
+	r := createDefaultReconciler(t)
+	r.dynakube.Spec.ActiveGate.Image = pinnedImage
+	r.dynakube.Status.ActiveGate.ImageHash = "old-hash"
+	r.dynakube.Status.ActiveGate.Version = "1.0.0"
+
+	err := r.Reconcile()
+	require.NoError(t, err)
+	statefulSet := getReconciledStatefulSet(t, r)
+	assert.Equal(t, pinnedImage, statefulSet.Spec.Template.Spec.Containers[0].Image)
+
+	t.Run("same digest keeps the pod template and its hash", func(t *testing.T) {
+		r.dynakube.Status.ActiveGate.ImageHash = "new-hash"
+		r.dynakube.Status.ActiveGate.Version = "1.0.1"
+
+		err := r.Reconcile()
+		require.NoError(t, err)
+
+		reconciledStatefulSet := getReconciledStatefulSet(t, r)
+		assert.Equal(t, statefulSet.Spec.Template, reconciledStatefulSet.Spec.Template)
+		assert.Equal(t, statefulSet.Annotations[kubeobjects.AnnotationHash], reconciledStatefulSet.Annotations[kubeobjects.AnnotationHash])
+		assert.Equal(t, statefulSet.ResourceVersion, reconciledStatefulSet.ResourceVersion)
+	})
+	t.Run("changed digest rolls the pod template", func(t *testing.T) {
+		r.dynakube.Spec.ActiveGate.Image = changedImage
+
+		err := r.Reconcile()
+		require.NoError(t, err)
+
+		reconciledStatefulSet := getReconciledStatefulSet(t, r)
+		assert.Equal(t, changedImage, reconciledStatefulSet.Spec.Template.Spec.Containers[0].Image)
+		assert.NotEqual(t, statefulSet.Annotations[kubeobjects.AnnotationHash], reconciledStatefulSet.Annotations[kubeobjects.AnnotationHash])
+	})
+}
+
+func TestReconcile_RestartAnnotation(t *testing.T) {
+	r := createDefaultReconciler(t)
+	err := r.Reconcile()
+	require.NoError(t, err)
+
+	r.dynakube.Annotations[consts.AnnotationActiveGateRestart] = "2022-11-16T10:00:00Z"
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	sts, err := r.getStatefulSet(desiredSts)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-11-16T10:00:00Z", sts.Spec.Template.Annotations[consts.AnnotationActiveGateRestart])
+}
+
+func TestReconcile_PullSecretRotation(t *testing.T) {
+	t.Run("no annotation without pull secret", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+
+		assert.NotContains(t, desiredSts.Spec.Template.Annotations, consts.AnnotationPullSecretHash)
+	})
+	t.Run("rotated pull secret rolls the pods", func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		pullSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.dynakube.PullSecret(),
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{".dockerconfigjson": []byte(testValue)},
+		}
+		require.NoError(t, r.client.Create(context.TODO(), pullSecret))
+		require.NoError(t, r.Reconcile())
+
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+		oldHash := desiredSts.Spec.Template.Annotations[consts.AnnotationPullSecretHash]
+		assert.NotEmpty(t, oldHash)
+
+		updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+		require.NoError(t, err)
+		assert.False(t, updated)
+
+		pullSecret.Data[".dockerconfigjson"] = []byte(testToken)
+		require.NoError(t, r.client.Update(context.TODO(), pullSecret))
+
+		desiredSts, err = r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+		updated, err = r.updateStatefulSetIfOutdated(desiredSts)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.NotEqual(t, oldHash, sts.Spec.Template.Annotations[consts.AnnotationPullSecretHash])
+	})
+}
+
+func TestReconcile_MissingKubeSystemUID(t *testing.T) {
+	const lastKnownUID = "last-known-uid"
+
+	r := createDefaultReconciler(t)
+	err := r.client.Delete(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: kubesystem.Namespace}})
+	require.NoError(t, err)
+	r.dynakube.Status.KubeSystemUUID = lastKnownUID
+
+	err = r.Reconcile()
+	require.NoError(t, err)
+
+	statefulSet := &appsv1.StatefulSet{}
+	err = r.client.Get(context.TODO(), client.ObjectKey{Name: r.dynakube.Name + "-" + r.capability.ShortName(), Namespace: r.dynakube.Namespace}, statefulSet)
+	require.NoError(t, err)
+	assert.Contains(t, statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: consts.EnvDtIdSeedClusterId, Value: lastKnownUID})
+}
+
 func TestReconcile_GetStatefulSet(t *testing.T) {
 	r := createDefaultReconciler(t)
 	err := r.Reconcile()
@@ -140,6 +346,35 @@ func TestReconcile_GetStatefulSet(t *testing.T) {
 	assert.Equal(t, *desiredSts, *sts)
 }
 
+func TestReconcile_LongDynakubeName(t *testing.T) {
+	r := createDefaultReconciler(t)
+	r.dynakube.Name = "dynakube-with-a-very-long-name-that-exceeds-the-kubernetes-limits"
+	require.Greater(t, len(r.dynakube.Name), 60)
+	err := r.client.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.dynakube.ActiveGateAuthTokenSecret(),
+			Namespace: testNamespace,
+		},
+		Data: map[string][]byte{authtoken.ActiveGateAuthTokenName: []byte(testToken)},
+	})
+	require.NoError(t, err)
+
+	err = r.Reconcile()
+	require.NoError(t, err)
+	err = r.Reconcile()
+	require.NoError(t, err)
+
+	var statefulSets appsv1.StatefulSetList
+	err = r.client.List(context.TODO(), &statefulSets, client.InNamespace(testNamespace))
+	require.NoError(t, err)
+	require.Len(t, statefulSets.Items, 1)
+
+	name := statefulSets.Items[0].Name
+	assert.Equal(t, capability.CalculateStatefulSetName(r.capability, r.dynakube.Name), name)
+	assert.LessOrEqual(t, len(name), 52)
+	assert.Empty(t, validation.IsDNS1123Label(name))
+}
+
 func TestReconcile_CreateStatefulSetIfNotExists(t *testing.T) {
 	r := createDefaultReconciler(t)
 	desiredSts, err := r.buildDesiredStatefulSet()
@@ -155,6 +390,161 @@ func TestReconcile_CreateStatefulSetIfNotExists(t *testing.T) {
 	assert.False(t, created)
 }
 
+// staleCacheClient doesn't find any StatefulSet, like a cache that didn't receive a newly created one yet
+type staleCacheClient struct {
+	client.Client
+	creates int
+}
+
+func (clt *staleCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, isStatefulSet := obj.(*appsv1.StatefulSet); isStatefulSet {
+		return k8serrors.NewNotFound(appsv1.Resource("statefulsets"), key.Name)
+	}
+	return clt.Client.Get(ctx, key, obj, opts...)
+}
+
+func (clt *staleCacheClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	clt.creates++
+	return clt.Client.Create(ctx, obj, opts...)
+}
+
+func TestReconcile_CreateStatefulSetCacheMiss(t *testing.T) {
+	t.Run(`stateful set known to the api server is not created again`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		require.NoError(t, r.Reconcile())
+		staleClient := &staleCacheClient{Client: r.client}
+		r = NewReconciler(context.TODO(), staleClient, r.client, r.scheme, r.recorder, r.dynakube, r.capability)
+
+		err := r.Reconcile()
+
+		require.NoError(t, err)
+		assert.Zero(t, staleClient.creates)
+	})
+	t.Run(`concurrently created stateful set is not an error`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+		require.NoError(t, r.client.Create(context.TODO(), desiredSts.DeepCopy()))
+		staleClient := &staleCacheClient{Client: r.client}
+		r = NewReconciler(context.TODO(), staleClient, staleClient, r.scheme, r.recorder, r.dynakube, r.capability)
+
+		created, err := r.createStatefulSetIfNotExists(desiredSts)
+
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, 1, staleClient.creates)
+	})
+}
+
+func TestReconcile_AdoptStatefulSetIfUnowned(t *testing.T) {
+	t.Run(`unowned stateful set with matching labels is adopted`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+
+		// simulate a stateful set left behind by a previous helm install
+		require.NoError(t, r.client.Create(context.TODO(), desiredSts.DeepCopy()))
+
+		err = r.Reconcile()
+		require.NoError(t, err)
+
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.True(t, metav1.IsControlledBy(sts, r.dynakube))
+	})
+	t.Run(`unowned stateful set with different labels is not adopted`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+
+		foreignSts := desiredSts.DeepCopy()
+		foreignSts.Spec.Selector.MatchLabels = map[string]string{"app": "activegate"}
+		require.NoError(t, r.client.Create(context.TODO(), foreignSts))
+
+		err = r.Reconcile()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not managed by the operator")
+
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.Nil(t, metav1.GetControllerOf(sts))
+		assert.Equal(t, foreignSts.Spec.Selector.MatchLabels, sts.Spec.Selector.MatchLabels)
+	})
+	t.Run(`stateful set controlled by someone else is not adopted`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+
+		isController := true
+		foreignSts := desiredSts.DeepCopy()
+		foreignSts.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       testName,
+				UID:        testUID,
+				Controller: &isController,
+			},
+		}
+		require.NoError(t, r.client.Create(context.TODO(), foreignSts))
+
+		err = r.Reconcile()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "controlled by Deployment "+testName)
+
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.False(t, metav1.IsControlledBy(sts, r.dynakube))
+	})
+	t.Run(`cleared owner references are restored`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		require.NoError(t, r.Reconcile())
+
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		sts.OwnerReferences = nil
+		require.NoError(t, r.client.Update(context.TODO(), sts))
+
+		require.NoError(t, r.Reconcile())
+
+		sts, err = r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.True(t, metav1.IsControlledBy(sts, r.dynakube))
+	})
+	t.Run(`owner reference of a previous dynakube with the same name is replaced`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		require.NoError(t, r.Reconcile())
+
+		// simulate a restore from backup, which recreates the dynakube with a new UID
+		r.dynakube.UID = "restored-uid"
+		require.NoError(t, r.Reconcile())
+
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.True(t, metav1.IsControlledBy(sts, r.dynakube))
+		assert.Len(t, sts.OwnerReferences, 1)
+	})
+	t.Run(`owned stateful set is left as is`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		require.NoError(t, r.Reconcile())
+
+		desiredSts, err := r.buildDesiredStatefulSet()
+		require.NoError(t, err)
+		sts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+
+		require.NoError(t, r.adoptStatefulSetIfUnowned(desiredSts))
+
+		adoptedSts, err := r.getStatefulSet(desiredSts)
+		require.NoError(t, err)
+		assert.Equal(t, sts.ResourceVersion, adoptedSts.ResourceVersion)
+	})
+}
+
 func TestReconcile_UpdateStatefulSetIfOutdated(t *testing.T) {
 	r := createDefaultReconciler(t)
 	desiredSts, err := r.buildDesiredStatefulSet()
@@ -183,6 +573,65 @@ func TestReconcile_UpdateStatefulSetIfOutdated(t *testing.T) {
 	assert.True(t, updated)
 }
 
+func TestReconcile_ForceResync(t *testing.T) {
+	r := createDefaultReconciler(t)
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	created, err := r.createStatefulSetIfNotExists(desiredSts)
+	require.True(t, created)
+	require.NoError(t, err)
+
+	updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	r.dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationForceResync: "true"}
+	updated, err = r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	r.dynakube.Annotations[dynatracev1beta1.AnnotationForceResync] = "false"
+	updated, err = r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.False(t, updated)
+}
+
+func TestReconcile_InjectedSidecar(t *testing.T) {
+	r := createDefaultReconciler(t)
+	err := r.Reconcile()
+	require.NoError(t, err)
+
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+	sts, err := r.getStatefulSet(desiredSts)
+	require.NoError(t, err)
+
+	// simulate a service mesh webhook injecting its sidecar
+	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, corev1.Container{Name: "istio-proxy"})
+	require.NoError(t, r.client.Update(context.TODO(), sts))
+
+	updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	r.dynakube.Spec.Routing.Labels = map[string]string{testName: testValue}
+	desiredSts, err = r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	updated, err = r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	sts, err = r.getStatefulSet(desiredSts)
+	require.NoError(t, err)
+	assert.True(t, containsContainer(sts.Spec.Template.Spec.Containers, "istio-proxy"))
+
+	updated, err = r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.False(t, updated)
+}
+
 func TestReconcile_DeleteStatefulSetIfOldLabelsAreUsed(t *testing.T) {
 	r := createDefaultReconciler(t)
 	desiredSts, err := r.buildDesiredStatefulSet()
@@ -216,6 +665,57 @@ func TestReconcile_DeleteStatefulSetIfOldLabelsAreUsed(t *testing.T) {
 	assert.True(t, deleted)
 }
 
+func TestReconcile_UserLabelsUpdateStatefulSet(t *testing.T) {
+	r := createDefaultReconciler(t)
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	created, err := r.createStatefulSetIfNotExists(desiredSts)
+	require.True(t, created)
+	require.NoError(t, err)
+
+	r.dynakube.Spec.Routing.Labels = map[string]string{"cost-center": "test"}
+	desiredSts, err = r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	deleted, err := r.deleteStatefulSetIfOldLabelsAreUsed(desiredSts)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	sts, err := r.getStatefulSet(desiredSts)
+	require.NoError(t, err)
+	assert.Equal(t, "test", sts.Labels["cost-center"])
+	assert.Equal(t, "test", sts.Spec.Template.Labels["cost-center"])
+}
+
+func TestReconcile_UpdateStrategyUpdatesStatefulSet(t *testing.T) {
+	r := createDefaultReconciler(t)
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	created, err := r.createStatefulSetIfNotExists(desiredSts)
+	require.True(t, created)
+	require.NoError(t, err)
+
+	r.dynakube.Spec.Routing.UpdateStrategy = &appsv1.StatefulSetUpdateStrategy{
+		Type: appsv1.OnDeleteStatefulSetStrategyType,
+	}
+	desiredSts, err = r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	sts, err := r.getStatefulSet(desiredSts)
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.OnDeleteStatefulSetStrategyType, sts.Spec.UpdateStrategy.Type)
+}
+
 func TestReconcile_GetCustomPropertyHash(t *testing.T) {
 	r := createDefaultReconciler(t)
 	hash, err := r.calculateActiveGateConfigurationHash()
@@ -272,3 +772,81 @@ func TestReconcile_GetActiveGateAuthTokenHash(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, hash)
 }
+
+func TestReconcile_GetTrustedCAsHash(t *testing.T) {
+	r := createDefaultReconciler(t)
+	hash, err := r.calculateActiveGateConfigurationHash()
+	require.NoError(t, err)
+
+	r.dynakube.Spec.TrustedCAs = testName
+	_, err = r.calculateActiveGateConfigurationHash()
+	assert.Error(t, err)
+
+	err = r.client.Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+		},
+		Data: map[string]string{
+			dynatracev1beta1.TrustedCAKey: testValue,
+		},
+	})
+	require.NoError(t, err)
+
+	trustedCAsHash, err := r.calculateActiveGateConfigurationHash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, trustedCAsHash)
+}
+
+func TestReconcile_RecreateStatefulSetSendsEvent(t *testing.T) {
+	r := createDefaultReconciler(t)
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+
+	created, err := r.createStatefulSetIfNotExists(desiredSts)
+	require.True(t, created)
+	require.NoError(t, err)
+
+	desiredSts, err = r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+	desiredSts.Spec.Selector.MatchLabels = map[string]string{"changed": "selector"}
+	desiredSts.Annotations[kubeobjects.AnnotationHash] = "changed"
+
+	updated, err := r.updateStatefulSetIfOutdated(desiredSts)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, statefulSetRecreatedEvent)
+}
+
+func TestReconcile_VolumeClaimTemplateChangeRecreatesStatefulSet(t *testing.T) {
+	r := createAutoscalingReconciler(t, nil)
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+	r.dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate = &corev1.PersistentVolumeClaimSpec{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+		},
+	}
+	require.NoError(t, r.Reconcile())
+
+	desiredSts, err := r.buildDesiredStatefulSet()
+	require.NoError(t, err)
+	var sts appsv1.StatefulSet
+	require.NoError(t, r.client.Get(context.TODO(), kubeobjects.Key(desiredSts), &sts))
+	require.Len(t, sts.Spec.VolumeClaimTemplates, 1)
+	assert.NotEmpty(t, sts.Annotations[consts.AnnotationVolumeClaimTemplateHash])
+	assert.Empty(t, recorder.Events)
+
+	r.dynakube.Spec.KubernetesMonitoring.VolumeClaimTemplate.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+	require.NoError(t, r.Reconcile())
+
+	require.NoError(t, r.client.Get(context.TODO(), kubeobjects.Key(desiredSts), &sts))
+	storage := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.True(t, storage.Equal(resource.MustParse("2Gi")))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "volume claim templates changed")
+}
@@ -1,6 +1,8 @@
 package statefulset
 
 import (
+	"strings"
+
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
@@ -22,6 +24,11 @@ type StatefulSetBuilder struct {
 	configHash string
 	dynakube   dynatracev1beta1.DynaKube
 	capability capability.Capability
+	replicas   *int32
+
+	pullSecretHash string
+	image          string
+	imageVersion   string
 }
 
 func NewStatefulSetBuilder(kubeUID types.UID, configHash string, dynakube dynatracev1beta1.DynaKube, capability capability.Capability) StatefulSetBuilder {
@@ -33,12 +40,40 @@ func NewStatefulSetBuilder(kubeUID types.UID, configHash string, dynakube dynatr
 	}
 }
 
+// WithReplicas overrides the replicas of the capability, nil keeps the configured value
+func (statefulSetBuilder StatefulSetBuilder) WithReplicas(replicas *int32) StatefulSetBuilder {
+	statefulSetBuilder.replicas = replicas
+	return statefulSetBuilder
+}
+
+// WithPullSecretHash records the hash of the pull secret contents on the pod template, empty omits the annotation
+func (statefulSetBuilder StatefulSetBuilder) WithPullSecretHash(pullSecretHash string) StatefulSetBuilder {
+	statefulSetBuilder.pullSecretHash = pullSecretHash
+	return statefulSetBuilder
+}
+
+// WithImage overrides the image of the ActiveGate container and the version it is labeled with, empty values keep the ones of the DynaKube
+func (statefulSetBuilder StatefulSetBuilder) WithImage(image, imageVersion string) StatefulSetBuilder {
+	statefulSetBuilder.image = image
+	statefulSetBuilder.imageVersion = imageVersion
+	return statefulSetBuilder
+}
+
 func (statefulSetBuilder StatefulSetBuilder) CreateStatefulSet(mods []builder.Modifier) (*appsv1.StatefulSet, error) {
 	activeGateBuilder := builder.NewBuilder(statefulSetBuilder.getBase())
 	if len(mods) == 0 {
 		mods = modifiers.GenerateAllModifiers(statefulSetBuilder.dynakube, statefulSetBuilder.capability)
 	}
 	sts := activeGateBuilder.AddModifier(mods...).Build()
+	statefulSetBuilder.overrideImage(&sts)
+
+	if err := validateCustomVolumes(&sts, statefulSetBuilder.dynakube.Spec.KubernetesMonitoring); err != nil {
+		return nil, err
+	}
+
+	if err := setVolumeClaimTemplateHash(&sts); err != nil {
+		return nil, err
+	}
 
 	if err := setHash(&sts); err != nil {
 		return nil, err
@@ -47,6 +82,22 @@ func (statefulSetBuilder StatefulSetBuilder) CreateStatefulSet(mods []builder.Mo
 	return &sts, nil
 }
 
+// overrideImage replaces the ActiveGate image of the DynaKube in all containers which run it, e.g. the init container of the kubemon capability
+func (statefulSetBuilder StatefulSetBuilder) overrideImage(sts *appsv1.StatefulSet) {
+	if statefulSetBuilder.image == "" {
+		return
+	}
+
+	activeGateImage := statefulSetBuilder.dynakube.ActiveGateImage()
+	for _, containers := range [][]corev1.Container{sts.Spec.Template.Spec.InitContainers, sts.Spec.Template.Spec.Containers} {
+		for i := range containers {
+			if containers[i].Image == activeGateImage {
+				containers[i].Image = statefulSetBuilder.image
+			}
+		}
+	}
+}
+
 func (statefulSetBuilder StatefulSetBuilder) getBase() appsv1.StatefulSet {
 	var sts appsv1.StatefulSet
 	sts.ObjectMeta = statefulSetBuilder.getBaseObjectMeta()
@@ -58,12 +109,26 @@ func (statefulSetBuilder StatefulSetBuilder) getBase() appsv1.StatefulSet {
 	if statefulSetBuilder.dynakube.FeatureActiveGateAppArmor() {
 		sts.Spec.Template.ObjectMeta.Annotations[consts.AnnotationActiveGateContainerAppArmor] = "runtime/default"
 	}
+	statefulSetBuilder.addRestartAnnotation(&sts)
+	if statefulSetBuilder.pullSecretHash != "" {
+		sts.Spec.Template.ObjectMeta.Annotations[consts.AnnotationPullSecretHash] = statefulSetBuilder.pullSecretHash
+	}
 	return sts
 }
 
+// addRestartAnnotation copies the restart trigger of the DynaKube onto the pod template,
+// so a changed value results in a different template hash and rolls the pods
+func (statefulSetBuilder StatefulSetBuilder) addRestartAnnotation(sts *appsv1.StatefulSet) {
+	restartTrigger, ok := statefulSetBuilder.dynakube.Annotations[consts.AnnotationActiveGateRestart]
+	if !ok {
+		return
+	}
+	sts.Spec.Template.ObjectMeta.Annotations[consts.AnnotationActiveGateRestart] = restartTrigger
+}
+
 func (statefulSetBuilder StatefulSetBuilder) getBaseObjectMeta() metav1.ObjectMeta {
 	return metav1.ObjectMeta{
-		Name:        statefulSetBuilder.dynakube.Name + "-" + statefulSetBuilder.capability.ShortName(),
+		Name:        capability.CalculateStatefulSetName(statefulSetBuilder.capability, statefulSetBuilder.dynakube.Name),
 		Namespace:   statefulSetBuilder.dynakube.Namespace,
 		Annotations: map[string]string{},
 	}
@@ -71,8 +136,9 @@ func (statefulSetBuilder StatefulSetBuilder) getBaseObjectMeta() metav1.ObjectMe
 
 func (statefulSetBuilder StatefulSetBuilder) getBaseSpec() appsv1.StatefulSetSpec {
 	return appsv1.StatefulSetSpec{
-		Replicas:            statefulSetBuilder.capability.Properties().Replicas,
+		Replicas:            statefulSetBuilder.getReplicas(),
 		PodManagementPolicy: appsv1.ParallelPodManagement,
+		UpdateStrategy:      statefulSetBuilder.getUpdateStrategy(),
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
 				Annotations: map[string]string{
@@ -83,40 +149,169 @@ func (statefulSetBuilder StatefulSetBuilder) getBaseSpec() appsv1.StatefulSetSpe
 	}
 }
 
-func (statefulSetBuilder StatefulSetBuilder) addLabels(sts *appsv1.StatefulSet) {
+func (statefulSetBuilder StatefulSetBuilder) getReplicas() *int32 {
+	if statefulSetBuilder.replicas != nil {
+		return statefulSetBuilder.replicas
+	}
+	return statefulSetBuilder.capability.Properties().Replicas
+}
+
+func (statefulSetBuilder StatefulSetBuilder) getUpdateStrategy() appsv1.StatefulSetUpdateStrategy {
+	updateStrategy := statefulSetBuilder.capability.Properties().UpdateStrategy
+	if updateStrategy == nil {
+		return appsv1.StatefulSetUpdateStrategy{
+			Type: appsv1.RollingUpdateStatefulSetStrategyType,
+		}
+	}
+	return *updateStrategy.DeepCopy()
+}
+
+func (statefulSetBuilder StatefulSetBuilder) appLabels() *kubeobjects.AppLabels {
 	versionLabelValue := statefulSetBuilder.dynakube.Status.ActiveGate.Version
+	if statefulSetBuilder.imageVersion != "" {
+		versionLabelValue = statefulSetBuilder.imageVersion
+	}
 	if statefulSetBuilder.dynakube.CustomActiveGateImage() != "" {
 		versionLabelValue = kubeobjects.CustomImageLabelValue
 	}
-	appLabels := kubeobjects.NewAppLabels(kubeobjects.ActiveGateComponentLabel, statefulSetBuilder.dynakube.Name, statefulSetBuilder.capability.ShortName(), versionLabelValue)
+	return kubeobjects.NewAppLabels(kubeobjects.ActiveGateComponentLabel, statefulSetBuilder.dynakube.Name, statefulSetBuilder.capability.ShortName(), versionLabelValue)
+}
+
+func (statefulSetBuilder StatefulSetBuilder) addLabels(sts *appsv1.StatefulSet) {
+	appLabels := statefulSetBuilder.appLabels()
 
-	sts.ObjectMeta.Labels = appLabels.BuildLabels()
+	sts.ObjectMeta.Labels = kubeobjects.MergeMap(statefulSetBuilder.capability.Properties().Labels, appLabels.BuildLabels())
 	sts.Spec.Selector = &metav1.LabelSelector{MatchLabels: appLabels.BuildMatchLabels()}
 	sts.Spec.Template.ObjectMeta.Labels = kubeobjects.MergeMap(statefulSetBuilder.capability.Properties().Labels, appLabels.BuildLabels())
 }
 
+// addUserAnnotations merges the user defined annotations into the StatefulSet and its pod template.
+// Annotations set by the operator take precedence and user annotations using the internal prefix are dropped.
 func (statefulSetBuilder StatefulSetBuilder) addUserAnnotations(sts *appsv1.StatefulSet) {
-	sts.ObjectMeta.Annotations = kubeobjects.MergeMap(sts.ObjectMeta.Annotations, statefulSetBuilder.dynakube.Spec.ActiveGate.Annotations)
-	sts.Spec.Template.ObjectMeta.Annotations = kubeobjects.MergeMap(sts.Spec.Template.ObjectMeta.Annotations, statefulSetBuilder.dynakube.Spec.ActiveGate.Annotations)
+	userAnnotations := withoutInternalFlags(statefulSetBuilder.capability.Properties().Annotations)
+	sts.ObjectMeta.Annotations = kubeobjects.MergeMap(userAnnotations, sts.ObjectMeta.Annotations)
+	sts.Spec.Template.ObjectMeta.Annotations = kubeobjects.MergeMap(userAnnotations, sts.Spec.Template.ObjectMeta.Annotations)
+}
+
+func withoutInternalFlags(annotations map[string]string) map[string]string {
+	filtered := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, dynatracev1beta1.InternalFlagPrefix) {
+			filtered[key] = value
+		}
+	}
+	return filtered
 }
 
 func (statefulSetBuilder StatefulSetBuilder) addTemplateSpec(sts *appsv1.StatefulSet) {
 	podSpec := corev1.PodSpec{
 		Containers:         statefulSetBuilder.buildBaseContainer(),
 		NodeSelector:       statefulSetBuilder.capability.Properties().NodeSelector,
-		ServiceAccountName: statefulSetBuilder.dynakube.ActiveGateServiceAccountName(),
-		Affinity:           nodeAffinity(),
+		ServiceAccountName: statefulSetBuilder.serviceAccountName(),
+		Affinity:           statefulSetBuilder.affinity(),
 		Tolerations:        buildTolerations(statefulSetBuilder.capability),
 		ImagePullSecrets: []corev1.LocalObjectReference{
 			{Name: statefulSetBuilder.dynakube.PullSecret()},
 		},
-		PriorityClassName:         statefulSetBuilder.dynakube.Spec.ActiveGate.PriorityClassName,
-		DNSPolicy:                 statefulSetBuilder.dynakube.Spec.ActiveGate.DNSPolicy,
-		TopologySpreadConstraints: statefulSetBuilder.capability.Properties().TopologySpreadConstraints,
+		PriorityClassName:             statefulSetBuilder.priorityClassName(),
+		DNSPolicy:                     statefulSetBuilder.dynakube.Spec.ActiveGate.DNSPolicy,
+		TopologySpreadConstraints:     statefulSetBuilder.topologySpreadConstraints(),
+		TerminationGracePeriodSeconds: statefulSetBuilder.terminationGracePeriodSeconds(),
 	}
 	sts.Spec.Template.Spec = podSpec
 }
 
+func (statefulSetBuilder StatefulSetBuilder) priorityClassName() string {
+	_, isKubeMon := statefulSetBuilder.capability.(*capability.KubeMonCapability)
+	if isKubeMon && statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.PriorityClassName != "" {
+		return statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.PriorityClassName
+	}
+	return statefulSetBuilder.dynakube.Spec.ActiveGate.PriorityClassName
+}
+
+func (statefulSetBuilder StatefulSetBuilder) serviceAccountName() string {
+	_, isKubeMon := statefulSetBuilder.capability.(*capability.KubeMonCapability)
+	if isKubeMon && statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.ServiceAccountName != "" {
+		return statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.ServiceAccountName
+	}
+	return statefulSetBuilder.dynakube.ActiveGateServiceAccountName()
+}
+
+// topologySpreadConstraints returns nil instead of an empty list, so an explicitly empty list doesn't change the hash of the StatefulSet
+func (statefulSetBuilder StatefulSetBuilder) topologySpreadConstraints() []corev1.TopologySpreadConstraint {
+	constraints := statefulSetBuilder.capability.Properties().TopologySpreadConstraints
+	if len(constraints) == 0 {
+		return nil
+	}
+	return constraints
+}
+
+// terminationGracePeriodSeconds returns the grace period of the Kubernetes monitoring spec for the kubemon capability,
+// nil keeps the default of Kubernetes
+func (statefulSetBuilder StatefulSetBuilder) terminationGracePeriodSeconds() *int64 {
+	_, isKubeMon := statefulSetBuilder.capability.(*capability.KubeMonCapability)
+	if !isKubeMon || statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.TerminationGracePeriodSeconds == nil {
+		return nil
+	}
+	return address.Of(*statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.TerminationGracePeriodSeconds)
+}
+
+// lifecycle returns the preStop hook of the Kubernetes monitoring spec for the kubemon capability, nil if there is none
+func (statefulSetBuilder StatefulSetBuilder) lifecycle() *corev1.Lifecycle {
+	_, isKubeMon := statefulSetBuilder.capability.(*capability.KubeMonCapability)
+	if !isKubeMon || statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.PreStop == nil {
+		return nil
+	}
+	return &corev1.Lifecycle{PreStop: statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.PreStop.DeepCopy()}
+}
+
+// affinity returns the affinity of the Kubernetes monitoring spec for the kubemon capability,
+// falling back to the default node affinity if the user didn't define one.
+// Without user defined affinity, multiple kubemon replicas are spread across nodes and zones where possible.
+func (statefulSetBuilder StatefulSetBuilder) affinity() *corev1.Affinity {
+	_, isKubeMon := statefulSetBuilder.capability.(*capability.KubeMonCapability)
+	if !isKubeMon {
+		return statefulSetBuilder.defaultAffinity()
+	}
+
+	if statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.Affinity == nil {
+		affinity := statefulSetBuilder.defaultAffinity()
+		if replicas := statefulSetBuilder.getReplicas(); replicas != nil && *replicas > 1 {
+			affinity.PodAntiAffinity = statefulSetBuilder.podAntiAffinity()
+		}
+		return affinity
+	}
+
+	affinity := statefulSetBuilder.dynakube.Spec.KubernetesMonitoring.Affinity.DeepCopy()
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = statefulSetBuilder.defaultAffinity().NodeAffinity
+	}
+	return affinity
+}
+
+// podAntiAffinity prefers to schedule the pods of the StatefulSet on different nodes, and as a weaker preference in different zones
+func (statefulSetBuilder StatefulSetBuilder) podAntiAffinity() *corev1.PodAntiAffinity {
+	matchLabels := statefulSetBuilder.appLabels().BuildMatchLabels()
+	return &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+			{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+					TopologyKey:   corev1.LabelHostname,
+				},
+			},
+			{
+				Weight: 50,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+					TopologyKey:   corev1.LabelTopologyZone,
+				},
+			},
+		},
+	}
+}
+
 func buildTolerations(capability capability.Capability) []corev1.Toleration {
 	tolerations := append(capability.Properties().Tolerations, kubeobjects.TolerationForAmd()...)
 	return tolerations
@@ -129,6 +324,7 @@ func (statefulSetBuilder StatefulSetBuilder) buildBaseContainer() []corev1.Conta
 		Resources:       statefulSetBuilder.capability.Properties().Resources,
 		Env:             statefulSetBuilder.buildCommonEnvs(),
 		ImagePullPolicy: corev1.PullAlways,
+		Lifecycle:       statefulSetBuilder.lifecycle(),
 		ReadinessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
@@ -168,7 +364,6 @@ func (statefulSetBuilder StatefulSetBuilder) buildCommonEnvs() []corev1.EnvVar {
 		{Name: consts.EnvDtIdSeedClusterId, Value: string(statefulSetBuilder.kubeUID)},
 		{Name: consts.EnvDtDeploymentMetadata, Value: deploymentMetadata.AsString()},
 	}
-	envs = append(envs, statefulSetBuilder.capability.Properties().Env...)
 
 	if statefulSetBuilder.capability.Properties().Group != "" {
 		envs = append(envs, corev1.EnvVar{Name: consts.EnvDtGroup, Value: statefulSetBuilder.capability.Properties().Group})
@@ -179,13 +374,47 @@ func (statefulSetBuilder StatefulSetBuilder) buildCommonEnvs() []corev1.EnvVar {
 	return envs
 }
 
+// arm64Architecture is the key of arm64 images in the architecture hashes of a multi-arch image
+const arm64Architecture = "arm64"
+
+// defaultAffinity limits the pods to amd64 nodes, unless all images of the pod are multi-arch images that support arm64 as well
+func (statefulSetBuilder StatefulSetBuilder) defaultAffinity() *corev1.Affinity {
+	if statefulSetBuilder.imagesSupportArm64() {
+		return nodeAffinityWithArm64()
+	}
+	return nodeAffinity()
+}
+
+func (statefulSetBuilder StatefulSetBuilder) imagesSupportArm64() bool {
+	status := statefulSetBuilder.dynakube.Status
+	versionStatuses := []dynatracev1beta1.VersionStatus{status.ActiveGate.VersionStatus}
+	if statefulSetBuilder.dynakube.IsStatsdActiveGateEnabled() {
+		versionStatuses = append(versionStatuses, status.ExtensionController.VersionStatus, status.Statsd.VersionStatus)
+	}
+
+	for _, versionStatus := range versionStatuses {
+		if _, ok := versionStatus.ArchitectureHashes[arm64Architecture]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func nodeAffinity() *corev1.Affinity {
+	return affinityWithNodeRequirements(kubeobjects.AffinityNodeRequirement())
+}
+
+func nodeAffinityWithArm64() *corev1.Affinity {
+	return affinityWithNodeRequirements(kubeobjects.AffinityNodeRequirementWithARM64())
+}
+
+func affinityWithNodeRequirements(requirements []corev1.NodeSelectorRequirement) *corev1.Affinity {
 	return &corev1.Affinity{
 		NodeAffinity: &corev1.NodeAffinity{
 			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
 				NodeSelectorTerms: []corev1.NodeSelectorTerm{
 					{
-						MatchExpressions: kubeobjects.AffinityNodeRequirement(),
+						MatchExpressions: requirements,
 					},
 				},
 			},
@@ -201,3 +430,18 @@ func setHash(sts *appsv1.StatefulSet) error {
 	sts.ObjectMeta.Annotations[kubeobjects.AnnotationHash] = hash
 	return nil
 }
+
+// setVolumeClaimTemplateHash records the volume claim templates separately,
+// as a StatefulSet has to be recreated if they change
+func setVolumeClaimTemplateHash(sts *appsv1.StatefulSet) error {
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return nil
+	}
+
+	hash, err := kubeobjects.GenerateHash(sts.Spec.VolumeClaimTemplates)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sts.ObjectMeta.Annotations[consts.AnnotationVolumeClaimTemplateHash] = hash
+	return nil
+}
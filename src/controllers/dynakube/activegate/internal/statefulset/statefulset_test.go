@@ -1,17 +1,20 @@
 package statefulset
 
 import (
+	"strings"
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -66,6 +69,31 @@ func TestGetBaseObjectMeta(t *testing.T) {
 		assert.Contains(t, objectMeta.Name, multiCapability.ShortName())
 		assert.NotNil(t, objectMeta.Annotations)
 	})
+	t.Run("long dynakube names are truncated", func(t *testing.T) {
+		longDynakube := getTestDynakube()
+		longDynakube.Name = "dynakube-with-a-very-long-name-that-exceeds-the-kubernetes-limits"
+		multiCapability := capability.NewMultiCapability(&longDynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, longDynakube, multiCapability)
+
+		objectMeta := builder.getBaseObjectMeta()
+
+		assert.Len(t, objectMeta.Name, 52)
+		assert.True(t, strings.HasPrefix(objectMeta.Name, "dynakube-with-a-very-long-name-that-exceeds-"))
+		assert.Equal(t, objectMeta.Name, builder.getBaseObjectMeta().Name)
+
+		sts, err := builder.CreateStatefulSet(nil)
+		require.NoError(t, err)
+		for _, labels := range []map[string]string{sts.Labels, sts.Spec.Selector.MatchLabels, sts.Spec.Template.Labels} {
+			for _, value := range labels {
+				assert.Empty(t, validation.IsValidLabelValue(value))
+			}
+		}
+
+		otherDynakube := getTestDynakube()
+		otherDynakube.Name = longDynakube.Name + "-2"
+		otherBuilder := NewStatefulSetBuilder(testKubeUID, testConfigHash, otherDynakube, capability.NewMultiCapability(&otherDynakube))
+		assert.NotEqual(t, objectMeta.Name, otherBuilder.getBaseObjectMeta().Name)
+	})
 	t.Run("default annotations", func(t *testing.T) {
 		multiCapability := capability.NewMultiCapability(&dynakube)
 		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
@@ -91,6 +119,141 @@ func TestGetBaseObjectMeta(t *testing.T) {
 
 		require.NotEmpty(t, sts.Spec.Template.Labels)
 		assert.Equal(t, expectedTemplateAnnotations, sts.Spec.Template.Annotations)
+		assert.Equal(t, "test", sts.Annotations["test"])
+	})
+	t.Run("user annotations do not overwrite internal annotations", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.ActiveGate.Annotations = map[string]string{
+			consts.AnnotationActiveGateConfigurationHash: "overwritten",
+			kubeobjects.AnnotationHash:                   "overwritten",
+			dynatracev1beta1.InternalFlagPrefix + "test": "test",
+		}
+		multiCapability := capability.NewMultiCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		sts, _ := builder.CreateStatefulSet(nil)
+
+		assert.Equal(t, testConfigHash, sts.Spec.Template.Annotations[consts.AnnotationActiveGateConfigurationHash])
+		assert.NotEqual(t, "overwritten", sts.Annotations[kubeobjects.AnnotationHash])
+		assert.NotContains(t, sts.Annotations, dynatracev1beta1.InternalFlagPrefix+"test")
+		assert.NotContains(t, sts.Spec.Template.Annotations, dynatracev1beta1.InternalFlagPrefix+"test")
+	})
+	t.Run("user annotations are part of the hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		multiCapability := capability.NewMultiCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		sts, _ := builder.CreateStatefulSet(nil)
+
+		dynakube.Spec.ActiveGate.Annotations = map[string]string{"test": "test"}
+		multiCapability = capability.NewMultiCapability(&dynakube)
+		builder = NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		annotatedSts, _ := builder.CreateStatefulSet(nil)
+
+		assert.True(t, kubeobjects.IsHashAnnotationDifferent(sts, annotatedSts))
+	})
+}
+
+func TestAddRestartAnnotation(t *testing.T) {
+	t.Run("no restart annotation by default", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		multiCapability := capability.NewMultiCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		sts, _ := builder.CreateStatefulSet(nil)
+
+		assert.NotContains(t, sts.Spec.Template.Annotations, consts.AnnotationActiveGateRestart)
+	})
+	t.Run("changed restart annotation rolls the pods", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Annotations[consts.AnnotationActiveGateRestart] = "2022-11-16T10:00:00Z"
+		multiCapability := capability.NewMultiCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		sts, _ := builder.CreateStatefulSet(nil)
+
+		assert.Equal(t, "2022-11-16T10:00:00Z", sts.Spec.Template.Annotations[consts.AnnotationActiveGateRestart])
+
+		dynakube.Annotations[consts.AnnotationActiveGateRestart] = "2022-11-16T11:00:00Z"
+		builder = NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		restartedSts, _ := builder.CreateStatefulSet(nil)
+
+		assert.Equal(t, "2022-11-16T11:00:00Z", restartedSts.Spec.Template.Annotations[consts.AnnotationActiveGateRestart])
+		assert.True(t, kubeobjects.IsHashAnnotationDifferent(sts, restartedSts))
+	})
+}
+
+func TestCreateStatefulSetHash(t *testing.T) {
+	t.Run("building the same instance twice results in the same hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.ActiveGate.Labels = map[string]string{"a": "a", "b": "b", "c": "c", "d": "d"}
+		dynakube.Spec.ActiveGate.Annotations = map[string]string{"e": "e", "f": "f", "g": "g", "h": "h"}
+		dynakube.Spec.ActiveGate.NodeSelector = map[string]string{"i": "i", "j": "j", "k": "k"}
+
+		firstSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+		secondSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, firstSts.Annotations[kubeobjects.AnnotationHash])
+		assert.Equal(t, firstSts.Annotations[kubeobjects.AnnotationHash], secondSts.Annotations[kubeobjects.AnnotationHash])
+	})
+	t.Run("custom envs and args change the hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		hashOf := func(dynakube dynatracev1beta1.DynaKube) string {
+			sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+			require.NoError(t, err)
+			return sts.Annotations[kubeobjects.AnnotationHash]
+		}
+		baseHash := hashOf(dynakube)
+
+		dynakube.Spec.KubernetesMonitoring.Args = []string{"--custom-flag"}
+		argsHash := hashOf(dynakube)
+		assert.NotEqual(t, baseHash, argsHash)
+
+		dynakube.Spec.KubernetesMonitoring.Env = []corev1.EnvVar{{Name: "CUSTOM_ENV", Value: "custom"}}
+		envHash := hashOf(dynakube)
+		assert.NotEqual(t, argsHash, envHash)
+
+		dynakube.Spec.KubernetesMonitoring.Env[0].Value = "changed"
+		assert.NotEqual(t, envHash, hashOf(dynakube))
+	})
+	t.Run("probe overrides change the hash, unset probes keep the defaults", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		baseSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+		baseContainer := baseSts.Spec.Template.Spec.Containers[0]
+		require.NotNil(t, baseContainer.ReadinessProbe)
+		assert.Equal(t, int32(90), baseContainer.ReadinessProbe.InitialDelaySeconds)
+		assert.Nil(t, baseContainer.LivenessProbe)
+
+		dynakube.Spec.KubernetesMonitoring.ReadinessProbe = &dynatracev1beta1.ProbeSettings{InitialDelaySeconds: address.Of(int32(300))}
+		readinessSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(300), readinessSts.Spec.Template.Spec.Containers[0].ReadinessProbe.InitialDelaySeconds)
+		assert.NotEqual(t, baseSts.Annotations[kubeobjects.AnnotationHash], readinessSts.Annotations[kubeobjects.AnnotationHash])
+
+		dynakube.Spec.KubernetesMonitoring.LivenessProbe = &dynatracev1beta1.ProbeSettings{}
+		livenessSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+		assert.NotNil(t, livenessSts.Spec.Template.Spec.Containers[0].LivenessProbe)
+		assert.NotEqual(t, readinessSts.Annotations[kubeobjects.AnnotationHash], livenessSts.Annotations[kubeobjects.AnnotationHash])
+	})
+	t.Run("custom envs don't override the operator managed ones", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.Env = []corev1.EnvVar{{Name: consts.EnvDtIdSeedNamespace, Value: "user-namespace"}}
+
+		sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		envs := sts.Spec.Template.Spec.Containers[0].Env
+		count := 0
+		for _, env := range envs {
+			if env.Name == consts.EnvDtIdSeedNamespace {
+				count++
+			}
+		}
+		assert.Equal(t, 1, count)
+		assert.Equal(t, dynakube.Namespace, kubeobjects.FindEnvVar(envs, consts.EnvDtIdSeedNamespace).Value)
 	})
 }
 
@@ -106,6 +269,23 @@ func TestGetBaseSpec(t *testing.T) {
 		assert.Equal(t, &testReplicas, stsSpec.Replicas)
 		require.NotNil(t, stsSpec.Template.Annotations)
 		assert.Equal(t, testConfigHash, stsSpec.Template.Annotations[consts.AnnotationActiveGateConfigurationHash])
+		assert.Equal(t, appsv1.RollingUpdateStatefulSetStrategyType, stsSpec.UpdateStrategy.Type)
+	})
+	t.Run("uses custom update strategy", func(t *testing.T) {
+		var partition int32 = 2
+		dynakube := getTestDynakube()
+		dynakube.Spec.ActiveGate.UpdateStrategy = &appsv1.StatefulSetUpdateStrategy{
+			Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+				Partition: &partition,
+			},
+		}
+		multiCapability := capability.NewMultiCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+
+		stsSpec := builder.getBaseSpec()
+
+		assert.Equal(t, *dynakube.Spec.ActiveGate.UpdateStrategy, stsSpec.UpdateStrategy)
 	})
 }
 
@@ -143,6 +323,23 @@ func TestAddLabels(t *testing.T) {
 
 		require.NotEmpty(t, sts.Spec.Template.Labels)
 		assert.Equal(t, expectedTemplateLabels, sts.Spec.Template.Labels)
+		assert.Equal(t, expectedTemplateLabels, sts.ObjectMeta.Labels)
+		assert.Equal(t, appLabels.BuildMatchLabels(), sts.Spec.Selector.MatchLabels)
+	})
+	t.Run("user labels do not overwrite app labels", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.ActiveGate.Labels = map[string]string{
+			kubeobjects.AppNameLabel: "test",
+		}
+		multiCapability := capability.NewMultiCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
+		sts := appsv1.StatefulSet{}
+		appLabels := kubeobjects.NewAppLabels(kubeobjects.ActiveGateComponentLabel, builder.dynakube.Name, builder.capability.ShortName(), testVersion)
+
+		builder.addLabels(&sts)
+
+		assert.Equal(t, appLabels.BuildLabels(), sts.ObjectMeta.Labels)
+		assert.Equal(t, appLabels.BuildLabels(), sts.Spec.Template.Labels)
 	})
 	t.Run("use custom image", func(t *testing.T) {
 		dynakube := getTestDynakube()
@@ -251,6 +448,254 @@ func TestAddTemplateSpec(t *testing.T) {
 
 		assert.Equal(t, testPriorityClass, spec.PriorityClassName)
 	})
+	t.Run("set priorityClass for kubernetes monitoring", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.ActiveGate.PriorityClassName = "activegate"
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.PriorityClassName = "monitoring"
+		kubeMonCapability := capability.NewKubeMonCapability(&dynakube)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, kubeMonCapability)
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, "monitoring", sts.Spec.Template.Spec.PriorityClassName)
+
+		dynakube.Spec.KubernetesMonitoring.PriorityClassName = ""
+		builder = NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts = appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, "activegate", sts.Spec.Template.Spec.PriorityClassName)
+	})
+	t.Run("set serviceAccountName for kubernetes monitoring", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.ServiceAccountName = "custom-monitoring"
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, "custom-monitoring", sts.Spec.Template.Spec.ServiceAccountName)
+
+		dynakube.Spec.KubernetesMonitoring.ServiceAccountName = ""
+		builder = NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts = appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, dynakube.ActiveGateServiceAccountName(), sts.Spec.Template.Spec.ServiceAccountName)
+	})
+	t.Run("changing serviceAccountName changes the hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		dynakube.Spec.KubernetesMonitoring.ServiceAccountName = "custom-monitoring"
+		customSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, sts.Annotations[kubeobjects.AnnotationHash], customSts.Annotations[kubeobjects.AnnotationHash])
+	})
+	t.Run("set termination grace period and preStop hook for kubernetes monitoring", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.TerminationGracePeriodSeconds = address.Of(int64(120))
+		dynakube.Spec.KubernetesMonitoring.PreStop = &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "sleep 60"}},
+		}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		require.NotNil(t, sts.Spec.Template.Spec.TerminationGracePeriodSeconds)
+		assert.Equal(t, int64(120), *sts.Spec.Template.Spec.TerminationGracePeriodSeconds)
+		require.NotNil(t, sts.Spec.Template.Spec.Containers[0].Lifecycle)
+		assert.Equal(t, dynakube.Spec.KubernetesMonitoring.PreStop, sts.Spec.Template.Spec.Containers[0].Lifecycle.PreStop)
+	})
+	t.Run("termination grace period and preStop hook keep the defaults", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.TerminationGracePeriodSeconds = address.Of(int64(120))
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Nil(t, sts.Spec.Template.Spec.TerminationGracePeriodSeconds)
+		assert.Nil(t, sts.Spec.Template.Spec.Containers[0].Lifecycle)
+	})
+	t.Run("changing the termination grace period changes the hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		dynakube.Spec.KubernetesMonitoring.TerminationGracePeriodSeconds = address.Of(int64(120))
+		customSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, sts.Annotations[kubeobjects.AnnotationHash], customSts.Annotations[kubeobjects.AnnotationHash])
+	})
+	t.Run("set scheduling constraints for kubernetes monitoring", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.NodeSelector = map[string]string{"node-role.kubernetes.io/infra": ""}
+		dynakube.Spec.KubernetesMonitoring.Tolerations = []corev1.Toleration{
+			{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		}
+		dynakube.Spec.KubernetesMonitoring.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{Weight: 100, PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: "kubernetes.io/hostname"}},
+				},
+			},
+		}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+		spec := sts.Spec.Template.Spec
+
+		assert.Equal(t, dynakube.Spec.KubernetesMonitoring.NodeSelector, spec.NodeSelector)
+		assert.Contains(t, spec.Tolerations, dynakube.Spec.KubernetesMonitoring.Tolerations[0])
+		assert.Equal(t, dynakube.Spec.KubernetesMonitoring.Affinity.PodAntiAffinity, spec.Affinity.PodAntiAffinity)
+		assert.Equal(t, nodeAffinity().NodeAffinity, spec.Affinity.NodeAffinity)
+		assert.Nil(t, dynakube.Spec.KubernetesMonitoring.Affinity.NodeAffinity)
+	})
+	t.Run("custom node affinity for kubernetes monitoring replaces the default one", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		customNodeAffinity := &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/infra", Operator: corev1.NodeSelectorOpExists}}},
+				},
+			},
+		}
+		dynakube.Spec.KubernetesMonitoring.Affinity = &corev1.Affinity{NodeAffinity: customNodeAffinity}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, customNodeAffinity, sts.Spec.Template.Spec.Affinity.NodeAffinity)
+	})
+	t.Run("kubernetes monitoring affinity is not used by other capabilities", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, nodeAffinity(), sts.Spec.Template.Spec.Affinity)
+	})
+	t.Run("multi-arch images allow arm64 nodes", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Status.ActiveGate.ArchitectureHashes = map[string]string{"amd64": "amd64-hash", "arm64": "arm64-hash"}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, nodeAffinityWithArm64(), sts.Spec.Template.Spec.Affinity)
+	})
+	t.Run("amd64 only images keep the default node affinity", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Status.ActiveGate.ArchitectureHashes = map[string]string{"amd64": "amd64-hash"}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, nodeAffinity(), sts.Spec.Template.Spec.Affinity)
+	})
+	t.Run("kubernetes monitoring replicas are spread across nodes by default", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		replicas := int32(2)
+		dynakube.Spec.KubernetesMonitoring.Replicas = &replicas
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+		builder.addLabels(&sts)
+		affinity := sts.Spec.Template.Spec.Affinity
+
+		assert.Equal(t, nodeAffinity().NodeAffinity, affinity.NodeAffinity)
+		require.NotNil(t, affinity.PodAntiAffinity)
+		terms := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		require.Len(t, terms, 2)
+		assert.Equal(t, corev1.LabelHostname, terms[0].PodAffinityTerm.TopologyKey)
+		assert.Equal(t, corev1.LabelTopologyZone, terms[1].PodAffinityTerm.TopologyKey)
+		assert.Greater(t, terms[0].Weight, terms[1].Weight)
+		for _, term := range terms {
+			assert.Equal(t, sts.Spec.Selector.MatchLabels, term.PodAffinityTerm.LabelSelector.MatchLabels)
+		}
+	})
+	t.Run("no default pod anti affinity for a single kubernetes monitoring replica", func(t *testing.T) {
+		singleReplica := int32(1)
+		for _, replicas := range []*int32{nil, &singleReplica} {
+			dynakube := getTestDynakube()
+			dynakube.Spec.KubernetesMonitoring.Enabled = true
+			dynakube.Spec.KubernetesMonitoring.Replicas = replicas
+			builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+			sts := appsv1.StatefulSet{}
+
+			builder.addTemplateSpec(&sts)
+
+			assert.Equal(t, nodeAffinity(), sts.Spec.Template.Spec.Affinity)
+		}
+	})
+	t.Run("custom kubernetes monitoring affinity replaces the default pod anti affinity", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		replicas := int32(2)
+		dynakube.Spec.KubernetesMonitoring.Replicas = &replicas
+		dynakube.Spec.KubernetesMonitoring.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Nil(t, sts.Spec.Template.Spec.Affinity.PodAntiAffinity)
+		assert.Equal(t, dynakube.Spec.KubernetesMonitoring.Affinity.PodAffinity, sts.Spec.Template.Spec.Affinity.PodAffinity)
+	})
+	t.Run("changing scheduling constraints for kubernetes monitoring changes the hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+		hashes := map[string]bool{sts.Annotations[kubeobjects.AnnotationHash]: true}
+
+		for _, modify := range []func(*dynatracev1beta1.DynaKube){
+			func(dk *dynatracev1beta1.DynaKube) {
+				dk.Spec.KubernetesMonitoring.NodeSelector = map[string]string{"node-role.kubernetes.io/infra": ""}
+			},
+			func(dk *dynatracev1beta1.DynaKube) {
+				dk.Spec.KubernetesMonitoring.Tolerations = []corev1.Toleration{{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists}}
+			},
+			func(dk *dynatracev1beta1.DynaKube) {
+				replicas := int32(2)
+				dk.Spec.KubernetesMonitoring.Replicas = &replicas
+			},
+			func(dk *dynatracev1beta1.DynaKube) {
+				dk.Spec.KubernetesMonitoring.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+			},
+		} {
+			modify(&dynakube)
+			sts, err = NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+			require.NoError(t, err)
+
+			hash := sts.Annotations[kubeobjects.AnnotationHash]
+			assert.False(t, hashes[hash])
+			hashes[hash] = true
+		}
+	})
 	t.Run("set topologyConstraint", func(t *testing.T) {
 		dynakube := getTestDynakube()
 		testTopologyConstraint := []corev1.TopologySpreadConstraint{
@@ -267,6 +712,48 @@ func TestAddTemplateSpec(t *testing.T) {
 		spec := sts.Spec.Template.Spec
 		assert.Equal(t, testTopologyConstraint, spec.TopologySpreadConstraints)
 	})
+	t.Run("set topologyConstraint for kubernetes monitoring", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		testTopologyConstraint := []corev1.TopologySpreadConstraint{
+			{
+				MaxSkew:           1,
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			},
+		}
+		dynakube.Spec.KubernetesMonitoring.TopologySpreadConstraints = testTopologyConstraint
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Equal(t, testTopologyConstraint, sts.Spec.Template.Spec.TopologySpreadConstraints)
+	})
+	t.Run("empty topologyConstraint leaves the field nil", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{}
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+		sts := appsv1.StatefulSet{}
+
+		builder.addTemplateSpec(&sts)
+
+		assert.Nil(t, sts.Spec.Template.Spec.TopologySpreadConstraints)
+	})
+	t.Run("topologyConstraint changes the hash", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Spec.KubernetesMonitoring.Enabled = true
+		dynakube.Spec.KubernetesMonitoring.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{}
+		sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		dynakube.Spec.KubernetesMonitoring.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: corev1.LabelTopologyZone}}
+		customSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, sts.Annotations[kubeobjects.AnnotationHash], customSts.Annotations[kubeobjects.AnnotationHash])
+	})
 }
 
 func TestBuildBaseContainer(t *testing.T) {
@@ -325,8 +812,10 @@ func TestBuildCommonEnvs(t *testing.T) {
 		multiCapability := capability.NewMultiCapability(&dynakube)
 		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, multiCapability)
 
-		envs := builder.buildCommonEnvs()
+		sts, err := builder.CreateStatefulSet(nil)
+		require.NoError(t, err)
 
+		envs := sts.Spec.Template.Spec.Containers[0].Env
 		require.NotEmpty(t, envs)
 		for _, env := range testEnvs {
 			assert.Contains(t, envs, env)
@@ -0,0 +1,42 @@
+package statefulset
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// validateCustomVolumes rejects StatefulSets in which a user defined volume or volume mount collides with one managed by the operator
+func validateCustomVolumes(sts *appsv1.StatefulSet, spec dynatracev1beta1.KubernetesMonitoringSpec) error {
+	for _, customVolume := range spec.Volumes {
+		count := 0
+		for _, volume := range sts.Spec.Template.Spec.Volumes {
+			if volume.Name == customVolume.Name {
+				count++
+			}
+		}
+		if count > 1 {
+			return errors.Errorf("volume %s collides with a volume managed by the operator", customVolume.Name)
+		}
+	}
+
+	activeGateContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+	if activeGateContainer == nil {
+		return nil
+	}
+
+	for _, customVolumeMount := range spec.VolumeMounts {
+		count := 0
+		for _, volumeMount := range activeGateContainer.VolumeMounts {
+			if volumeMount.MountPath == customVolumeMount.MountPath {
+				count++
+			}
+		}
+		if count > 1 {
+			return errors.Errorf("mount path %s of volume mount %s collides with a volume mount managed by the operator", customVolumeMount.MountPath, customVolumeMount.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package statefulset
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/internal/customproperties"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	testVolumeName = "corporate-ca"
+	testMountPath  = "/etc/corporate-ca"
+)
+
+func getTestDynakubeWithCustomVolumes(volumeName string, mountPath string) dynatracev1beta1.DynaKube {
+	dynakube := getTestDynakube()
+	dynakube.Spec.KubernetesMonitoring.Enabled = true
+	dynakube.Spec.KubernetesMonitoring.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{Value: testVersion}
+	dynakube.Spec.KubernetesMonitoring.Volumes = []corev1.Volume{
+		{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: testVolumeName},
+				},
+			},
+		},
+	}
+	dynakube.Spec.KubernetesMonitoring.VolumeMounts = []corev1.VolumeMount{
+		{
+			Name:      volumeName,
+			MountPath: mountPath,
+			ReadOnly:  true,
+		},
+	}
+	return dynakube
+}
+
+func TestCustomVolumes(t *testing.T) {
+	t.Run("custom volumes are added to the kubernetes monitoring statefulset", func(t *testing.T) {
+		dynakube := getTestDynakubeWithCustomVolumes(testVolumeName, testMountPath)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		sts, err := builder.CreateStatefulSet(nil)
+
+		require.NoError(t, err)
+		assert.Contains(t, sts.Spec.Template.Spec.Volumes, dynakube.Spec.KubernetesMonitoring.Volumes[0])
+		activeGateContainer := kubeobjects.FindContainerInPodSpec(&sts.Spec.Template.Spec, consts.ActiveGateContainerName)
+		require.NotNil(t, activeGateContainer)
+		assert.Contains(t, activeGateContainer.VolumeMounts, dynakube.Spec.KubernetesMonitoring.VolumeMounts[0])
+	})
+	t.Run("changing custom volumes changes the hash", func(t *testing.T) {
+		dynakube := getTestDynakubeWithCustomVolumes(testVolumeName, testMountPath)
+		sts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		dynakube.Spec.KubernetesMonitoring.VolumeMounts[0].MountPath = "/etc/other"
+		changedSts, err := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube)).CreateStatefulSet(nil)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, sts.Annotations[kubeobjects.AnnotationHash], changedSts.Annotations[kubeobjects.AnnotationHash])
+	})
+	t.Run("custom volumes are ignored for other capabilities", func(t *testing.T) {
+		dynakube := getTestDynakubeWithCustomVolumes(testVolumeName, testMountPath)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewMultiCapability(&dynakube))
+
+		sts, err := builder.CreateStatefulSet(nil)
+
+		require.NoError(t, err)
+		assert.NotContains(t, sts.Spec.Template.Spec.Volumes, dynakube.Spec.KubernetesMonitoring.Volumes[0])
+	})
+	t.Run("volume name colliding with an operator volume is rejected", func(t *testing.T) {
+		dynakube := getTestDynakubeWithCustomVolumes(customproperties.VolumeName, testMountPath)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		sts, err := builder.CreateStatefulSet(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), customproperties.VolumeName)
+		assert.Nil(t, sts)
+	})
+	t.Run("mount path colliding with an operator volume mount is rejected", func(t *testing.T) {
+		dynakube := getTestDynakubeWithCustomVolumes(testVolumeName, customproperties.MountPath)
+		builder := NewStatefulSetBuilder(testKubeUID, testConfigHash, dynakube, capability.NewKubeMonCapability(&dynakube))
+
+		sts, err := builder.CreateStatefulSet(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), customproperties.MountPath)
+		assert.Nil(t, sts)
+	})
+}
@@ -15,9 +15,12 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -27,6 +30,8 @@ type Reconciler struct {
 	dynakube                          *dynatracev1beta1.DynaKube
 	apiReader                         client.Reader
 	scheme                            *runtime.Scheme
+	recorder                          record.EventRecorder
+	dtc                               dtclient.Client
 	authTokenReconciler               controllers.Reconciler
 	proxyReconciler                   controllers.Reconciler
 	newStatefulsetReconcilerFunc      statefulset.NewReconcilerFunc
@@ -36,11 +41,11 @@ type Reconciler struct {
 
 var _ controllers.Reconciler = (*Reconciler)(nil)
 
-func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, dynakube *dynatracev1beta1.DynaKube, dtc dtclient.Client) controllers.Reconciler {
+func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, dynakube *dynatracev1beta1.DynaKube, dtc dtclient.Client) controllers.Reconciler {
 	authTokenReconciler := authtoken.NewReconciler(clt, apiReader, scheme, dynakube, dtc)
 	proxyReconciler := proxy.NewReconciler(clt, apiReader, dynakube)
 	newCustomPropertiesReconcilerFunc := func(customPropertiesOwnerName string, customPropertiesSource *dynatracev1beta1.DynaKubeValueSource) controllers.Reconciler {
-		return customproperties.NewReconciler(clt, dynakube, customPropertiesOwnerName, scheme, customPropertiesSource)
+		return customproperties.NewReconciler(ctx, clt, dynakube, customPropertiesOwnerName, scheme, customPropertiesSource)
 	}
 
 	return &Reconciler{
@@ -48,7 +53,9 @@ func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Read
 		client:                            clt,
 		apiReader:                         apiReader,
 		scheme:                            scheme,
+		recorder:                          recorder,
 		dynakube:                          dynakube,
+		dtc:                               dtc,
 		authTokenReconciler:               authTokenReconciler,
 		proxyReconciler:                   proxyReconciler,
 		newCustomPropertiesReconcilerFunc: newCustomPropertiesReconcilerFunc,
@@ -57,6 +64,8 @@ func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Read
 	}
 }
 
+// Reconcile stops at the auth token and proxy secret, as the ActiveGate can't start without them,
+// the capability reconciler collects the errors of its remaining steps.
 func (r *Reconciler) Reconcile() error {
 	if r.dynakube.UseActiveGateAuthToken() {
 		err := r.authTokenReconciler.Reconcile()
@@ -70,6 +79,14 @@ func (r *Reconciler) Reconcile() error {
 		return err
 	}
 
+	if r.dynakube.NeedsNetworkZone() && r.dynakube.Status.NetworkZone != r.dynakube.Spec.NetworkZone {
+		err = r.dtc.CreateNetworkZone(r.dynakube.Spec.NetworkZone)
+		if err != nil {
+			return errors.WithMessage(err, "could not create network zone")
+		}
+		r.dynakube.Status.NetworkZone = r.dynakube.Spec.NetworkZone
+	}
+
 	var caps = capability.GenerateActiveGateCapabilities(r.dynakube)
 	for _, agCapability := range caps {
 		if agCapability.Enabled() {
@@ -87,7 +104,7 @@ func (r *Reconciler) Reconcile() error {
 
 func (r *Reconciler) createCapability(agCapability capability.Capability) error {
 	customPropertiesReconciler := r.newCustomPropertiesReconcilerFunc(r.dynakube.ActiveGateServiceAccountOwner(), agCapability.Properties().CustomProperties)
-	statefulsetReconciler := r.newStatefulsetReconcilerFunc(r.client, r.apiReader, r.scheme, r.dynakube, agCapability)
+	statefulsetReconciler := r.newStatefulsetReconcilerFunc(r.context, r.client, r.apiReader, r.scheme, r.recorder, r.dynakube, agCapability)
 
 	capabilityReconciler := r.newCapabilityReconcilerFunc(r.client, agCapability, r.dynakube, statefulsetReconciler, customPropertiesReconciler)
 	return capabilityReconciler.Reconcile()
@@ -102,6 +119,14 @@ func (r *Reconciler) deleteCapability(agCapability capability.Capability) error
 		return err
 	}
 
+	if err := r.deletePodDisruptionBudget(agCapability); err != nil {
+		return err
+	}
+
+	if err := r.deleteHorizontalPodAutoscaler(agCapability); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -119,6 +144,26 @@ func (r *Reconciler) deleteService(agCapability capability.Capability) error {
 	return kubeobjects.Delete(r.context, r.client, &svc)
 }
 
+func (r *Reconciler) deletePodDisruptionBudget(agCapability capability.Capability) error {
+	pdb := policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      capability.CalculateStatefulSetName(agCapability, r.dynakube.Name),
+			Namespace: r.dynakube.Namespace,
+		},
+	}
+	return kubeobjects.Delete(r.context, r.client, &pdb)
+}
+
+func (r *Reconciler) deleteHorizontalPodAutoscaler(agCapability capability.Capability) error {
+	hpa := autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      capability.CalculateStatefulSetName(agCapability, r.dynakube.Name),
+			Namespace: r.dynakube.Namespace,
+		},
+	}
+	return kubeobjects.DeleteIfExists(r.context, r.client, &hpa)
+}
+
 func (r *Reconciler) deleteStatefulset(agCapability capability.Capability) error {
 	sts := appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
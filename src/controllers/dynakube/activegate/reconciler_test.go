@@ -5,15 +5,18 @@ import (
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -21,6 +24,7 @@ const (
 	testNamespace   = "test-namespace"
 	testProxyName   = "test-proxy"
 	testServiceName = testName + "-activegate"
+	testNetworkZone = "test-zone"
 )
 
 var (
@@ -43,7 +47,7 @@ func TestReconciler_Reconcile(t *testing.T) {
 				Name:      testName,
 			}}
 		fakeClient := fake.NewClient()
-		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, instance, dtc)
+		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, record.NewFakeRecorder(10), instance, dtc)
 		err := r.Reconcile()
 		require.NoError(t, err)
 	})
@@ -58,12 +62,12 @@ func TestReconciler_Reconcile(t *testing.T) {
 			},
 		}
 		fakeClient := fake.NewClient()
-		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, instance, dtc)
+		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, record.NewFakeRecorder(10), instance, dtc)
 		err := r.Reconcile()
 		require.NoError(t, err)
 
 		var proxySecret corev1.Secret
-		err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "dynatrace-activegate-internal-proxy", Namespace: testNamespace}, &proxySecret)
+		err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: capability.BuildProxySecretName(testName), Namespace: testNamespace}, &proxySecret)
 		assert.NoError(t, err)
 	})
 	t.Run(`Create AG capability (creation and deletion)`, func(t *testing.T) {
@@ -79,7 +83,7 @@ func TestReconciler_Reconcile(t *testing.T) {
 			},
 		}
 		fakeClient := fake.NewClient(testKubeSystemNamespace)
-		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, instance, dtc)
+		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, record.NewFakeRecorder(10), instance, dtc)
 		err := r.Reconcile()
 		require.NoError(t, err)
 
@@ -94,4 +98,52 @@ func TestReconciler_Reconcile(t *testing.T) {
 		err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: testServiceName, Namespace: testNamespace}, &service)
 		assert.True(t, errors.IsNotFound(err))
 	})
+	t.Run(`Create network zone`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("CreateNetworkZone", testNetworkZone).Return(nil)
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      testName,
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeatureCreateNetworkZone:   "true",
+					dynatracev1beta1.AnnotationFeatureActiveGateAuthToken: "false",
+				},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				NetworkZone: testNetworkZone,
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.RoutingCapability.DisplayName},
+				},
+			},
+		}
+		fakeClient := fake.NewClient(testKubeSystemNamespace)
+		r := NewReconciler(context.TODO(), fakeClient, fakeClient, scheme.Scheme, record.NewFakeRecorder(10), instance, dtc)
+		err := r.Reconcile()
+		require.NoError(t, err)
+		dtc.AssertCalled(t, "CreateNetworkZone", testNetworkZone)
+		assert.Equal(t, testNetworkZone, instance.Status.NetworkZone)
+
+		// the tenant is not asked again once the network zone is known to exist
+		dtc.Calls = nil
+		err = r.Reconcile()
+		require.NoError(t, err)
+		dtc.AssertNotCalled(t, "CreateNetworkZone", testNetworkZone)
+
+		// a changed network zone is checked again
+		instance.Spec.NetworkZone = "other-zone"
+		dtc.On("CreateNetworkZone", "other-zone").Return(nil)
+		err = r.Reconcile()
+		require.NoError(t, err)
+		dtc.AssertCalled(t, "CreateNetworkZone", "other-zone")
+		assert.Equal(t, "other-zone", instance.Status.NetworkZone)
+
+		// without the feature flag the network zone has to exist already
+		delete(instance.Annotations, dynatracev1beta1.AnnotationFeatureCreateNetworkZone)
+		instance.Status.NetworkZone = ""
+		dtc.Calls = nil
+		err = r.Reconcile()
+		require.NoError(t, err)
+		dtc.AssertNotCalled(t, "CreateNetworkZone", mock.Anything)
+	})
 }
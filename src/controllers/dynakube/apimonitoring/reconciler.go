@@ -1,7 +1,10 @@
 package apimonitoring
 
 import (
+	"context"
+
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/pkg/errors"
 )
 
@@ -9,33 +12,38 @@ type ApiMonitoringReconciler struct {
 	dtc            dtclient.Client
 	clusterLabel   string
 	kubeSystemUUID string
+	// objectID is the settings object created for the DynaKube, only this object is relabeled
+	objectID string
 }
 
-func NewReconciler(dtc dtclient.Client, clusterLabel, kubeSystemUUID string) *ApiMonitoringReconciler {
+func NewReconciler(dtc dtclient.Client, clusterLabel, kubeSystemUUID, objectID string) *ApiMonitoringReconciler {
 	return &ApiMonitoringReconciler{
 		dtc,
 		clusterLabel,
 		kubeSystemUUID,
+		objectID,
 	}
 }
 
-func (r *ApiMonitoringReconciler) Reconcile() error {
-	objectID, err := r.createObjectIdIfNotExists()
+// Reconcile makes sure a Kubernetes settings object with the current cluster label exists for the cluster,
+// it returns the id of the object belonging to the DynaKube, or an empty string if the cluster is registered by another DynaKube
+func (r *ApiMonitoringReconciler) Reconcile(ctx context.Context) (string, error) {
+	objectID, err := r.createObjectIdIfNotExists(ctx)
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if objectID != "" {
-		log.Info("created kubernetes cluster setting", "clusterLabel", r.clusterLabel, "cluster", r.kubeSystemUUID, "object id", objectID)
+		logger.FromContext(ctx, log).Info("kubernetes cluster setting is up to date", "clusterLabel", r.clusterLabel, "cluster", r.kubeSystemUUID, "object id", objectID)
 	} else {
-		log.Info("kubernetes cluster setting already exists", "clusterLabel", r.clusterLabel, "cluster", r.kubeSystemUUID)
+		logger.FromContext(ctx, log).Info("kubernetes cluster setting belongs to another dynakube", "clusterLabel", r.clusterLabel, "cluster", r.kubeSystemUUID)
 	}
 
-	return nil
+	return objectID, nil
 }
 
-func (r *ApiMonitoringReconciler) createObjectIdIfNotExists() (string, error) {
+func (r *ApiMonitoringReconciler) createObjectIdIfNotExists(ctx context.Context) (string, error) {
 	if r.kubeSystemUUID == "" {
 		return "", errors.New("no kube-system namespace UUID given")
 	}
@@ -53,7 +61,7 @@ func (r *ApiMonitoringReconciler) createObjectIdIfNotExists() (string, error) {
 	}
 
 	if settings.TotalCount > 0 {
-		return "", nil
+		return r.reconcileExistingSetting(ctx, settings.Items)
 	}
 
 	// determine newest ME (can be empty string), and create or update a settings object accordingly
@@ -66,6 +74,33 @@ func (r *ApiMonitoringReconciler) createObjectIdIfNotExists() (string, error) {
 	return objectID, nil
 }
 
+// reconcileExistingSetting returns the id of the settings object of the DynaKube and relabels it, so a changed cluster label is reflected in Dynatrace.
+// Objects registered before their id was recorded are recognized by their label.
+// Objects of other DynaKubes monitoring the same cluster are left alone, so they don't relabel the object back and forth.
+func (r *ApiMonitoringReconciler) reconcileExistingSetting(ctx context.Context, settings []dtclient.KubernetesSettingObject) (string, error) {
+	for _, setting := range settings {
+		if r.objectID == "" || setting.ObjectId != r.objectID {
+			continue
+		}
+
+		if setting.Label() != r.clusterLabel {
+			err := r.dtc.UpdateKubernetesSettingLabel(setting, r.clusterLabel)
+			if err != nil {
+				return "", errors.WithMessage(err, "error updating dynatrace settings object")
+			}
+			logger.FromContext(ctx, log).Info("updated label of kubernetes cluster setting", "previousLabel", setting.Label(), "clusterLabel", r.clusterLabel, "object id", setting.ObjectId)
+		}
+		return setting.ObjectId, nil
+	}
+
+	for _, setting := range settings {
+		if setting.Label() == r.clusterLabel {
+			return setting.ObjectId, nil
+		}
+	}
+	return "", nil
+}
+
 // determineNewestMonitoredEntity returns the UUID of the newest entities; or empty string if the slice of entities is empty
 func determineNewestMonitoredEntity(entities []dtclient.MonitoredEntity) string {
 	if len(entities) == 0 {
@@ -1,6 +1,7 @@
 package apimonitoring
 
 import (
+	"context"
 	"testing"
 
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
@@ -33,7 +34,7 @@ func createReconciler(t *testing.T, uid string, monitoredEntities []dtclient.Mon
 	mockClient.On("CreateOrUpdateKubernetesSetting", testName, testUID, mock.AnythingOfType("string")).
 		Return(objectID, nil)
 
-	r := NewReconciler(mockClient, testName, uid)
+	r := NewReconciler(mockClient, testName, uid, testObjectID)
 	require.NotNil(t, r)
 	require.NotNil(t, r.dtc)
 
@@ -49,7 +50,7 @@ func createReconcilerWithError(t *testing.T, monitoredEntitiesError error, getSe
 	mockClient.On("CreateOrUpdateKubernetesSetting", testName, testUID, mock.AnythingOfType("string")).
 		Return("", createSettingsResponseError)
 
-	r := NewReconciler(mockClient, testName, testUID)
+	r := NewReconciler(mockClient, testName, testUID, testObjectID)
 	require.NotNil(t, r)
 	require.NotNil(t, r.dtc)
 
@@ -69,7 +70,7 @@ func TestReconcile(t *testing.T) {
 		r := createDefaultReconciler(t)
 
 		// act
-		err := r.Reconcile()
+		_, err := r.Reconcile(context.TODO())
 
 		// assert
 		assert.NoError(t, err)
@@ -80,7 +81,7 @@ func TestReconcile(t *testing.T) {
 		r := createReconciler(t, testUID, []dtclient.MonitoredEntity{}, dtclient.GetSettingsResponse{}, testObjectID)
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.NoError(t, err)
@@ -93,7 +94,7 @@ func TestReconcile(t *testing.T) {
 		r := createReconciler(t, testUID, entities, dtclient.GetSettingsResponse{}, testObjectID)
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.NoError(t, err)
@@ -106,12 +107,98 @@ func TestReconcile(t *testing.T) {
 		r := createReconciler(t, testUID, entities, dtclient.GetSettingsResponse{TotalCount: 1}, testObjectID)
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.NoError(t, err)
 		assert.Equal(t, "", actual)
 	})
+
+	t.Run(`update label of existing setting when the cluster label changed`, func(t *testing.T) {
+		// arrange
+		entities := createMonitoredEntities()
+		settings := dtclient.GetSettingsResponse{
+			TotalCount: 1,
+			Items: []dtclient.KubernetesSettingObject{
+				{ObjectId: testObjectID, Value: map[string]interface{}{"label": "previous-clusterLabel", "clusterId": testUID}},
+			},
+		}
+		r := createReconciler(t, testUID, entities, settings, "")
+		mockClient := r.dtc.(*dtclient.MockDynatraceClient)
+		mockClient.On("UpdateKubernetesSettingLabel", settings.Items[0], testName).Return(nil)
+
+		// act
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, testObjectID, actual)
+		mockClient.AssertCalled(t, "UpdateKubernetesSettingLabel", settings.Items[0], testName)
+		mockClient.AssertNotCalled(t, "CreateOrUpdateKubernetesSetting", testName, testUID, mock.AnythingOfType("string"))
+	})
+
+	t.Run(`don't relabel the setting of another dynakube`, func(t *testing.T) {
+		// arrange
+		entities := createMonitoredEntities()
+		settings := dtclient.GetSettingsResponse{
+			TotalCount: 1,
+			Items: []dtclient.KubernetesSettingObject{
+				{ObjectId: "other-objectid", Value: map[string]interface{}{"label": "other-clusterLabel", "clusterId": testUID}},
+			},
+		}
+		r := createReconciler(t, testUID, entities, settings, "")
+		mockClient := r.dtc.(*dtclient.MockDynatraceClient)
+
+		// act
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "", actual)
+		mockClient.AssertNotCalled(t, "UpdateKubernetesSettingLabel", settings.Items[0], testName)
+	})
+
+	t.Run(`don't update setting when the cluster label is unchanged`, func(t *testing.T) {
+		// arrange
+		entities := createMonitoredEntities()
+		settings := dtclient.GetSettingsResponse{
+			TotalCount: 1,
+			Items: []dtclient.KubernetesSettingObject{
+				{ObjectId: testObjectID, Value: map[string]interface{}{"label": testName, "clusterId": testUID}},
+			},
+		}
+		r := createReconciler(t, testUID, entities, settings, "")
+		mockClient := r.dtc.(*dtclient.MockDynatraceClient)
+
+		// act
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, testObjectID, actual)
+		mockClient.AssertNotCalled(t, "UpdateKubernetesSettingLabel", settings.Items[0], testName)
+	})
+
+	t.Run(`return id of a setting registered before the id was recorded`, func(t *testing.T) {
+		// arrange
+		entities := createMonitoredEntities()
+		settings := dtclient.GetSettingsResponse{
+			TotalCount: 2,
+			Items: []dtclient.KubernetesSettingObject{
+				{ObjectId: "other-objectid", Value: map[string]interface{}{"label": "other-clusterLabel", "clusterId": testUID}},
+				{ObjectId: "registered-objectid", Value: map[string]interface{}{"label": testName, "clusterId": testUID}},
+			},
+		}
+		r := createReconciler(t, testUID, entities, settings, "")
+		r.objectID = ""
+
+		// act
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "registered-objectid", actual)
+	})
 }
 
 func TestReconcileErrors(t *testing.T) {
@@ -120,7 +207,7 @@ func TestReconcileErrors(t *testing.T) {
 		r := createReconciler(t, "", []dtclient.MonitoredEntity{}, dtclient.GetSettingsResponse{}, testObjectID)
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.Error(t, err)
@@ -132,7 +219,7 @@ func TestReconcileErrors(t *testing.T) {
 		r := createReconcilerWithError(t, errors.New("could not get monitored entities"), nil, nil)
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.Error(t, err)
@@ -144,7 +231,7 @@ func TestReconcileErrors(t *testing.T) {
 		r := createReconcilerWithError(t, nil, errors.New("could not get settings for monitored entities"), nil)
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.Error(t, err)
@@ -156,7 +243,27 @@ func TestReconcileErrors(t *testing.T) {
 		r := createReconcilerWithError(t, nil, nil, errors.New("could not create monitored entity"))
 
 		// act
-		actual, err := r.createObjectIdIfNotExists()
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
+
+		// assert
+		assert.Error(t, err)
+		assert.Equal(t, "", actual)
+	})
+	t.Run(`return error when update settings api response is error`, func(t *testing.T) {
+		// arrange
+		entities := createMonitoredEntities()
+		settings := dtclient.GetSettingsResponse{
+			TotalCount: 1,
+			Items: []dtclient.KubernetesSettingObject{
+				{ObjectId: testObjectID, Value: map[string]interface{}{"label": "previous-clusterLabel", "clusterId": testUID}},
+			},
+		}
+		r := createReconciler(t, testUID, entities, settings, "")
+		r.dtc.(*dtclient.MockDynatraceClient).On("UpdateKubernetesSettingLabel", settings.Items[0], testName).
+			Return(errors.New("could not update settings object"))
+
+		// act
+		actual, err := r.createObjectIdIfNotExists(context.TODO())
 
 		// assert
 		assert.Error(t, err)
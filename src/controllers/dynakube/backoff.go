@@ -0,0 +1,79 @@
+package dynakube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// maxApiErrorBackoffSteps caps the doubling of errorUpdateInterval, 2^5 minutes are just above the default update interval
+	maxApiErrorBackoffSteps = 5
+	apiErrorBackoffJitter   = 0.1
+)
+
+// apiErrorBackoff counts the consecutive Dynatrace API errors per DynaKube, so the requeue interval grows exponentially
+// while the API keeps failing instead of hitting it again on every retry of the controller
+type apiErrorBackoff struct {
+	mutex    sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// next records another failure for key and returns the interval until the next reconcile,
+// errorUpdateInterval doubled for every previous failure with some jitter, so multiple DynaKubes don't retry in lockstep
+func (backoff *apiErrorBackoff) next(key types.NamespacedName) time.Duration {
+	backoff.mutex.Lock()
+	defer backoff.mutex.Unlock()
+
+	if backoff.failures == nil {
+		backoff.failures = map[types.NamespacedName]int{}
+	}
+
+	steps := backoff.failures[key]
+	if steps < maxApiErrorBackoffSteps {
+		backoff.failures[key] = steps + 1
+	} else {
+		steps = maxApiErrorBackoffSteps
+	}
+
+	return wait.Jitter(errorUpdateInterval<<steps, apiErrorBackoffJitter)
+}
+
+// reset forgets the failures of key, the next error starts again with errorUpdateInterval
+func (backoff *apiErrorBackoff) reset(key types.NamespacedName) {
+	backoff.mutex.Lock()
+	defer backoff.mutex.Unlock()
+
+	delete(backoff.failures, key)
+}
+
+// isApiError returns true if err is an error response of the Dynatrace API or the request didn't get a response at all,
+// both are expected to resolve on their own, so they are retried with a backoff
+func isApiError(err error) bool {
+	var serverErr dtclient.ServerError
+	return errors.As(err, &serverErr) || isTransportError(err)
+}
+
+// isTransportError returns true if a request failed before a response was received,
+// e.g. because of a timeout, a refused connection or a failed TLS handshake
+func isTransportError(err error) bool {
+	var netErr net.Error
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var certificateInvalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var recordHeaderErr tls.RecordHeaderError
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &certificateInvalidErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &recordHeaderErr)
+}
@@ -0,0 +1,76 @@
+package dynakube
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func assertInterval(t *testing.T, expected, actual time.Duration) {
+	t.Helper()
+	assert.GreaterOrEqual(t, actual, expected)
+	assert.LessOrEqual(t, actual, time.Duration(float64(expected)*(1+apiErrorBackoffJitter)))
+}
+
+func TestApiErrorBackoff(t *testing.T) {
+	key := types.NamespacedName{Name: testName, Namespace: testNamespace}
+
+	t.Run("interval doubles with every failure", func(t *testing.T) {
+		backoff := apiErrorBackoff{}
+
+		assertInterval(t, errorUpdateInterval, backoff.next(key))
+		assertInterval(t, 2*errorUpdateInterval, backoff.next(key))
+		assertInterval(t, 4*errorUpdateInterval, backoff.next(key))
+	})
+	t.Run("interval is capped", func(t *testing.T) {
+		backoff := apiErrorBackoff{}
+		for i := 0; i < 2*maxApiErrorBackoffSteps; i++ {
+			backoff.next(key)
+		}
+
+		assertInterval(t, errorUpdateInterval<<maxApiErrorBackoffSteps, backoff.next(key))
+	})
+	t.Run("failures are tracked per dynakube", func(t *testing.T) {
+		backoff := apiErrorBackoff{}
+		backoff.next(key)
+
+		assertInterval(t, errorUpdateInterval, backoff.next(types.NamespacedName{Name: "other", Namespace: testNamespace}))
+	})
+	t.Run("reset starts over", func(t *testing.T) {
+		backoff := apiErrorBackoff{}
+		backoff.next(key)
+		backoff.next(key)
+
+		backoff.reset(key)
+
+		assertInterval(t, errorUpdateInterval, backoff.next(key))
+	})
+}
+
+func TestIsApiError(t *testing.T) {
+	t.Run("server errors and transport errors are api errors", func(t *testing.T) {
+		for _, err := range []error{
+			dtclient.ServerError{Code: http.StatusServiceUnavailable, Message: "unavailable"},
+			&url.Error{Op: "Get", URL: testHost, Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}},
+			&url.Error{Op: "Get", URL: testHost, Err: context.DeadlineExceeded},
+			errors.WithStack(context.DeadlineExceeded),
+			errors.WithMessage(x509.UnknownAuthorityError{}, "tls handshake failed"),
+		} {
+			assert.True(t, isApiError(err), err.Error())
+		}
+	})
+	t.Run("other errors are no api errors", func(t *testing.T) {
+		assert.False(t, isApiError(nil))
+		assert.False(t, isApiError(errors.New("malformed response")))
+	})
+}
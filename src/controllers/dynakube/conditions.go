@@ -1,39 +1,213 @@
 package dynakube
 
 import (
+	"context"
+	"fmt"
+
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/token"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (controller *DynakubeController) setConditionTokenError(dynakube *dynatracev1beta1.DynaKube, err error) {
+func (controller *DynakubeController) setConditionTokenError(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, err error) {
+	reason := dynatracev1beta1.ReasonTokenError
+	if errors.As(err, &token.MissingScopesError{}) {
+		reason = dynatracev1beta1.ReasonTokenScopeMissing
+	}
+
 	tokenErrorCondition := metav1.Condition{
 		Type:    dynatracev1beta1.TokenConditionType,
 		Status:  metav1.ConditionFalse,
-		Reason:  dynatracev1beta1.ReasonTokenError,
+		Reason:  reason,
 		Message: err.Error(),
 	}
 
-	controller.setAndLogCondition(dynakube, tokenErrorCondition)
+	controller.setAndLogCondition(ctx, dynakube, tokenErrorCondition)
 }
 
-func (controller *DynakubeController) setConditionTokenReady(dynakube *dynatracev1beta1.DynaKube) {
+func (controller *DynakubeController) setConditionTokenReady(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) {
 	tokenErrorCondition := metav1.Condition{
 		Type:   dynatracev1beta1.TokenConditionType,
 		Status: metav1.ConditionTrue,
 		Reason: dynatracev1beta1.ReasonTokenReady,
 	}
 
-	controller.setAndLogCondition(dynakube, tokenErrorCondition)
+	controller.setAndLogCondition(ctx, dynakube, tokenErrorCondition)
+}
+
+func (controller *DynakubeController) setConditionImageVersionProviderOverridden(dynakube *dynatracev1beta1.DynaKube) {
+	overriddenCondition := metav1.Condition{
+		Type:    dynatracev1beta1.ImageVersionProviderConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonImageVersionProviderOverridden,
+		Message: "image versions are not resolved by the default image version provider",
+	}
+
+	controller.setCondition(dynakube, overriddenCondition)
+}
+
+func (controller *DynakubeController) removeConditionImageVersionProviderOverridden(dynakube *dynatracev1beta1.DynaKube) {
+	meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.ImageVersionProviderConditionType)
+}
+
+func (controller *DynakubeController) setConditionPullSecretReconciled(dynakube *dynatracev1beta1.DynaKube) {
+	controller.setCondition(dynakube, metav1.Condition{
+		Type:   dynatracev1beta1.PullSecretConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: dynatracev1beta1.ReasonPullSecretReconciled,
+	})
+}
+
+func (controller *DynakubeController) setConditionPullSecretError(dynakube *dynatracev1beta1.DynaKube, err error) {
+	controller.setCondition(dynakube, metav1.Condition{
+		Type:    dynatracev1beta1.PullSecretConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonPullSecretError,
+		Message: err.Error(),
+	})
+}
+
+func (controller *DynakubeController) setConditionApiReachable(dynakube *dynatracev1beta1.DynaKube) {
+	controller.setCondition(dynakube, metav1.Condition{
+		Type:   dynatracev1beta1.APIReachableConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: dynatracev1beta1.ReasonAPIReachable,
+	})
+}
+
+// setConditionApiUnreachable reports the http status of a server error or the network error in the message,
+// so networking and token problems can be told apart
+func (controller *DynakubeController) setConditionApiUnreachable(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, err error) {
+	logger.FromContext(ctx, log).Info("dynatrace api is not reachable", "apiUrl", dynakube.Spec.APIURL, "message", err.Error())
+	controller.setCondition(dynakube, metav1.Condition{
+		Type:    dynatracev1beta1.APIReachableConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonAPIUnreachable,
+		Message: err.Error(),
+	})
+}
+
+func (controller *DynakubeController) updateStatefulSetCondition(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) {
+	if !dynakube.NeedsActiveGate() {
+		meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.StatefulSetConditionType)
+		meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.ReadyConditionType)
+		dynakube.Status.ActiveGateReady = false
+		activeGateReplicasReadyMetric.DeleteLabelValues(dynakube.Namespace, dynakube.Name)
+		return
+	}
+
+	readyReplicas, desiredReplicas, err := controller.activeGateReplicas(ctx, dynakube)
+	if err != nil {
+		logger.FromContext(ctx, log).Info("could not determine ActiveGate replicas for the status", "error", err.Error())
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:    dynatracev1beta1.StatefulSetConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReplicasReady,
+		Message: fmt.Sprintf("%d/%d replicas ready", readyReplicas, desiredReplicas),
+	}
+	replicasReady := 1.0
+	if readyReplicas < desiredReplicas {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = dynatracev1beta1.ReasonReplicasNotReady
+		replicasReady = 0
+	}
+	controller.setCondition(dynakube, condition)
+	activeGateReplicasReadyMetric.WithLabelValues(dynakube.Namespace, dynakube.Name).Set(replicasReady)
+
+	dynakube.Status.ActiveGateReady = readyReplicas >= desiredReplicas
+	readyCondition := metav1.Condition{
+		Type:    dynatracev1beta1.ReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonActiveGateReady,
+		Message: condition.Message,
+	}
+	if !dynakube.Status.ActiveGateReady {
+		readyCondition.Status = metav1.ConditionFalse
+		readyCondition.Reason = dynatracev1beta1.ReasonActiveGateNotReady
+	}
+	controller.setCondition(dynakube, readyCondition)
+}
+
+// updateImagePullCondition reports the first ActiveGate container that can't pull its image.
+// A failing pull keeps the ActiveGate from getting ready, so the reconcile is requeued soon and the condition is removed once the pods recover.
+func (controller *DynakubeController) updateImagePullCondition(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) {
+	if !dynakube.NeedsActiveGate() {
+		meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.ImagePullFailedConditionType)
+		return
+	}
+
+	appLabels := kubeobjects.NewAppLabels(kubeobjects.ActiveGateComponentLabel, dynakube.Name, "", "")
+	var pods corev1.PodList
+	err := controller.client.List(ctx, &pods, client.InNamespace(dynakube.Namespace), client.MatchingLabels(appLabels.BuildMatchLabels()))
+	if err != nil {
+		logger.FromContext(ctx, log).Info("could not list ActiveGate pods for the status", "error", err.Error())
+		return
+	}
+
+	for _, pod := range pods.Items {
+		for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			waiting := containerStatus.State.Waiting
+			if waiting == nil || (waiting.Reason != dynatracev1beta1.ReasonErrImagePull && waiting.Reason != dynatracev1beta1.ReasonImagePullBackOff) {
+				continue
+			}
+
+			logger.FromContext(ctx, log).Info("ActiveGate pod can't pull its image",
+				"pod", pod.Name, "container", containerStatus.Name, "reason", waiting.Reason)
+			controller.setCondition(dynakube, metav1.Condition{
+				Type:    dynatracev1beta1.ImagePullFailedConditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  waiting.Reason,
+				Message: fmt.Sprintf("pod %s, container %s: %s", pod.Name, containerStatus.Name, waiting.Message),
+			})
+			return
+		}
+	}
+	meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.ImagePullFailedConditionType)
+}
+
+func (controller *DynakubeController) setConditionDegraded(dynakube *dynatracev1beta1.DynaKube, err error) {
+	controller.setCondition(dynakube, metav1.Condition{
+		Type:    dynatracev1beta1.DegradedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReconcilePanicked,
+		Message: err.Error(),
+	})
+}
+
+func (controller *DynakubeController) setConditionPaused(dynakube *dynatracev1beta1.DynaKube) {
+	controller.setCondition(dynakube, metav1.Condition{
+		Type:    dynatracev1beta1.PausedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReconcilePaused,
+		Message: fmt.Sprintf("reconciliation is paused by the %s annotation", dynatracev1beta1.AnnotationReconcilePaused),
+	})
+}
+
+// setCondition sets newCondition, the transition time is only updated if the condition actually changed
+func (controller *DynakubeController) setCondition(dynakube *dynatracev1beta1.DynaKube, newCondition metav1.Condition) {
+	if areStatusesEqual(meta.FindStatusCondition(dynakube.Status.Conditions, newCondition.Type), newCondition) {
+		return
+	}
+
+	newCondition.LastTransitionTime = controller.now()
+	meta.SetStatusCondition(&dynakube.Status.Conditions, newCondition)
 }
 
-func (controller *DynakubeController) setAndLogCondition(dynakube *dynatracev1beta1.DynaKube, newCondition metav1.Condition) {
+func (controller *DynakubeController) setAndLogCondition(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, newCondition metav1.Condition) {
 	controller.removeDeprecatedConditionTypes(dynakube)
 	statusCondition := meta.FindStatusCondition(dynakube.Status.Conditions, newCondition.Type)
 
 	if newCondition.Reason != dynatracev1beta1.ReasonTokenReady {
-		log.Info("problem with token detected",
-			"dynakube", dynakube.Name, "namespace", dynakube.Namespace,
+		logger.FromContext(ctx, log).Info("problem with token detected",
 			"token", newCondition.Type,
 			"message", newCondition.Message)
 	}
@@ -42,7 +216,7 @@ func (controller *DynakubeController) setAndLogCondition(dynakube *dynatracev1be
 		return
 	}
 
-	newCondition.LastTransitionTime = metav1.Now()
+	newCondition.LastTransitionTime = controller.now()
 	meta.SetStatusCondition(&dynakube.Status.Conditions, newCondition)
 }
 
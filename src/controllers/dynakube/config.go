@@ -2,8 +2,48 @@ package dynakube
 
 import (
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+
+	dynakubeReconciler     = "dynakube"
+	istioReconciler        = "istio"
+	activeGateReconciler   = "activegate"
+	oneAgentReconciler     = "oneagent"
+	appInjectionReconciler = "appinjection"
 )
 
 var (
 	log = logger.Factory.GetLogger("dynakube")
+
+	reconcileResultsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dynakube",
+		Name:      "reconcile_results",
+		Help:      "Number of DynaKube reconciliations by outcome",
+	}, []string{"outcome"})
+
+	activeGateReplicasReadyMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dynakube",
+		Name:      "activegate_replicas_ready",
+		Help:      "Whether all ActiveGate replicas of a DynaKube are ready (1) or not (0)",
+	}, []string{"namespace", "dynakube"})
+
+	reconcileDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dynakube",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of DynaKube reconciliations by sub-reconciler, 'dynakube' covers the whole reconcile",
+	}, []string{"reconciler"})
 )
+
+func init() {
+	metrics.Registry.MustRegister(reconcileResultsMetric)
+	metrics.Registry.MustRegister(activeGateReplicasReadyMetric)
+	metrics.Registry.MustRegister(reconcileDurationMetric)
+}
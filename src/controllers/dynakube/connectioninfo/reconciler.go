@@ -6,6 +6,7 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -27,11 +28,19 @@ func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Read
 	}
 }
 
+// Reconcile looks up the connection info of the tenant once per reconcile and shares it with the other components:
+// the OneAgent connection info is stored in the status and, with immutable images, in the OneAgent tenant secret,
+// the ActiveGate connection info in the ActiveGate tenant secret
 func (r *Reconciler) Reconcile() (err error) {
+	err = r.reconcileOneAgentConnectionInfo()
+	if err != nil {
+		return err
+	}
+
 	if !r.dynakube.FeatureDisableActivegateRawImage() {
 		activeGateConnectionInfo, err := r.dtc.GetActiveGateConnectionInfo()
 		if err != nil {
-			log.Info("failed to get activegate connection info")
+			logger.FromContext(r.context, log).Info("failed to get activegate connection info")
 			return err
 		}
 
@@ -41,19 +50,25 @@ func (r *Reconciler) Reconcile() (err error) {
 		}
 	}
 
-	if r.dynakube.FeatureOneAgentImmutableImage() {
-		oneAgentConnectionInfo, err := r.dtc.GetOneAgentConnectionInfo()
-		if err != nil {
-			log.Info("failed to get oneagent connection info")
-			return err
-		}
+	return nil
+}
 
-		err = r.createOrUpdateSecret(r.dynakube.OneagentTenantSecret(), oneAgentConnectionInfo.ConnectionInfo)
-		if err != nil {
-			return err
-		}
+func (r *Reconciler) reconcileOneAgentConnectionInfo() error {
+	oneAgentConnectionInfo, err := r.dtc.GetOneAgentConnectionInfo()
+	if err != nil {
+		logger.FromContext(r.context, log).Info("failed to get oneagent connection info")
+		return err
 	}
 
+	r.dynakube.Status.ConnectionInfo = dynatracev1beta1.ConnectionInfoStatus{
+		CommunicationHosts:              communicationHostsToStatus(oneAgentConnectionInfo.CommunicationHosts),
+		TenantUUID:                      oneAgentConnectionInfo.TenantUUID,
+		FormattedCommunicationEndpoints: oneAgentConnectionInfo.Endpoints,
+	}
+
+	if r.dynakube.FeatureOneAgentImmutableImage() {
+		return r.createOrUpdateSecret(r.dynakube.OneagentTenantSecret(), oneAgentConnectionInfo.ConnectionInfo)
+	}
 	return nil
 }
 
@@ -64,7 +79,7 @@ func (r *Reconciler) createOrUpdateSecret(secretName string, connectionInfo dtcl
 	query := kubeobjects.NewSecretQuery(r.context, r.client, r.apiReader, log)
 	err := query.CreateOrUpdate(*secret)
 	if err != nil {
-		log.Info("could not create or update secret for connection info", "name", secret.Name)
+		logger.FromContext(r.context, log).Info("could not create or update secret for connection info", "name", secret.Name)
 		return err
 	}
 	return nil
@@ -84,3 +99,13 @@ func buildConnectionInfoSecret(connectionInfo dtclient.ConnectionInfo) map[strin
 
 	return data
 }
+
+func communicationHostsToStatus(communicationHosts []dtclient.CommunicationHost) []dynatracev1beta1.CommunicationHostStatus {
+	var communicationHostStatuses []dynatracev1beta1.CommunicationHostStatus
+
+	for _, communicationHost := range communicationHosts {
+		communicationHostStatuses = append(communicationHostStatuses, dynatracev1beta1.CommunicationHostStatus(communicationHost))
+	}
+
+	return communicationHostStatuses
+}
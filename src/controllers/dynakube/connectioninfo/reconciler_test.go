@@ -6,9 +6,11 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -20,6 +22,7 @@ const (
 	testTenantToken     = "test-token"
 	testTenantUuid      = "test-uuid"
 	testTenantEndpoints = "test-endpoints"
+	testError           = "test-error"
 )
 
 func TestReconcile_ActivegateSecret(t *testing.T) {
@@ -38,6 +41,7 @@ func TestReconcile_ActivegateSecret(t *testing.T) {
 	}
 	dtc := &dtclient.MockDynatraceClient{}
 	dtc.On("GetActiveGateConnectionInfo").Return(tenantInfoResponse, nil)
+	dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{}, nil)
 
 	t.Run(`create activegate secret`, func(t *testing.T) {
 		fakeClient := fake.NewClientBuilder().Build()
@@ -179,3 +183,55 @@ func TestReconcile_OneagentSecret(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestReconcile_OneagentConnectionInfoStatus(t *testing.T) {
+	dynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationFeatureActiveGateRawImage: "false",
+			},
+		}}
+
+	t.Run(`connection info is stored in the status`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{
+			CommunicationHosts: []dtclient.CommunicationHost{
+				{Protocol: "https", Host: "tenant.example.com", Port: 443},
+				{Protocol: "https", Host: "activegate.example.com", Port: 9999},
+			},
+			ConnectionInfo: dtclient.ConnectionInfo{
+				TenantUUID:  testTenantUuid,
+				TenantToken: testTenantToken,
+				Endpoints:   testTenantEndpoints,
+			},
+		}, nil)
+		fakeClient := fake.NewClientBuilder().Build()
+
+		r := NewReconciler(context.TODO(), fakeClient, fakeClient, dynakube, dtc)
+		err := r.Reconcile()
+		require.NoError(t, err)
+
+		assert.Equal(t, testTenantUuid, dynakube.Status.ConnectionInfo.TenantUUID)
+		assert.Equal(t, testTenantEndpoints, dynakube.Status.ConnectionInfo.FormattedCommunicationEndpoints)
+		assert.Equal(t, []dynatracev1beta1.CommunicationHostStatus{
+			{Protocol: "https", Host: "tenant.example.com", Port: 443},
+			{Protocol: "https", Host: "activegate.example.com", Port: 9999},
+		}, dynakube.Status.ConnectionInfo.CommunicationHosts)
+		dtc.AssertNumberOfCalls(t, "GetOneAgentConnectionInfo", 1)
+
+		var secret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: dynakube.OneagentTenantSecret(), Namespace: testNamespace}, &secret)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`error querying connection info`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{}, errors.New(testError))
+		fakeClient := fake.NewClientBuilder().Build()
+
+		r := NewReconciler(context.TODO(), fakeClient, fakeClient, dynakube, dtc)
+		err := r.Reconcile()
+		assert.EqualError(t, err, testError)
+	})
+}
@@ -6,6 +6,8 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/token"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -26,6 +28,7 @@ type Reconciler struct {
 	dynakube  *dynatracev1beta1.DynaKube
 	scheme    *runtime.Scheme
 	tokens    token.Tokens
+	log       logr.Logger
 }
 
 func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, dynakube *dynatracev1beta1.DynaKube, tokens token.Tokens) *Reconciler {
@@ -36,6 +39,7 @@ func NewReconciler(ctx context.Context, clt client.Client, apiReader client.Read
 		scheme:    scheme,
 		dynakube:  dynakube,
 		tokens:    tokens,
+		log:       logger.FromContext(ctx, log),
 	}
 }
 
@@ -43,7 +47,7 @@ func (r *Reconciler) Reconcile() error {
 	if r.dynakube.Spec.CustomPullSecret == "" {
 		err := r.reconcilePullSecret()
 		if err != nil {
-			log.Info("could not reconcile pull secret")
+			r.log.Info("could not reconcile pull secret")
 			return errors.WithStack(err)
 		}
 	}
@@ -69,7 +73,7 @@ func (r *Reconciler) createPullSecretIfNotExists(pullSecretData map[string][]byt
 	var config corev1.Secret
 	err := r.apiReader.Get(r.ctx, client.ObjectKey{Name: extendWithPullSecretSuffix(r.dynakube.Name), Namespace: r.dynakube.Namespace}, &config)
 	if k8serrors.IsNotFound(err) {
-		log.Info("creating pull secret")
+		r.log.Info("creating pull secret")
 		return r.createPullSecret(pullSecretData)
 	}
 	return &config, err
@@ -97,7 +101,7 @@ func (r *Reconciler) createPullSecret(pullSecretData map[string][]byte) (*corev1
 }
 
 func (r *Reconciler) updatePullSecret(pullSecret *corev1.Secret, desiredPullSecretData map[string][]byte) error {
-	log.Info("updating secret", "name", pullSecret.Name)
+	r.log.Info("updating secret", "name", pullSecret.Name)
 	pullSecret.Data = desiredPullSecretData
 	if err := r.client.Update(r.ctx, pullSecret); err != nil {
 		return errors.WithMessagef(err, "failed to update secret %s", pullSecret.Name)
@@ -7,9 +7,12 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/token"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/go-logr/logr/funcr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -156,3 +159,31 @@ func TestReconciler_Reconcile(t *testing.T) {
 		assert.Equal(t, expectedJSON, string(pullSecret.Data[".dockerconfigjson"]))
 	})
 }
+
+func TestReconcile_LogCorrelation(t *testing.T) {
+	var logLines []string
+	originalLog := log
+	log = funcr.New(func(_, args string) {
+		logLines = append(logLines, args)
+	}, funcr.Options{})
+	defer func() { log = originalLog }()
+
+	dynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		}}
+	ctx := logger.NewCorrelationContext(context.TODO(), "dynakube", testName, "reconcileID", "test-reconcile-id")
+	fakeClient := fake.NewClient()
+	r := NewReconciler(ctx, fakeClient, fakeClient, scheme.Scheme, dynakube, token.Tokens{
+		dtclient.DynatraceApiToken: token.Token{Value: testValue},
+	})
+
+	require.NoError(t, r.Reconcile())
+
+	require.NotEmpty(t, logLines)
+	for _, logLine := range logLines {
+		assert.Contains(t, logLine, `"dynakube"="`+testName+`"`)
+		assert.Contains(t, logLine, `"reconcileID"="test-reconcile-id"`)
+	}
+}
@@ -2,12 +2,15 @@ package dynakube
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/apimonitoring"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/connectioninfo"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtpullsecret"
@@ -22,37 +25,57 @@ import (
 	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
 	"github.com/Dynatrace/dynatrace-operator/src/initgeneration"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
-	errorUpdateInterval   = 1 * time.Minute
-	changesUpdateInterval = 5 * time.Minute
-	defaultUpdateInterval = 30 * time.Minute
+	errorUpdateInterval    = 1 * time.Minute
+	changesUpdateInterval  = 5 * time.Minute
+	notReadyUpdateInterval = 30 * time.Second
+	defaultUpdateInterval  = 30 * time.Minute
 )
 
-func Add(mgr manager.Manager, _ string) error {
-	return NewController(mgr).SetupWithManager(mgr)
+func Add(mgr manager.Manager, _ string, dynatraceApiTimeout time.Duration, reconcileInterval time.Duration) error {
+	controller := NewController(mgr)
+	controller.dynatraceApiTimeout = dynatraceApiTimeout
+	controller.reconcileInterval = reconcileInterval
+	return controller.SetupWithManager(mgr)
 }
 
 // NewController returns a new ReconcileDynaKube
 func NewController(mgr manager.Manager) *DynakubeController {
-	return NewDynaKubeController(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), mgr.GetConfig())
+	return NewDynaKubeController(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), mgr.GetConfig(),
+		WithEventRecorder(mgr.GetEventRecorderFor("dynakube-controller")))
 }
 
-func NewDynaKubeController(kubeClient client.Client, apiReader client.Reader, scheme *runtime.Scheme, config *rest.Config) *DynakubeController {
-	return &DynakubeController{
+// NewDynaKubeController creates the controller with its default collaborators,
+// opts can be used to replace the optional ones, entries must not be nil.
+func NewDynaKubeController(kubeClient client.Client, apiReader client.Reader, scheme *runtime.Scheme, config *rest.Config, opts ...Option) *DynakubeController {
+	controller := &DynakubeController{
 		client:                 kubeClient,
 		apiReader:              apiReader,
 		scheme:                 scheme,
@@ -60,7 +83,68 @@ func NewDynaKubeController(kubeClient client.Client, apiReader client.Reader, sc
 		dynatraceClientBuilder: dynatraceclient.NewBuilder(apiReader),
 		config:                 config,
 		operatorNamespace:      os.Getenv("POD_NAMESPACE"),
+		imageVersionCache:      version.NewImageVersionCache(version.DefaultImageVersionCacheTTL),
+		clock:                  clock.RealClock{},
 	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Option can be passed to NewDynaKubeController and customizes the created controller.
+type Option func(*DynakubeController)
+
+// WithImageVersionProvider replaces version.GetImageVersion for resolving image versions, a nil provider keeps the default one.
+// This is only meant to be used by tests, the DynaKube gets a condition while it is set.
+func WithImageVersionProvider(provider version.VersionProviderCallback) Option {
+	return func(controller *DynakubeController) {
+		controller.imageVersionProvider = provider
+	}
+}
+
+// WithImageVersionCache replaces the cache of the default image version provider, a nil cache disables caching.
+func WithImageVersionCache(cache version.ImageVersionCache) Option {
+	return func(controller *DynakubeController) {
+		controller.imageVersionCache = cache
+	}
+}
+
+// WithEventRecorder sets the recorder for the events on the DynaKube, without it the events are discarded.
+func WithEventRecorder(recorder record.EventRecorder) Option {
+	return func(controller *DynakubeController) {
+		controller.recorder = recorder
+	}
+}
+
+// WithClock replaces the clock used for status timestamps and probe intervals.
+func WithClock(clock clock.PassiveClock) Option {
+	return func(controller *DynakubeController) {
+		controller.clock = clock
+	}
+}
+
+// WithDynatraceClientBuilder replaces the builder used to create the client for the Dynatrace API.
+func WithDynatraceClientBuilder(builder dynatraceclient.Builder) Option {
+	return func(controller *DynakubeController) {
+		controller.dynatraceClientBuilder = builder
+	}
+}
+
+func (controller *DynakubeController) now() metav1.Time {
+	if controller.clock == nil {
+		return metav1.Now()
+	}
+	return metav1.NewTime(controller.clock.Now())
+}
+
+func (controller *DynakubeController) timeProvider() kubeobjects.TimeProvider {
+	timeProvider := kubeobjects.NewTimeProvider()
+	now := controller.now()
+	timeProvider.SetNow(&now)
+	return *timeProvider
 }
 
 func (controller *DynakubeController) SetupWithManager(mgr ctrl.Manager) error {
@@ -68,9 +152,63 @@ func (controller *DynakubeController) SetupWithManager(mgr ctrl.Manager) error {
 		For(&dynatracev1beta1.DynaKube{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.DaemonSet{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(controller.mapTokenSecretToDynakubes)).
+		Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(controller.mapNodeToAutoSizedDynakubes),
+			builder.WithPredicates(nodeCountChangedPredicate())).
 		Complete(controller)
 }
 
+// nodeCountChangedPredicate ignores node updates, as the frequent status updates of nodes don't change the node count
+func nodeCountChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(event.UpdateEvent) bool {
+			return false
+		},
+	}
+}
+
+// mapNodeToAutoSizedDynakubes enqueues the DynaKubes that size their ActiveGates by the node count,
+// so added or removed nodes are picked up without waiting for the next regular reconcile
+func (controller *DynakubeController) mapNodeToAutoSizedDynakubes(node client.Object) []reconcile.Request {
+	var dynakubes dynatracev1beta1.DynaKubeList
+	if err := controller.client.List(context.TODO(), &dynakubes); err != nil {
+		log.Info("could not list dynakubes for node", "node", node.GetName(), "error", err.Error())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dynakube := range dynakubes.Items {
+		if isAutoSized(dynakube) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynakube)})
+		}
+	}
+	return requests
+}
+
+func isAutoSized(dynakube dynatracev1beta1.DynaKube) bool {
+	return dynakube.Spec.ActiveGate.AutoSizing != nil ||
+		dynakube.Spec.Routing.AutoSizing != nil ||
+		dynakube.Spec.KubernetesMonitoring.AutoSizing != nil
+}
+
+// mapTokenSecretToDynakubes enqueues the DynaKubes that read their tokens from secret,
+// so a rotated token, e.g. by an external secret store, is picked up without waiting for the next regular reconcile
+func (controller *DynakubeController) mapTokenSecretToDynakubes(secret client.Object) []reconcile.Request {
+	var dynakubes dynatracev1beta1.DynaKubeList
+	if err := controller.client.List(context.TODO(), &dynakubes, client.InNamespace(secret.GetNamespace())); err != nil {
+		log.Info("could not list dynakubes for token secret", "secret", secret.GetName(), "error", err.Error())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dynakube := range dynakubes.Items {
+		if dynakube.Tokens() == secret.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dynakube)})
+		}
+	}
+	return requests
+}
+
 // DynakubeController reconciles a DynaKube object
 type DynakubeController struct {
 	// This client, initialized using mgr.Client() above, is a split client
@@ -82,6 +220,27 @@ type DynakubeController struct {
 	dynatraceClientBuilder dynatraceclient.Builder
 	config                 *rest.Config
 	operatorNamespace      string
+
+	// dynatraceApiTimeout limits every request to the Dynatrace API, so a hung connection can't block a reconcile worker
+	dynatraceApiTimeout time.Duration
+
+	// reconcileInterval replaces defaultUpdateInterval if set, it reverts manual changes to the deployed objects that don't trigger a watch event
+	reconcileInterval time.Duration
+
+	// imageVersionProvider replaces version.GetImageVersion if set, only meant to be used by tests
+	imageVersionProvider version.VersionProviderCallback
+
+	// clock provides the time for status timestamps and probe intervals, defaults to the real clock if nil
+	clock clock.PassiveClock
+
+	// imageVersionCache deduplicates registry lookups of the default provider across reconciles, disabled if nil
+	imageVersionCache version.ImageVersionCache
+
+	// recorder emits events on the DynaKube for significant transitions, so they show up in kubectl describe, discarded if nil
+	recorder record.EventRecorder
+
+	// apiErrorBackoff grows the requeue interval while the Dynatrace API keeps failing for a DynaKube
+	apiErrorBackoff apiErrorBackoff
 }
 
 // Reconcile reads that state of the cluster for a DynaKube object and makes changes based on the state read
@@ -91,54 +250,157 @@ type DynakubeController struct {
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (controller *DynakubeController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	log.Info("reconciling DynaKube", "namespace", request.Namespace, "name", request.Name)
-	requeueAfter := defaultUpdateInterval
+	// every log line of this reconcile carries the same fields, so the lines of the sub-reconcilers can be correlated
+	ctx = logger.NewCorrelationContext(ctx, "dynakube", request.Name, "namespace", request.Namespace, "reconcileID", string(uuid.NewUUID()))
+	reconcileLog := logger.FromContext(ctx, log)
+	reconcileLog.Info("reconciling DynaKube")
+	requeueAfter := controller.updateInterval()
 
 	dynakube, err := controller.getDynakubeOrUnmap(ctx, request.Name, request.Namespace)
 	if err != nil {
 		return reconcile.Result{}, err
 	} else if dynakube == nil {
+		// the DynaKube is gone, so its failures don't have to be remembered anymore
+		controller.apiErrorBackoff.reset(request.NamespacedName)
 		return reconcile.Result{}, nil
 	}
 
+	if dynakube.DeletionTimestamp != nil {
+		return controller.finalize(ctx, dynakube)
+	}
+
+	// deletion is still handled while paused, so a paused DynaKube can't block its own removal
+	if dynakube.IsReconcilePaused() {
+		return reconcile.Result{}, controller.pauseReconcile(ctx, dynakube)
+	}
+
 	oldStatus := *dynakube.Status.DeepCopy()
-	updated := controller.reconcileIstio(dynakube)
+	meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.PausedConditionType)
+	updated := controller.reconcileIstio(ctx, dynakube)
 	if updated {
-		log.Info("istio: objects updated")
+		reconcileLog.Info("istio: objects updated")
 	}
 
-	err = controller.reconcileDynaKube(ctx, dynakube)
+	err = controller.reconcileDynaKubeRecoveringPanics(ctx, dynakube)
+	countReconcileResult(err)
+	var panicErr recoveredPanicError
+	panicked := errors.As(err, &panicErr)
 
+	var serverErr dtclient.ServerError
+	var apiErrorRequeueAfter time.Duration
+	isServerError := errors.As(err, &serverErr)
+	isApiErr := isApiError(err)
 	if err != nil {
 		requeueAfter = errorUpdateInterval
 
-		var serverErr dtclient.ServerError
-		isServerError := errors.As(err, &serverErr)
+		if isApiErr {
+			apiErrorRequeueAfter = controller.apiErrorBackoff.next(request.NamespacedName)
+			requeueAfter = apiErrorRequeueAfter
+		}
 		if isServerError && serverErr.Code == http.StatusTooManyRequests {
 			// should we set the phase to error ?
-			log.Info("request limit for Dynatrace API reached! Next reconcile in one minute")
+			reconcileLog.Info("request limit for Dynatrace API reached!", "requeueAfter", requeueAfter)
 			return reconcile.Result{RequeueAfter: requeueAfter}, nil
 		}
 		dynakube.Status.SetPhase(dynatracev1beta1.Error)
+		dynakube.Status.SetLastError(err, controller.now())
 	} else {
-		dynakube.Status.SetPhase(controller.determineDynaKubePhase(dynakube))
+		controller.apiErrorBackoff.reset(request.NamespacedName)
+		dynakube.Status.SetPhase(controller.determineDynaKubePhase(ctx, dynakube))
+		dynakube.Status.ClearLastError()
 	}
 
-	isStatusDifferent, err := kubeobjects.IsDifferent(oldStatus, dynakube.Status)
-	if err != nil {
-		log.Error(err, "failed to generate hash for the status section")
+	isStatusDifferent, hashErr := kubeobjects.IsDifferent(oldStatus, dynakube.Status)
+	if hashErr != nil {
+		reconcileLog.Error(hashErr, "failed to generate hash for the status section")
 	}
 	if isStatusDifferent {
-		log.Info("status changed, updating DynaKube")
+		reconcileLog.Info("status changed, updating DynaKube")
 		requeueAfter = changesUpdateInterval
 		if errClient := controller.updateDynakubeStatus(ctx, dynakube); errClient != nil {
 			return reconcile.Result{}, errors.WithMessagef(errClient, "failed to update DynaKube after failure, original error: %s", err)
 		}
 	}
 
+	if panicked {
+		// returning the error lets the rate limiter of the controller back off, instead of retrying on a fixed interval
+		return reconcile.Result{}, panicErr
+	}
+
+	if isApiErr {
+		// the rate limiter of the controller would retry within milliseconds, the backoff gives the Dynatrace API time to recover
+		reconcileLog.Error(err, "request to the Dynatrace API failed", "requeueAfter", apiErrorRequeueAfter)
+		return reconcile.Result{RequeueAfter: apiErrorRequeueAfter}, nil
+	}
+
+	if err == nil && dynakube.NeedsActiveGate() && !dynakube.Status.ActiveGateReady {
+		// check again soon, so the Ready condition follows the pods instead of the regular update interval
+		requeueAfter = notReadyUpdateInterval
+	}
+
 	return reconcile.Result{RequeueAfter: requeueAfter}, err
 }
 
+// updateInterval is the requeue interval after a successful reconcile without changes.
+// Every reconcile verifies the tokens and updates the status through the Dynatrace API, so a shorter interval causes more API requests.
+func (controller *DynakubeController) updateInterval() time.Duration {
+	if controller.reconcileInterval > 0 {
+		return controller.reconcileInterval
+	}
+	return defaultUpdateInterval
+}
+
+// recoveredPanicError is returned instead of a panic of a sub-reconciler
+type recoveredPanicError struct {
+	value interface{}
+}
+
+func (err recoveredPanicError) Error() string {
+	return fmt.Sprintf("recovered from panic during reconcile: %v", err.value)
+}
+
+// reconcileDynaKubeRecoveringPanics turns a panic during the reconcile into an error and sets the Degraded condition,
+// so one broken DynaKube can't crash the operator and stop the reconciliation of all others
+func (controller *DynakubeController) reconcileDynaKubeRecoveringPanics(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (err error) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.DegradedConditionType)
+			return
+		}
+
+		err = recoveredPanicError{value: value}
+		logger.FromContext(ctx, log).Error(err, "reconcile panicked", "stack", string(debug.Stack()))
+		controller.setConditionDegraded(dynakube, err)
+	}()
+
+	return controller.reconcileDynaKube(ctx, dynakube)
+}
+
+// pauseReconcile only reports the paused state, the status is written once when the pause starts
+func (controller *DynakubeController) pauseReconcile(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	logger.FromContext(ctx, log).Info("reconciliation is paused", "annotation", dynatracev1beta1.AnnotationReconcilePaused)
+	if meta.IsStatusConditionTrue(dynakube.Status.Conditions, dynatracev1beta1.PausedConditionType) {
+		return nil
+	}
+
+	controller.setConditionPaused(dynakube)
+	return controller.updateDynakubeStatus(ctx, dynakube)
+}
+
+func countReconcileResult(err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeError
+	}
+	reconcileResultsMetric.WithLabelValues(outcome).Inc()
+}
+
+// observeReconcileDuration records the time since start for reconciler, it is meant to be deferred at the start of a sub-reconciler
+func observeReconcileDuration(reconciler string, start time.Time) {
+	reconcileDurationMetric.WithLabelValues(reconciler).Observe(time.Since(start).Seconds())
+}
+
 func (controller *DynakubeController) getDynakubeOrUnmap(ctx context.Context, dkName, dkNamespace string) (*dynatracev1beta1.DynaKube, error) {
 	dynakube := &dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
@@ -160,7 +422,8 @@ func (controller *DynakubeController) createDynakubeMapper(ctx context.Context,
 	return &dkMapper
 }
 
-func (controller *DynakubeController) reconcileIstio(dynakube *dynatracev1beta1.DynaKube) bool {
+func (controller *DynakubeController) reconcileIstio(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) bool {
+	defer observeReconcileDuration(istioReconciler, time.Now())
 	var err error
 	updated := false
 
@@ -168,7 +431,7 @@ func (controller *DynakubeController) reconcileIstio(dynakube *dynatracev1beta1.
 		updated, err = istio.NewIstioReconciler(controller.config, controller.scheme).ReconcileIstio(dynakube)
 		if err != nil {
 			// If there are errors log them, but move on.
-			log.Info("istio: failed to reconcile objects", "error", err)
+			logger.FromContext(ctx, log).Info("istio: failed to reconcile objects", "error", err)
 		}
 	}
 
@@ -176,32 +439,46 @@ func (controller *DynakubeController) reconcileIstio(dynakube *dynatracev1beta1.
 }
 
 func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	defer observeReconcileDuration(dynakubeReconciler, time.Now())
 	tokenReader := token.NewReader(controller.apiReader, dynakube)
 	tokens, err := tokenReader.ReadTokens(ctx)
 
 	if err != nil {
-		controller.setConditionTokenError(dynakube, err)
+		controller.reportTokenError(ctx, dynakube, err)
 		return err
 	}
 
 	dynatraceClientBuilder := controller.dynatraceClientBuilder.
 		SetContext(ctx).
+		SetTimeout(controller.dynatraceApiTimeout).
 		SetDynakube(*dynakube).
 		SetTokens(tokens)
+
+	err = controller.checkApiConnection(ctx, dynakube, dynatraceClientBuilder)
+	if err != nil {
+		return err
+	}
+
 	dynatraceClient, err := dynatraceClientBuilder.BuildWithTokenVerification(&dynakube.Status)
 
 	if err != nil {
-		controller.setConditionTokenError(dynakube, err)
+		controller.reportTokenError(ctx, dynakube, err)
 		return err
 	}
 
-	controller.setConditionTokenReady(dynakube)
-	err = status.SetDynakubeStatus(dynakube, status.Options{
-		DtClient:  dynatraceClient,
-		ApiReader: controller.apiReader,
+	controller.setConditionTokenReady(ctx, dynakube)
+	err = status.SetDynakubeStatus(ctx, dynakube, status.Options{
+		DtClient:     dynatraceClient,
+		ApiReader:    controller.apiReader,
+		TimeProvider: controller.timeProvider(),
 	})
 	if err != nil {
-		log.Info("could not update Dynakube status")
+		logger.FromContext(ctx, log).Info("could not update Dynakube status")
+		return err
+	}
+
+	err = connectioninfo.NewReconciler(ctx, controller.client, controller.apiReader, dynakube, dynatraceClient).Reconcile()
+	if err != nil {
 		return err
 	}
 
@@ -209,43 +486,64 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dyn
 		NewReconciler(ctx, controller.client, controller.apiReader, controller.scheme, dynakube, tokens).
 		Reconcile()
 	if err != nil {
-		log.Info("could not reconcile Dynatrace pull secret")
+		logger.FromContext(ctx, log).Info("could not reconcile Dynatrace pull secret")
+		controller.setConditionPullSecretError(dynakube, err)
 		return err
 	}
+	controller.setConditionPullSecretReconciled(dynakube)
 
-	err = connectioninfo.NewReconciler(ctx, controller.client, controller.apiReader, dynakube, dynatraceClient).Reconcile()
+	oldVersionStatuses := currentVersionStatuses(dynakube)
+	err = version.ReconcileVersions(ctx, dynakube, controller.client, controller.apiReader, controller.fs, controller.getImageVersionProvider(ctx, dynakube), controller.timeProvider())
 	if err != nil {
+		logger.FromContext(ctx, log).Info("could not reconcile component versions")
 		return err
 	}
-
-	err = version.ReconcileVersions(ctx, dynakube, controller.apiReader, controller.fs, version.GetImageVersion, *kubeobjects.NewTimeProvider())
-	if err != nil {
-		log.Info("could not reconcile component versions")
+	controller.sendImageVersionUpdatedEvents(dynakube, oldVersionStatuses)
+	if err = controller.removeRefreshImageAnnotation(ctx, dynakube); err != nil {
 		return err
 	}
 
 	err = controller.reconcileActiveGate(ctx, dynakube, dynatraceClient)
 	if err != nil {
-		log.Info("could not reconcile ActiveGate")
+		logger.FromContext(ctx, log).Info("could not reconcile ActiveGate")
 		return err
 	}
 
 	err = controller.reconcileOneAgent(ctx, dynakube)
 	if err != nil {
-		log.Info("could not reconcile OneAgent")
+		logger.FromContext(ctx, log).Info("could not reconcile OneAgent")
 		return err
 	}
 
 	err = controller.reconcileAppInjection(ctx, dynakube)
 	if err != nil {
-		log.Info("could not reconcile app injection")
+		logger.FromContext(ctx, log).Info("could not reconcile app injection")
 		return err
 	}
 
 	return nil
 }
 
+func (controller *DynakubeController) getImageVersionProvider(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) version.VersionProviderCallback {
+	if controller.imageVersionProvider == nil {
+		controller.removeConditionImageVersionProviderOverridden(dynakube)
+		if controller.imageVersionCache == nil {
+			return version.GetImageVersion
+		}
+		if dynakube.IsImageRefreshRequested() {
+			// a requested refresh has to reach the registry, as the image may have changed under the same tag
+			return version.RefreshingImageVersionProvider(controller.imageVersionCache, version.GetImageVersion)
+		}
+		return version.CachedImageVersionProvider(controller.imageVersionCache, version.GetImageVersion)
+	}
+
+	logger.FromContext(ctx, log).Info("image versions are resolved by a non-default provider, this is only supported in tests")
+	controller.setConditionImageVersionProviderOverridden(dynakube)
+	return controller.imageVersionProvider
+}
+
 func (controller *DynakubeController) reconcileAppInjection(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	defer observeReconcileDuration(appInjectionReconciler, time.Now())
 	if dynakube.NeedAppInjection() {
 		return controller.setupAppInjection(ctx, dynakube)
 	}
@@ -258,19 +556,19 @@ func (controller *DynakubeController) setupAppInjection(ctx context.Context, dyn
 	dkMapper := controller.createDynakubeMapper(ctx, dynakube)
 
 	if err = dkMapper.MapFromDynakube(); err != nil {
-		log.Info("update of a map of namespaces failed")
+		logger.FromContext(ctx, log).Info("update of a map of namespaces failed")
 		return err
 	}
 
 	err = initgeneration.NewInitGenerator(controller.client, controller.apiReader, dynakube.Namespace).GenerateForDynakube(ctx, dynakube)
 	if err != nil {
-		log.Info("failed to generate init secret")
+		logger.FromContext(ctx, log).Info("failed to generate init secret")
 		return err
 	}
 
 	err = endpointSecretGenerator.GenerateForDynakube(ctx, dynakube)
 	if err != nil {
-		log.Info("failed to generate data-ingest secret")
+		logger.FromContext(ctx, log).Info("failed to generate data-ingest secret")
 		return err
 	}
 
@@ -278,7 +576,7 @@ func (controller *DynakubeController) setupAppInjection(ctx context.Context, dyn
 		dynakube.Status.SetPhase(dynatracev1beta1.Running)
 	}
 
-	log.Info("app injection reconciled")
+	logger.FromContext(ctx, log).Info("app injection reconciled")
 	return nil
 }
 
@@ -287,18 +585,19 @@ func (controller *DynakubeController) removeAppInjection(ctx context.Context, dy
 	dkMapper := controller.createDynakubeMapper(ctx, dynakube)
 
 	if err := dkMapper.UnmapFromDynaKube(); err != nil {
-		log.Info("could not unmap dynakube from namespace")
+		logger.FromContext(ctx, log).Info("could not unmap dynakube from namespace")
 		return err
 	}
 	err = endpointSecretGenerator.RemoveEndpointSecrets(ctx, dynakube)
 	if err != nil {
-		log.Info("could not remove data-ingest secret")
+		logger.FromContext(ctx, log).Info("could not remove data-ingest secret")
 		return err
 	}
 	return nil
 }
 
 func (controller *DynakubeController) reconcileOneAgent(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	defer observeReconcileDuration(oneAgentReconciler, time.Now())
 	deploymentType := getDeploymentType(dynakube)
 
 	if deploymentType == "" {
@@ -328,40 +627,206 @@ func (controller *DynakubeController) removeOneAgentDaemonSet(ctx context.Contex
 }
 
 func (controller *DynakubeController) reconcileActiveGate(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, dtc dtclient.Client) error {
-	reconciler := activegate.NewReconciler(ctx, controller.client, controller.apiReader, controller.scheme, dynakube, dtc)
+	defer observeReconcileDuration(activeGateReconciler, time.Now())
+	reconciler := activegate.NewReconciler(ctx, controller.client, controller.apiReader, controller.scheme, controller.eventRecorder(), dynakube, dtc)
 	err := reconciler.Reconcile()
 
 	if err != nil {
 		return errors.WithMessage(err, "failed to reconcile ActiveGate")
 	}
-	controller.setupAutomaticApiMonitoring(dynakube, dtc)
+	if dynakube.Status.ActiveGateAuthTokenID != "" {
+		if err = controller.addFinalizer(ctx, dynakube, activeGateAuthTokenFinalizer); err != nil {
+			return errors.WithMessage(err, "could not add finalizer for the activegate auth token")
+		}
+	}
+	if err = controller.removeForceResyncAnnotation(ctx, dynakube); err != nil {
+		return err
+	}
+	controller.updateStatefulSetCondition(ctx, dynakube)
+	controller.updateImagePullCondition(ctx, dynakube)
+	controller.setupAutomaticApiMonitoring(ctx, dynakube, dtc)
 
 	return nil
 }
 
-func (controller *DynakubeController) setupAutomaticApiMonitoring(dynakube *dynatracev1beta1.DynaKube, dtc dtclient.Client) {
+// removeForceResyncAnnotation removes the force resync annotation once all StatefulSets were applied, so they are only forced once
+func (controller *DynakubeController) removeForceResyncAnnotation(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	return controller.removeOneShotAnnotation(ctx, dynakube, dynatracev1beta1.AnnotationForceResync)
+}
+
+// removeRefreshImageAnnotation removes the refresh image annotation once the image versions were probed, so they are only refreshed once
+func (controller *DynakubeController) removeRefreshImageAnnotation(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
+	return controller.removeOneShotAnnotation(ctx, dynakube, dynatracev1beta1.AnnotationRefreshImage)
+}
+
+func (controller *DynakubeController) removeOneShotAnnotation(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, annotation string) error {
+	if _, ok := dynakube.Annotations[annotation]; !ok {
+		return nil
+	}
+
+	// update a copy, so the status changes of the current reconciliation are not overwritten by the response
+	updatedDynakube := dynakube.DeepCopy()
+	delete(updatedDynakube.Annotations, annotation)
+	err := controller.client.Update(ctx, updatedDynakube)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to remove the %s annotation", annotation)
+	}
+
+	logger.FromContext(ctx, log).Info("removed one-shot annotation", "annotation", annotation)
+	dynakube.Annotations = updatedDynakube.Annotations
+	dynakube.ResourceVersion = updatedDynakube.ResourceVersion
+	return nil
+}
+
+// checkApiConnection verifies that the Dynatrace API can be reached before anything is deployed,
+// the outcome is reported by the APIReachable condition
+func (controller *DynakubeController) checkApiConnection(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, dynatraceClientBuilder dynatraceclient.Builder) error {
+	dynatraceClient, err := dynatraceClientBuilder.Build()
+	if err != nil {
+		controller.setConditionTokenError(ctx, dynakube, err)
+		return err
+	}
+
+	err = dynatraceClient.CheckConnection()
+	if err != nil {
+		controller.setConditionApiUnreachable(ctx, dynakube, err)
+		return errors.WithMessage(err, "could not connect to the Dynatrace API")
+	}
+
+	controller.setConditionApiReachable(dynakube)
+	return nil
+}
+
+func (controller *DynakubeController) setupAutomaticApiMonitoring(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, dtc dtclient.Client) {
 	if dynakube.Status.KubeSystemUUID != "" &&
 		dynakube.FeatureAutomaticKubernetesApiMonitoring() &&
 		dynakube.IsKubernetesMonitoringActiveGateEnabled() {
 
-		clusterLabel := dynakube.FeatureAutomaticKubernetesApiMonitoringClusterName()
-		if clusterLabel == "" {
-			clusterLabel = dynakube.Name
+		clusterLabel := controller.automaticApiMonitoringClusterLabel(ctx, dynakube)
+
+		objectID, err := apimonitoring.NewReconciler(dtc, clusterLabel, dynakube.Status.KubeSystemUUID, dynakube.Status.KubernetesSettingObjectID).
+			Reconcile(ctx)
+		controller.handleApiMonitoringResult(dynakube, clusterLabel, objectID, err)
+		if err != nil {
+			logger.FromContext(ctx, log).Error(err, "could not create setting")
+			return
+		}
+
+		if objectID != "" {
+			dynakube.Status.KubernetesSettingObjectID = objectID
+		}
+
+		if dynakube.Status.KubernetesSettingObjectID != "" {
+			err = controller.addFinalizer(ctx, dynakube, kubernetesSettingFinalizer)
+			if err != nil {
+				logger.FromContext(ctx, log).Error(err, "could not add finalizer for the kubernetes setting")
+			}
+		}
+	}
+}
+
+// handleApiMonitoringResult records the outcome of the registration in the status,
+// the timestamp only changes with the outcome, so a steady state doesn't result in status updates.
+// A new error is also sent as a warning event, so a failed registration shows up next to the DynaKube.
+func (controller *DynakubeController) handleApiMonitoringResult(dynakube *dynatracev1beta1.DynaKube, clusterLabel, objectID string, err error) {
+	newStatus := *dynakube.Status.KubernetesMonitoring.DeepCopy()
+	if err != nil {
+		newStatus.LastError = err.Error()
+	} else {
+		newStatus.LastError = ""
+		if objectID != "" {
+			newStatus.RegisteredClusterLabel = clusterLabel
 		}
+	}
+
+	oldStatus := dynakube.Status.KubernetesMonitoring
+	if newStatus.LastError == oldStatus.LastError && newStatus.RegisteredClusterLabel == oldStatus.RegisteredClusterLabel && oldStatus.LastTransitionTimestamp != nil {
+		return
+	}
+
+	now := controller.now()
+	newStatus.LastTransitionTimestamp = &now
+	dynakube.Status.KubernetesMonitoring = newStatus
 
-		err := apimonitoring.NewReconciler(dtc, clusterLabel, dynakube.Status.KubeSystemUUID).
-			Reconcile()
+	if err != nil {
+		controller.sendApiMonitoringRegistrationFailedEvent(dynakube, clusterLabel, err)
+	}
+}
+
+// automaticApiMonitoringClusterLabel returns the cluster label, optionally followed by the state of the ActiveGate.
+// The base label is kept as is, so the cluster can still be identified by it.
+func (controller *DynakubeController) automaticApiMonitoringClusterLabel(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) string {
+	clusterLabel := dynakube.FeatureAutomaticKubernetesApiMonitoringClusterName()
+	if clusterLabel == "" {
+		clusterLabel = dynakube.Name
+	}
+
+	if !dynakube.FeatureAutomaticKubernetesApiMonitoringStatusSuffix() {
+		return clusterLabel
+	}
+
+	readyReplicas, desiredReplicas, err := controller.activeGateReplicas(ctx, dynakube)
+	if err != nil {
+		logger.FromContext(ctx, log).Info("could not determine ActiveGate replicas for the cluster label", "error", err.Error())
+		return clusterLabel
+	}
+
+	suffix := fmt.Sprintf("%d/%d ready", readyReplicas, desiredReplicas)
+	if version := dynakube.Status.ActiveGate.Version; version != "" {
+		suffix = fmt.Sprintf("%s, %s", version, suffix)
+	}
+	return fmt.Sprintf("%s (%s)", clusterLabel, suffix)
+}
+
+func (controller *DynakubeController) activeGateReplicas(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (int32, int32, error) {
+	var readyReplicas, desiredReplicas int32
+
+	for _, activeGateCapability := range capability.GenerateActiveGateCapabilities(dynakube) {
+		activeGateStatefulSet := &appsv1.StatefulSet{}
+		instanceName := capability.CalculateStatefulSetName(activeGateCapability, dynakube.Name)
+		err := controller.client.Get(ctx, types.NamespacedName{Name: instanceName, Namespace: dynakube.Namespace}, activeGateStatefulSet)
+
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
 		if err != nil {
-			log.Error(err, "could not create setting")
+			return 0, 0, errors.WithStack(err)
+		}
+
+		if activeGateStatefulSet.Spec.Replicas != nil {
+			desiredReplicas += *activeGateStatefulSet.Spec.Replicas
+		} else {
+			// the api server defaults to one replica
+			desiredReplicas++
 		}
+		readyReplicas += activeGateStatefulSet.Status.ReadyReplicas
 	}
+
+	return readyReplicas, desiredReplicas, nil
 }
 
+// updateDynakubeStatus writes the status of dynakube, which is fully owned by the operator.
+// On a conflict the status is applied again on top of the latest resource version of the DynaKube.
+// If the conflicts persist, the update is skipped instead of failing the reconcile, the next reconcile writes the status again.
 func (controller *DynakubeController) updateDynakubeStatus(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
-	dynakube.Status.UpdatedTimestamp = metav1.Now()
-	err := controller.client.Status().Update(ctx, dynakube)
-	if err != nil && k8serrors.IsConflict(err) {
-		log.Info("could not update dynakube due to conflict", "name", dynakube.Name)
+	dynakube.Status.UpdatedTimestamp = controller.now()
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := controller.client.Status().Update(ctx, dynakube)
+		if !k8serrors.IsConflict(err) {
+			return err
+		}
+
+		logger.FromContext(ctx, log).Info("could not update dynakube due to conflict, retrying with the latest version")
+		var latest dynatracev1beta1.DynaKube
+		if errGet := controller.apiReader.Get(ctx, client.ObjectKeyFromObject(dynakube), &latest); errGet != nil {
+			return errGet
+		}
+		dynakube.ResourceVersion = latest.ResourceVersion
+		return err
+	})
+	if k8serrors.IsConflict(err) {
+		logger.FromContext(ctx, log).Info("could not update dynakube due to persisting conflicts, skipping the status update")
 		return nil
 	}
 	return errors.WithStack(err)
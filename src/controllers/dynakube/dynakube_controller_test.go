@@ -3,12 +3,17 @@ package dynakube
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dynatraceclient"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/token"
+	dtversion "github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/version"
+	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
@@ -18,6 +23,9 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/version"
 	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -28,7 +36,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -263,7 +276,7 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 		assert.NotNil(t, result)
 
 		var proxySecret corev1.Secret
-		name := capability.BuildProxySecretName()
+		name := capability.BuildProxySecretName(testName)
 		err = controller.client.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: testNamespace}, &proxySecret)
 
 		assert.NoError(t, err)
@@ -296,7 +309,7 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 		assert.NotNil(t, result)
 
 		var proxySecret corev1.Secret
-		name := capability.BuildProxySecretName()
+		name := capability.BuildProxySecretName(testName)
 		err = controller.client.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: testNamespace}, &proxySecret)
 
 		assert.Error(t, err)
@@ -627,6 +640,7 @@ func createDTMockClient(paasTokenScopes, apiTokenScopes dtclient.TokenScopes) *d
 	}, nil)
 	mockClient.On("GetTokenScopes", testPaasToken).Return(paasTokenScopes, nil)
 	mockClient.On("GetTokenScopes", testAPIToken).Return(apiTokenScopes, nil)
+	mockClient.On("CheckConnection").Return(nil)
 	mockClient.On("GetOneAgentConnectionInfo").Return(
 		dtclient.OneAgentConnectionInfo{
 			ConnectionInfo: dtclient.ConnectionInfo{
@@ -911,7 +925,7 @@ func TestReconcileIstio(t *testing.T) {
 		client:    fakeClient,
 		apiReader: fakeClient,
 	}
-	updated := controller.reconcileIstio(dynakube)
+	updated := controller.reconcileIstio(context.TODO(), dynakube)
 
 	assert.False(t, updated)
 
@@ -962,11 +976,1042 @@ func TestTokenConditions(t *testing.T) {
 		}.SetScopesForDynakube(*dynakube).ApiToken().RequiredScopes
 
 		mockClient.On("GetTokenScopes", testAPIToken).Return(dtclient.TokenScopes(requiredScopes), nil)
+		mockClient.On("CheckConnection").Return(nil)
+		mockClient.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{}, errors.New("communication hosts not available"))
 
 		_ = controller.reconcileDynaKube(context.TODO(), dynakube)
 
 		assertCondition(t, dynakube, dynatracev1beta1.TokenConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonTokenReady, "")
 	})
+	t.Run("token condition lists missing scopes", func(t *testing.T) {
+		dynakube := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+		}
+		fakeClient := fake.NewClient(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{
+				dtclient.DynatraceApiToken: []byte(testAPIToken),
+			},
+		})
+		controller := &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+			dynatraceClientBuilder: &dynatraceclient.StubBuilder{
+				Err: token.NewMissingScopesError("token 'apiToken' is missing the following scopes: [ DataExport ]"),
+			},
+		}
+
+		err := controller.reconcileDynaKube(context.TODO(), dynakube)
+
+		assert.Error(t, err)
+		assertCondition(t, dynakube, dynatracev1beta1.TokenConditionType, metav1.ConditionFalse, dynatracev1beta1.ReasonTokenScopeMissing,
+			"token 'apiToken' is missing the following scopes: [ DataExport ]")
+	})
+}
+
+func TestApiReachableCondition(t *testing.T) {
+	createController := func(mockClient dtclient.Client) (*DynakubeController, *dynatracev1beta1.DynaKube) {
+		dynakube := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+		}
+		fakeClient := fake.NewClient(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{
+				dtclient.DynatraceApiToken: []byte(testAPIToken),
+			},
+		})
+		return &DynakubeController{
+			client:                 fakeClient,
+			apiReader:              fakeClient,
+			dynatraceClientBuilder: &dynatraceclient.StubBuilder{DynatraceClient: mockClient},
+		}, dynakube
+	}
+
+	t.Run("unreachable api stops the reconcile before the tokens are verified", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("CheckConnection").Return(dtclient.ServerError{Code: http.StatusUnauthorized, Message: "invalid token"})
+		controller, dynakube := createController(mockClient)
+
+		err := controller.reconcileDynaKube(context.TODO(), dynakube)
+
+		require.Error(t, err)
+		assertCondition(t, dynakube, dynatracev1beta1.APIReachableConditionType, metav1.ConditionFalse, dynatracev1beta1.ReasonAPIUnreachable,
+			"dynatrace server error 401: invalid token")
+		mockClient.AssertNotCalled(t, "GetTokenScopes", testAPIToken)
+	})
+	t.Run("reachable api", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("CheckConnection").Return(nil)
+		mockClient.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{}, errors.New("communication hosts not available"))
+		controller, dynakube := createController(mockClient)
+
+		_ = controller.reconcileDynaKube(context.TODO(), dynakube)
+
+		assertCondition(t, dynakube, dynatracev1beta1.APIReachableConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonAPIReachable, "")
+	})
+}
+
+func TestRemoveForceResyncAnnotation(t *testing.T) {
+	t.Run("annotation is removed once", func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationForceResync: "true",
+					"other":                                "annotation",
+				},
+			},
+		}
+		fakeClient := fake.NewClient(instance)
+		controller := &DynakubeController{client: fakeClient, apiReader: fakeClient}
+		dynakube := &dynatracev1beta1.DynaKube{}
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, dynakube))
+		dynakube.Status.Phase = dynatracev1beta1.Running
+
+		require.NoError(t, controller.removeForceResyncAnnotation(context.TODO(), dynakube))
+
+		assert.False(t, dynakube.IsForceResyncRequested())
+		assert.Equal(t, dynatracev1beta1.Running, dynakube.Status.Phase)
+
+		var updatedDynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &updatedDynakube))
+		assert.NotContains(t, updatedDynakube.Annotations, dynatracev1beta1.AnnotationForceResync)
+		assert.Equal(t, "annotation", updatedDynakube.Annotations["other"])
+		assert.Equal(t, updatedDynakube.ResourceVersion, dynakube.ResourceVersion)
+	})
+	t.Run("dynakube is not updated without annotation", func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+		}
+		fakeClient := fake.NewClient(instance)
+		controller := &DynakubeController{client: fakeClient, apiReader: fakeClient}
+		dynakube := &dynatracev1beta1.DynaKube{}
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, dynakube))
+		resourceVersion := dynakube.ResourceVersion
+
+		require.NoError(t, controller.removeForceResyncAnnotation(context.TODO(), dynakube))
+
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, dynakube))
+		assert.Equal(t, resourceVersion, dynakube.ResourceVersion)
+	})
+}
+
+func TestRemoveRefreshImageAnnotation(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationRefreshImage: "true",
+			},
+		},
+	}
+	fakeClient := fake.NewClient(instance)
+	controller := &DynakubeController{client: fakeClient, apiReader: fakeClient}
+	dynakube := &dynatracev1beta1.DynaKube{}
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, dynakube))
+
+	require.NoError(t, controller.removeRefreshImageAnnotation(context.TODO(), dynakube))
+
+	assert.False(t, dynakube.IsImageRefreshRequested())
+	var updatedDynakube dynatracev1beta1.DynaKube
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &updatedDynakube))
+	assert.NotContains(t, updatedDynakube.Annotations, dynatracev1beta1.AnnotationRefreshImage)
+}
+
+func TestHandleApiMonitoringResult(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2022, time.November, 16, 11, 11, 11, 0, time.UTC))
+	recorder := record.NewFakeRecorder(10)
+	controller := &DynakubeController{clock: fakeClock, recorder: recorder}
+	dynakube := &dynatracev1beta1.DynaKube{}
+
+	controller.handleApiMonitoringResult(dynakube, testName, testObjectID, nil)
+
+	firstTransition := metav1.NewTime(fakeClock.Now())
+	assert.Equal(t, dynatracev1beta1.KubernetesMonitoringStatus{
+		RegisteredClusterLabel:  testName,
+		LastTransitionTimestamp: &firstTransition,
+	}, dynakube.Status.KubernetesMonitoring)
+
+	t.Run("steady state keeps the timestamp", func(t *testing.T) {
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+
+		controller.handleApiMonitoringResult(dynakube, testName, "", nil)
+
+		assert.Equal(t, testName, dynakube.Status.KubernetesMonitoring.RegisteredClusterLabel)
+		assert.Equal(t, &firstTransition, dynakube.Status.KubernetesMonitoring.LastTransitionTimestamp)
+	})
+	t.Run("failed registration is reported", func(t *testing.T) {
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+
+		controller.handleApiMonitoringResult(dynakube, testName, "", errors.New("error creating dynatrace settings object"))
+
+		assert.Equal(t, testName, dynakube.Status.KubernetesMonitoring.RegisteredClusterLabel)
+		assert.Equal(t, "error creating dynatrace settings object", dynakube.Status.KubernetesMonitoring.LastError)
+		assert.True(t, fakeClock.Now().Equal(dynakube.Status.KubernetesMonitoring.LastTransitionTimestamp.Time))
+		require.Len(t, recorder.Events, 1)
+		assert.Contains(t, <-recorder.Events, apiMonitoringRegistrationFailedEvent)
+	})
+	t.Run("repeated failure is reported once", func(t *testing.T) {
+		controller.handleApiMonitoringResult(dynakube, testName, "", errors.New("error creating dynatrace settings object"))
+
+		assert.Empty(t, recorder.Events)
+	})
+	t.Run("error is cleared once the registration succeeds", func(t *testing.T) {
+		controller.handleApiMonitoringResult(dynakube, testName+"-new", testObjectID, nil)
+
+		assert.Equal(t, testName+"-new", dynakube.Status.KubernetesMonitoring.RegisteredClusterLabel)
+		assert.Empty(t, dynakube.Status.KubernetesMonitoring.LastError)
+		assert.Empty(t, recorder.Events)
+	})
+}
+
+func TestAutomaticApiMonitoringClusterLabel(t *testing.T) {
+	createDynakube := func(annotations map[string]string) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        testName,
+				Namespace:   testNamespace,
+				Annotations: annotations,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+						dynatracev1beta1.KubeMonCapability.DisplayName,
+					},
+				},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				ActiveGate: dynatracev1beta1.ActiveGateStatus{
+					VersionStatus: dynatracev1beta1.VersionStatus{Version: testVersion},
+				},
+			},
+		}
+	}
+	createController := func(dynakube *dynatracev1beta1.DynaKube) *DynakubeController {
+		replicas := int32(2)
+		activeGateCapability := capability.GenerateActiveGateCapabilities(dynakube)[0]
+		fakeClient := fake.NewClient(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      capability.CalculateStatefulSetName(activeGateCapability, dynakube.Name),
+				Namespace: testNamespace,
+			},
+			Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status: appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		})
+		return &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+		}
+	}
+
+	t.Run("label without suffix", func(t *testing.T) {
+		dynakube := createDynakube(map[string]string{})
+		controller := createController(dynakube)
+
+		assert.Equal(t, testName, controller.automaticApiMonitoringClusterLabel(context.TODO(), dynakube))
+	})
+	t.Run("custom label without suffix", func(t *testing.T) {
+		dynakube := createDynakube(map[string]string{
+			dynatracev1beta1.AnnotationFeatureAutomaticK8sApiMonitoringClusterName: "custom",
+		})
+		controller := createController(dynakube)
+
+		assert.Equal(t, "custom", controller.automaticApiMonitoringClusterLabel(context.TODO(), dynakube))
+	})
+	t.Run("label with suffix", func(t *testing.T) {
+		dynakube := createDynakube(map[string]string{
+			dynatracev1beta1.AnnotationFeatureAutomaticK8sApiMonitoringClusterName: "custom",
+			dynatracev1beta1.AnnotationFeatureAutomaticK8sApiMonitoringStatus:      "true",
+		})
+		controller := createController(dynakube)
+
+		assert.Equal(t, "custom ("+testVersion+", 1/2 ready)", controller.automaticApiMonitoringClusterLabel(context.TODO(), dynakube))
+	})
+	t.Run("label with suffix without version", func(t *testing.T) {
+		dynakube := createDynakube(map[string]string{
+			dynatracev1beta1.AnnotationFeatureAutomaticK8sApiMonitoringStatus: "true",
+		})
+		dynakube.Status.ActiveGate.Version = ""
+		controller := createController(dynakube)
+
+		assert.Equal(t, testName+" (1/2 ready)", controller.automaticApiMonitoringClusterLabel(context.TODO(), dynakube))
+	})
+}
+
+func TestNewDynaKubeController(t *testing.T) {
+	fakeClient := fake.NewClient()
+
+	t.Run("default collaborators", func(t *testing.T) {
+		controller := NewDynaKubeController(fakeClient, fakeClient, scheme.Scheme, nil)
+
+		assert.Nil(t, controller.imageVersionProvider)
+		assert.NotNil(t, controller.imageVersionCache)
+		assert.Equal(t, clock.RealClock{}, controller.clock)
+		assert.NotNil(t, controller.dynatraceClientBuilder)
+	})
+	t.Run("options replace the collaborators", func(t *testing.T) {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		dtcBuilder := &dynatraceclient.StubBuilder{}
+		controller := NewDynaKubeController(fakeClient, fakeClient, scheme.Scheme, nil,
+			WithImageVersionProvider(func(_ string, _ *dockerconfig.DockerConfig) (dtversion.ImageVersion, error) {
+				return dtversion.ImageVersion{Version: "1.2.3"}, nil
+			}),
+			WithImageVersionCache(nil),
+			WithClock(fakeClock),
+			WithDynatraceClientBuilder(dtcBuilder),
+		)
+
+		imageVersion, err := controller.getImageVersionProvider(context.TODO(), &dynatracev1beta1.DynaKube{})("", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3", imageVersion.Version)
+		assert.Nil(t, controller.imageVersionCache)
+		assert.Equal(t, fakeClock, controller.clock)
+		assert.Equal(t, dtcBuilder, controller.dynatraceClientBuilder)
+	})
+}
+
+func TestImageVersionProvider(t *testing.T) {
+	t.Run("default provider is used and not reported", func(t *testing.T) {
+		dynakube := &dynatracev1beta1.DynaKube{}
+		controller := &DynakubeController{}
+
+		provider := controller.getImageVersionProvider(context.TODO(), dynakube)
+
+		assert.NotNil(t, provider)
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImageVersionProviderConditionType))
+	})
+	t.Run("override is used and reported in status", func(t *testing.T) {
+		dynakube := &dynatracev1beta1.DynaKube{}
+		controller := &DynakubeController{
+			imageVersionProvider: func(_ string, _ *dockerconfig.DockerConfig) (dtversion.ImageVersion, error) {
+				return dtversion.ImageVersion{Version: "1.2.3"}, nil
+			},
+		}
+
+		provider := controller.getImageVersionProvider(context.TODO(), dynakube)
+		imageVersion, err := provider("", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3", imageVersion.Version)
+		assertCondition(t, dynakube, dynatracev1beta1.ImageVersionProviderConditionType, metav1.ConditionTrue,
+			dynatracev1beta1.ReasonImageVersionProviderOverridden, "image versions are not resolved by the default image version provider")
+	})
+	t.Run("condition is removed once the override is gone", func(t *testing.T) {
+		dynakube := &dynatracev1beta1.DynaKube{}
+		controller := &DynakubeController{
+			imageVersionProvider: func(_ string, _ *dockerconfig.DockerConfig) (dtversion.ImageVersion, error) {
+				return dtversion.ImageVersion{}, nil
+			},
+		}
+		controller.getImageVersionProvider(context.TODO(), dynakube)
+		require.NotNil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImageVersionProviderConditionType))
+
+		controller.imageVersionProvider = nil
+		controller.getImageVersionProvider(context.TODO(), dynakube)
+
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImageVersionProviderConditionType))
+	})
+	t.Run("default provider is served by the image version cache", func(t *testing.T) {
+		const testCachedImage = "registry.example.com/dynatrace/activegate:1.2.3"
+		dynakube := &dynatracev1beta1.DynaKube{}
+		cache := dtversion.NewImageVersionCache(time.Hour)
+		cache.Set(testCachedImage, "", dtversion.ImageVersion{Version: "1.2.3", Hash: "cached-hash"})
+		controller := &DynakubeController{
+			imageVersionCache: cache,
+		}
+
+		provider := controller.getImageVersionProvider(context.TODO(), dynakube)
+		imageVersion, err := provider(testCachedImage, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, dtversion.ImageVersion{Version: "1.2.3", Hash: "cached-hash"}, imageVersion)
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImageVersionProviderConditionType))
+	})
+}
+
+func TestLastError(t *testing.T) {
+	t.Run("last error is set if reconciliation fails", func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		controller := &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+		}
+
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+		})
+		require.Error(t, err)
+
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+		require.NotNil(t, dynakube.Status.LastError)
+		assert.Equal(t, err.Error(), dynakube.Status.LastError.Message)
+		assert.False(t, dynakube.Status.LastError.Timestamp.IsZero())
+	})
+	t.Run("last error is cleared once reconciliation succeeds", func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+			dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeActiveGateTokenCreate})
+		mockClient.On("GetActiveGateAuthToken", testName).Return(&dtclient.ActiveGateAuthTokenInfo{}, nil)
+
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				LastError: &dynatracev1beta1.ErrorStatus{
+					Message:   "previous error",
+					Timestamp: metav1.Now(),
+				},
+			}}
+		controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+		})
+		require.NoError(t, err)
+
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+		assert.Nil(t, dynakube.Status.LastError)
+	})
+	t.Run("timestamp is kept while the error doesn't change", func(t *testing.T) {
+		status := dynatracev1beta1.DynaKubeStatus{}
+		firstOccurrence := metav1.NewTime(metav1.Now().Add(-time.Hour))
+
+		status.SetLastError(errors.New(testName), firstOccurrence)
+		status.SetLastError(errors.New(testName), metav1.Now())
+
+		assert.Equal(t, firstOccurrence, status.LastError.Timestamp)
+
+		status.SetLastError(errors.New(testNamespace), metav1.Now())
+
+		assert.Equal(t, testNamespace, status.LastError.Message)
+		assert.NotEqual(t, firstOccurrence, status.LastError.Timestamp)
+	})
+}
+
+func TestClock(t *testing.T) {
+	t.Run("status timestamps are taken from the clock", func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			}}
+		fakeClient := fake.NewClient(instance)
+		fakeTime := time.Date(2022, time.November, 16, 11, 11, 11, 0, time.Local)
+		controller := &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+			clock:     clocktesting.NewFakePassiveClock(fakeTime),
+		}
+
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+		})
+		require.Error(t, err)
+
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+		assert.True(t, fakeTime.Equal(dynakube.Status.UpdatedTimestamp.Time))
+		require.NotNil(t, dynakube.Status.LastError)
+		assert.True(t, fakeTime.Equal(dynakube.Status.LastError.Timestamp.Time))
+	})
+	t.Run("time provider follows the clock", func(t *testing.T) {
+		fakeClock := clocktesting.NewFakePassiveClock(time.Date(2022, time.November, 16, 11, 11, 11, 0, time.Local))
+		controller := &DynakubeController{clock: fakeClock}
+		timeProvider := controller.timeProvider()
+		assert.True(t, fakeClock.Now().Equal(timeProvider.Now().Time))
+
+		fakeClock.SetTime(fakeClock.Now().Add(time.Hour))
+		timeProvider = controller.timeProvider()
+		assert.True(t, fakeClock.Now().Equal(timeProvider.Now().Time))
+	})
+	t.Run("real clock is used by default", func(t *testing.T) {
+		controller := &DynakubeController{}
+		before := metav1.Now()
+
+		now := controller.now()
+
+		assert.False(t, now.Before(&before))
+	})
+}
+
+func TestStatusConditions(t *testing.T) {
+	mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+		dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeActiveGateTokenCreate})
+	mockClient.On("GetActiveGateAuthToken", testName).Return(&dtclient.ActiveGateAuthTokenInfo{}, nil)
+
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+					dynatracev1beta1.KubeMonCapability.DisplayName,
+				},
+			},
+		}}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+	require.NoError(t, controller.client.Delete(context.TODO(), &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: testName + "-activegate", Namespace: testNamespace}}))
+
+	result, err := controller.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, notReadyUpdateInterval, result.RequeueAfter)
+
+	var dynakube dynatracev1beta1.DynaKube
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+	assertCondition(t, &dynakube, dynatracev1beta1.PullSecretConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonPullSecretReconciled, "")
+	assertCondition(t, &dynakube, dynatracev1beta1.StatefulSetConditionType, metav1.ConditionFalse, dynatracev1beta1.ReasonReplicasNotReady, "0/1 replicas ready")
+	assertCondition(t, &dynakube, dynatracev1beta1.ReadyConditionType, metav1.ConditionFalse, dynatracev1beta1.ReasonActiveGateNotReady, "0/1 replicas ready")
+	assert.False(t, dynakube.Status.ActiveGateReady)
+	assert.Equal(t, float64(0), testutil.ToFloat64(activeGateReplicasReadyMetric.WithLabelValues(testNamespace, testName)))
+
+	var activeGateStatefulSet appsv1.StatefulSet
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName + "-activegate", Namespace: testNamespace}, &activeGateStatefulSet))
+	activeGateStatefulSet.Status.ReadyReplicas = 1
+	require.NoError(t, controller.client.Status().Update(context.TODO(), &activeGateStatefulSet))
+
+	controller.updateStatefulSetCondition(context.TODO(), &dynakube)
+
+	assertCondition(t, &dynakube, dynatracev1beta1.StatefulSetConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonReplicasReady, "1/1 replicas ready")
+	assertCondition(t, &dynakube, dynatracev1beta1.ReadyConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonActiveGateReady, "1/1 replicas ready")
+	assert.True(t, dynakube.Status.ActiveGateReady)
+	assert.Equal(t, float64(1), testutil.ToFloat64(activeGateReplicasReadyMetric.WithLabelValues(testNamespace, testName)))
+
+	dynakube.Spec.ActiveGate.Capabilities = nil
+	controller.updateStatefulSetCondition(context.TODO(), &dynakube)
+
+	assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.StatefulSetConditionType))
+	assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ReadyConditionType))
+	assert.False(t, dynakube.Status.ActiveGateReady)
+}
+
+func TestReconcile_Paused(t *testing.T) {
+	mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+		dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeActiveGateTokenCreate})
+	mockClient.On("GetActiveGateAuthToken", testName).Return(&dtclient.ActiveGateAuthTokenInfo{}, nil)
+
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationReconcilePaused: "true",
+			},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+					dynatracev1beta1.KubeMonCapability.DisplayName,
+				},
+			},
+		}}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	}
+
+	var statefulSetBefore appsv1.StatefulSet
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName + "-activegate", Namespace: testNamespace}, &statefulSetBefore))
+
+	result, err := controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, result)
+
+	var dynakube dynatracev1beta1.DynaKube
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+	assertCondition(t, &dynakube, dynatracev1beta1.PausedConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonReconcilePaused,
+		"reconciliation is paused by the "+dynatracev1beta1.AnnotationReconcilePaused+" annotation")
+
+	var statefulSetAfter appsv1.StatefulSet
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName + "-activegate", Namespace: testNamespace}, &statefulSetAfter))
+	assert.Equal(t, statefulSetBefore.ResourceVersion, statefulSetAfter.ResourceVersion)
+
+	var pullSecret corev1.Secret
+	err = controller.client.Get(context.TODO(), client.ObjectKey{Name: dynakube.PullSecret(), Namespace: testNamespace}, &pullSecret)
+	assert.True(t, k8serrors.IsNotFound(err))
+	mockClient.AssertNotCalled(t, "GetActiveGateAuthToken", testName)
+
+	_, err = controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+
+	var dynakubeAfterSecondReconcile dynatracev1beta1.DynaKube
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakubeAfterSecondReconcile))
+	assert.Equal(t, dynakube.ResourceVersion, dynakubeAfterSecondReconcile.ResourceVersion)
+
+	delete(dynakubeAfterSecondReconcile.Annotations, dynatracev1beta1.AnnotationReconcilePaused)
+	require.NoError(t, controller.client.Update(context.TODO(), &dynakubeAfterSecondReconcile))
+
+	_, err = controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+	assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.PausedConditionType))
+	assertCondition(t, &dynakube, dynatracev1beta1.PullSecretConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonPullSecretReconciled, "")
+}
+
+type writeCountingClient struct {
+	client.Client
+	writes int
+}
+
+func (clt *writeCountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	clt.writes++
+	return clt.Client.Create(ctx, obj, opts...)
+}
+
+func (clt *writeCountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	clt.writes++
+	return clt.Client.Update(ctx, obj, opts...)
+}
+
+func (clt *writeCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	clt.writes++
+	return clt.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (clt *writeCountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	clt.writes++
+	return clt.Client.Delete(ctx, obj, opts...)
+}
+
+func TestReconcile_PausedDoesNotWriteObjects(t *testing.T) {
+	mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+		dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeActiveGateTokenCreate})
+	mockClient.On("GetActiveGateAuthToken", testName).Return(&dtclient.ActiveGateAuthTokenInfo{}, nil)
+
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationReconcilePaused: "true",
+			},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+					dynatracev1beta1.KubeMonCapability.DisplayName,
+				},
+				CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+					CustomProperties: &dynatracev1beta1.DynaKubeValueSource{Value: "test-value"},
+				},
+			},
+		}}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+	countingClient := &writeCountingClient{Client: controller.client}
+	controller.client = countingClient
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	}
+
+	// only the status subresource is written while paused, the status writer is not counted
+	_, err := controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	_, err = controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+
+	assert.Zero(t, countingClient.writes)
+}
+
+func TestUpdateInterval(t *testing.T) {
+	assert.Equal(t, defaultUpdateInterval, (&DynakubeController{}).updateInterval())
+	assert.Equal(t, time.Hour, (&DynakubeController{reconcileInterval: time.Hour}).updateInterval())
+}
+
+func TestReconcile_ApiErrorBackoff(t *testing.T) {
+	mockClient := &dtclient.MockDynatraceClient{}
+	mockClient.On("CheckConnection").Return(dtclient.ServerError{Code: http.StatusServiceUnavailable, Message: "unavailable"})
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testHost,
+		},
+	}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	}
+
+	result, err := controller.Reconcile(context.TODO(), request)
+
+	require.NoError(t, err)
+	assertInterval(t, errorUpdateInterval, result.RequeueAfter)
+
+	result, err = controller.Reconcile(context.TODO(), request)
+
+	require.NoError(t, err)
+	assertInterval(t, 2*errorUpdateInterval, result.RequeueAfter)
+
+	var dynakube dynatracev1beta1.DynaKube
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+	assert.Equal(t, dynatracev1beta1.Error, dynakube.Status.Phase)
+	require.NotNil(t, dynakube.Status.LastError)
+
+	t.Run("transport errors back off as well", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("CheckConnection").Return(errors.WithStack(&url.Error{Op: "Get", URL: testHost, Err: context.DeadlineExceeded}))
+		controller := createFakeClientAndReconciler(mockClient, instance.DeepCopy(), testPaasToken, testAPIToken)
+
+		result, err := controller.Reconcile(context.TODO(), request)
+
+		require.NoError(t, err)
+		assertInterval(t, errorUpdateInterval, result.RequeueAfter)
+	})
+	t.Run("failures of a deleted dynakube are forgotten", func(t *testing.T) {
+		require.NoError(t, controller.client.Delete(context.TODO(), &dynakube))
+
+		_, err := controller.Reconcile(context.TODO(), request)
+
+		require.NoError(t, err)
+		assert.NotContains(t, controller.apiErrorBackoff.failures, request.NamespacedName)
+	})
+}
+
+func TestReconcile_ReturnsErrorAfterStatusUpdate(t *testing.T) {
+	mockClient := &dtclient.MockDynatraceClient{}
+	mockClient.On("CheckConnection").Return(errors.New("malformed response"))
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testHost,
+		},
+	}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+
+	_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "malformed response")
+
+	var dynakube dynatracev1beta1.DynaKube
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+	assert.Equal(t, dynatracev1beta1.Error, dynakube.Status.Phase)
+}
+
+func TestReconcile_RecoversFromPanic(t *testing.T) {
+	mockClient := &dtclient.MockDynatraceClient{}
+	mockClient.On("CheckConnection").Run(func(mock.Arguments) { panic("test panic") })
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testHost,
+		},
+	}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	}
+
+	var result reconcile.Result
+	var err error
+	require.NotPanics(t, func() {
+		result, err = controller.Reconcile(context.TODO(), request)
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test panic")
+	assert.Equal(t, reconcile.Result{}, result)
+
+	var dynakube dynatracev1beta1.DynaKube
+	require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+	assertCondition(t, &dynakube, dynatracev1beta1.DegradedConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonReconcilePanicked,
+		"recovered from panic during reconcile: test panic")
+	assert.Equal(t, dynatracev1beta1.Error, dynakube.Status.Phase)
+}
+
+func TestMapTokenSecretToDynakubes(t *testing.T) {
+	controller := &DynakubeController{
+		client: fake.NewClient(
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+				Spec:       dynatracev1beta1.DynaKubeSpec{Tokens: "external-tokens"},
+			},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-tokens", Namespace: testNamespace},
+			},
+		),
+	}
+
+	t.Run("dynakube referencing the secret is enqueued", func(t *testing.T) {
+		requests := controller.mapTokenSecretToDynakubes(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "external-tokens", Namespace: testNamespace}})
+
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: testName, Namespace: testNamespace}}}, requests)
+	})
+	t.Run("secret named after the dynakube is its default token secret", func(t *testing.T) {
+		requests := controller.mapTokenSecretToDynakubes(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "default-tokens", Namespace: testNamespace}})
+
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "default-tokens", Namespace: testNamespace}}}, requests)
+	})
+	t.Run("other secrets are ignored", func(t *testing.T) {
+		assert.Empty(t, controller.mapTokenSecretToDynakubes(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "external-tokens", Namespace: "other"}}))
+		assert.Empty(t, controller.mapTokenSecretToDynakubes(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace}}))
+	})
+}
+
+func TestMapNodeToAutoSizedDynakubes(t *testing.T) {
+	autoSizedDynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+	}
+	autoSizedDynakube.Spec.Routing.AutoSizing = &dynatracev1beta1.AutoSizingSpec{}
+	controller := &DynakubeController{
+		client: fake.NewClient(
+			autoSizedDynakube,
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: metav1.ObjectMeta{Name: "fixed-size", Namespace: testNamespace},
+			},
+		),
+	}
+
+	requests := controller.mapNodeToAutoSizedDynakubes(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: testName, Namespace: testNamespace}}}, requests)
+}
+
+func TestNodeCountChangedPredicate(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	nodePredicate := nodeCountChangedPredicate()
+
+	assert.True(t, nodePredicate.Create(event.CreateEvent{Object: node}))
+	assert.True(t, nodePredicate.Delete(event.DeleteEvent{Object: node}))
+	assert.False(t, nodePredicate.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: node}))
+}
+
+func TestImagePullCondition(t *testing.T) {
+	newPod := func(name, dynakubeName string, waiting *corev1.ContainerStateWaiting) *corev1.Pod {
+		appLabels := kubeobjects.NewAppLabels(kubeobjects.ActiveGateComponentLabel, dynakubeName, "kubemon", "")
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: appLabels.BuildLabels()},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "activegate", State: corev1.ContainerState{Waiting: waiting}},
+				},
+			},
+		}
+	}
+	newDynakube := func() *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.KubeMonCapability.DisplayName},
+				},
+			},
+		}
+	}
+
+	t.Run("failing image pull is reported", func(t *testing.T) {
+		controller := &DynakubeController{
+			client: fake.NewClient(
+				newPod("healthy", testName, nil),
+				newPod("failing", testName, &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}),
+			),
+		}
+		dynakube := newDynakube()
+
+		controller.updateImagePullCondition(context.TODO(), dynakube)
+
+		assertCondition(t, dynakube, dynatracev1beta1.ImagePullFailedConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonImagePullBackOff,
+			"pod failing, container activegate: Back-off pulling image")
+	})
+	t.Run("other waiting reasons and other dynakubes are ignored", func(t *testing.T) {
+		controller := &DynakubeController{
+			client: fake.NewClient(
+				newPod("starting", testName, &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}),
+				newPod("other", "other-dynakube", &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}),
+			),
+		}
+		dynakube := newDynakube()
+
+		controller.updateImagePullCondition(context.TODO(), dynakube)
+
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImagePullFailedConditionType))
+	})
+	t.Run("condition is removed once the pods recover", func(t *testing.T) {
+		clt := fake.NewClient(newPod("failing", testName, &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"}))
+		controller := &DynakubeController{client: clt}
+		dynakube := newDynakube()
+		controller.updateImagePullCondition(context.TODO(), dynakube)
+		assertCondition(t, dynakube, dynatracev1beta1.ImagePullFailedConditionType, metav1.ConditionTrue, dynatracev1beta1.ReasonErrImagePull,
+			"pod failing, container activegate: not found")
+
+		var pod corev1.Pod
+		require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: "failing", Namespace: testNamespace}, &pod))
+		pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+		require.NoError(t, clt.Status().Update(context.TODO(), &pod))
+		controller.updateImagePullCondition(context.TODO(), dynakube)
+
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImagePullFailedConditionType))
+	})
+}
+
+func TestUpdateDynakubeStatus(t *testing.T) {
+	t.Run("conflict is retried with the latest resource version", func(t *testing.T) {
+		fakeClient := fake.NewClient(&dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+		})
+		controller := &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+		}
+
+		var staleDynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &staleDynakube))
+		var latestDynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &latestDynakube))
+		latestDynakube.Annotations = map[string]string{"updated": "true"}
+		require.NoError(t, fakeClient.Update(context.TODO(), &latestDynakube))
+
+		staleDynakube.Status.Phase = dynatracev1beta1.Running
+		err := controller.updateDynakubeStatus(context.TODO(), &staleDynakube)
+
+		require.NoError(t, err)
+		var updatedDynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &updatedDynakube))
+		assert.Equal(t, dynatracev1beta1.Running, updatedDynakube.Status.Phase)
+		assert.Equal(t, updatedDynakube.ResourceVersion, staleDynakube.ResourceVersion)
+	})
+	t.Run("conflict once then success", func(t *testing.T) {
+		fakeClient := fake.NewClient(&dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+		})
+		conflictClient := &conflictingStatusClient{Client: fakeClient, conflicts: 1}
+		controller := &DynakubeController{
+			client:    conflictClient,
+			apiReader: fakeClient,
+		}
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+
+		dynakube.Status.Phase = dynatracev1beta1.Running
+		err := controller.updateDynakubeStatus(context.TODO(), &dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, conflictClient.updates)
+		var updatedDynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &updatedDynakube))
+		assert.Equal(t, dynatracev1beta1.Running, updatedDynakube.Status.Phase)
+	})
+	t.Run("persisting conflicts don't fail the reconcile", func(t *testing.T) {
+		fakeClient := fake.NewClient(&dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+		})
+		conflictClient := &conflictingStatusClient{Client: fakeClient, conflicts: -1}
+		controller := &DynakubeController{
+			client:    conflictClient,
+			apiReader: fakeClient,
+		}
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+
+		err := controller.updateDynakubeStatus(context.TODO(), &dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, retry.DefaultRetry.Steps, conflictClient.updates)
+	})
+}
+
+// conflictingStatusClient fails the first status updates with a conflict, a negative number of conflicts fails all of them
+type conflictingStatusClient struct {
+	client.Client
+	conflicts int
+	updates   int
+}
+
+func (clt *conflictingStatusClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: clt.Client.Status(), client: clt}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	client *conflictingStatusClient
+}
+
+func (writer *conflictingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	writer.client.updates++
+	if writer.client.conflicts < 0 || writer.client.updates <= writer.client.conflicts {
+		return k8serrors.NewConflict(dynatracev1beta1.GroupVersion.WithResource("dynakubes").GroupResource(), obj.GetName(), errors.New("object was modified"))
+	}
+	return writer.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func TestReconcileResultsMetric(t *testing.T) {
+	errorsBefore := testutil.ToFloat64(reconcileResultsMetric.WithLabelValues(outcomeError))
+	successesBefore := testutil.ToFloat64(reconcileResultsMetric.WithLabelValues(outcomeSuccess))
+
+	countReconcileResult(errors.New(testName))
+	countReconcileResult(nil)
+	countReconcileResult(nil)
+
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(reconcileResultsMetric.WithLabelValues(outcomeError)))
+	assert.Equal(t, successesBefore+2, testutil.ToFloat64(reconcileResultsMetric.WithLabelValues(outcomeSuccess)))
+}
+
+func TestReconcileDurationMetric(t *testing.T) {
+	reconcilesBefore := reconcileDurationCount(t, dynakubeReconciler)
+	fakeClient := fake.NewClient()
+	controller := &DynakubeController{
+		client:    fakeClient,
+		apiReader: fakeClient,
+	}
+
+	_ = controller.reconcileDynaKube(context.TODO(), &dynatracev1beta1.DynaKube{})
+
+	assert.Equal(t, reconcilesBefore+1, reconcileDurationCount(t, dynakubeReconciler))
+}
+
+func reconcileDurationCount(t *testing.T, reconciler string) uint64 {
+	metric := &dto.Metric{}
+	err := reconcileDurationMetric.WithLabelValues(reconciler).(prometheus.Histogram).Write(metric)
+	require.NoError(t, err)
+	return metric.GetHistogram().GetSampleCount()
 }
 
 func assertCondition(t *testing.T, dk *dynatracev1beta1.DynaKube, expectedConditionType string, expectedConditionStatus metav1.ConditionStatus, expectedReason string, expectedMessage string) {
@@ -978,3 +2023,47 @@ func assertCondition(t *testing.T, dk *dynatracev1beta1.DynaKube, expectedCondit
 	assert.Equal(t, expectedReason, actualCondition.Reason)
 	assert.Equal(t, expectedMessage, actualCondition.Message)
 }
+
+func TestEvents(t *testing.T) {
+	t.Run("token invalid event is only sent once the tokens become invalid", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		fakeClient := fake.NewClient()
+		dynakube := &dynatracev1beta1.DynaKube{}
+		controller := &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+			recorder:  recorder,
+		}
+
+		_ = controller.reconcileDynaKube(context.TODO(), dynakube)
+		_ = controller.reconcileDynaKube(context.TODO(), dynakube)
+
+		require.Len(t, recorder.Events, 1)
+		assert.Contains(t, <-recorder.Events, tokenInvalidEvent)
+	})
+	t.Run("image version updated event is sent for changed image hashes", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		controller := &DynakubeController{recorder: recorder}
+		dynakube := &dynatracev1beta1.DynaKube{}
+		dynakube.Status.ActiveGate.ImageHash = "old-hash"
+		dynakube.Status.ActiveGate.Version = "1.0.0"
+		oldStatuses := currentVersionStatuses(dynakube)
+
+		dynakube.Status.ActiveGate.ImageHash = "new-hash"
+		dynakube.Status.ActiveGate.Version = "1.1.0"
+		dynakube.Status.OneAgent.ImageHash = "first-hash"
+		controller.sendImageVersionUpdatedEvents(dynakube, oldStatuses)
+
+		require.Len(t, recorder.Events, 1)
+		event := <-recorder.Events
+		assert.Contains(t, event, imageVersionUpdatedEvent)
+		assert.Contains(t, event, "1.0.0 -> 1.1.0")
+	})
+	t.Run("events are discarded without recorder", func(t *testing.T) {
+		controller := &DynakubeController{}
+
+		assert.NotPanics(t, func() {
+			controller.sendTokenInvalidEvent(&dynatracev1beta1.DynaKube{}, errors.New("invalid"))
+		})
+	})
+}
@@ -5,40 +5,41 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	appsv1 "k8s.io/api/apps/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 )
 
-func (controller *DynakubeController) determineDynaKubePhase(dynakube *dynatracev1beta1.DynaKube) dynatracev1beta1.DynaKubePhaseType {
+func (controller *DynakubeController) determineDynaKubePhase(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) dynatracev1beta1.DynaKubePhaseType {
 	if dynakube.NeedsActiveGate() {
-		activeGatePods, err := controller.numberOfMissingActiveGatePods(dynakube)
+		activeGatePods, err := controller.numberOfMissingActiveGatePods(ctx, dynakube)
 		if err != nil {
-			log.Error(err, "activegate statefulset could not be accessed", "dynakube", dynakube.Name)
+			logger.FromContext(ctx, log).Error(err, "activegate statefulset could not be accessed")
 			return dynatracev1beta1.Error
 		}
 		if activeGatePods > 0 {
-			log.Info("activegate statefulset is still deploying", "dynakube", dynakube.Name)
+			logger.FromContext(ctx, log).Info("activegate statefulset is still deploying")
 			return dynatracev1beta1.Deploying
 		}
 		if activeGatePods < 0 {
-			log.Info("activegate statefulset not yet available", "dynakube", dynakube.Name)
+			logger.FromContext(ctx, log).Info("activegate statefulset not yet available")
 			return dynatracev1beta1.Deploying
 		}
 	}
 
 	if dynakube.CloudNativeFullstackMode() || dynakube.ClassicFullStackMode() || dynakube.HostMonitoringMode() {
-		oneAgentPods, err := controller.numberOfMissingOneagentPods(dynakube)
+		oneAgentPods, err := controller.numberOfMissingOneagentPods(ctx, dynakube)
 		if k8serrors.IsNotFound(err) {
-			log.Info("oneagent daemonset not yet available", "dynakube", dynakube.Name)
+			logger.FromContext(ctx, log).Info("oneagent daemonset not yet available")
 			return dynatracev1beta1.Deploying
 		}
 		if err != nil {
-			log.Error(err, "oneagent daemonset could not be accessed", "dynakube", dynakube.Name)
+			logger.FromContext(ctx, log).Error(err, "oneagent daemonset could not be accessed")
 			return dynatracev1beta1.Error
 		}
 		if oneAgentPods > 0 {
-			log.Info("oneagent daemonset is still deploying", "dynakube", dynakube.Name)
+			logger.FromContext(ctx, log).Info("oneagent daemonset is still deploying")
 			return dynatracev1beta1.Deploying
 		}
 	}
@@ -46,10 +47,10 @@ func (controller *DynakubeController) determineDynaKubePhase(dynakube *dynatrace
 	return dynatracev1beta1.Running
 }
 
-func (controller *DynakubeController) numberOfMissingOneagentPods(dynakube *dynatracev1beta1.DynaKube) (int32, error) {
+func (controller *DynakubeController) numberOfMissingOneagentPods(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (int32, error) {
 	oneAgentDaemonSet := &appsv1.DaemonSet{}
 	instanceName := dynakube.OneAgentDaemonsetName()
-	err := controller.client.Get(context.TODO(), types.NamespacedName{Name: instanceName, Namespace: dynakube.Namespace}, oneAgentDaemonSet)
+	err := controller.client.Get(ctx, types.NamespacedName{Name: instanceName, Namespace: dynakube.Namespace}, oneAgentDaemonSet)
 
 	if err != nil {
 		return 0, err
@@ -57,7 +58,7 @@ func (controller *DynakubeController) numberOfMissingOneagentPods(dynakube *dyna
 	return oneAgentDaemonSet.Status.CurrentNumberScheduled - oneAgentDaemonSet.Status.NumberReady, nil
 }
 
-func (controller *DynakubeController) numberOfMissingActiveGatePods(dynakube *dynatracev1beta1.DynaKube) (int32, error) {
+func (controller *DynakubeController) numberOfMissingActiveGatePods(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (int32, error) {
 	capabilities := capability.GenerateActiveGateCapabilities(dynakube)
 
 	sum := int32(0)
@@ -66,7 +67,7 @@ func (controller *DynakubeController) numberOfMissingActiveGatePods(dynakube *dy
 	for _, activeGateCapability := range capabilities {
 		activeGateStatefulSet := &appsv1.StatefulSet{}
 		instanceName := capability.CalculateStatefulSetName(activeGateCapability, dynakube.Name)
-		err := controller.client.Get(context.TODO(), types.NamespacedName{Name: instanceName, Namespace: dynakube.Namespace}, activeGateStatefulSet)
+		err := controller.client.Get(ctx, types.NamespacedName{Name: instanceName, Namespace: dynakube.Namespace}, activeGateStatefulSet)
 
 		if k8serrors.IsNotFound(err) {
 			continue
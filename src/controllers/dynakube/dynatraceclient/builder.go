@@ -9,6 +9,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -18,6 +19,7 @@ const apiTokenProbeDelay = 5 * time.Minute
 
 type Builder interface {
 	SetContext(ctx context.Context) Builder
+	SetTimeout(timeout time.Duration) Builder
 	SetDynakube(dynakube dynatracev1beta1.DynaKube) Builder
 	SetTokens(tokens token.Tokens) Builder
 	Build() (dtclient.Client, error)
@@ -29,11 +31,16 @@ type builder struct {
 	apiReader client.Reader
 	dynakube  dynatracev1beta1.DynaKube
 	tokens    token.Tokens
+	timeout   time.Duration
+
+	// pool caches the built clients, a nil pool builds a new client every time
+	pool *clientPool
 }
 
 func NewBuilder(apiReader client.Reader) Builder {
 	return builder{
 		apiReader: apiReader,
+		pool:      defaultPool,
 	}
 }
 
@@ -42,6 +49,12 @@ func (dynatraceClientBuilder builder) SetContext(ctx context.Context) Builder {
 	return dynatraceClientBuilder
 }
 
+// SetTimeout limits the duration of every request made by the built client, zero means no timeout
+func (dynatraceClientBuilder builder) SetTimeout(timeout time.Duration) Builder {
+	dynatraceClientBuilder.timeout = timeout
+	return dynatraceClientBuilder
+}
+
 func (dynatraceClientBuilder builder) SetDynakube(dynakube dynatracev1beta1.DynaKube) Builder {
 	dynatraceClientBuilder.dynakube = dynakube
 	return dynatraceClientBuilder
@@ -68,12 +81,15 @@ func (dynatraceClientBuilder builder) getTokens() token.Tokens {
 	return dynatraceClientBuilder.tokens
 }
 
-// Build creates a new Dynatrace client using the settings configured on the given instance.
+// Build returns a Dynatrace client using the settings configured on the given instance.
+// Clients are reused as long as the DynaKube, its tokens and settings don't change, requests to the same tenant share a rate limit.
 func (dynatraceClientBuilder builder) Build() (dtclient.Client, error) {
 	namespace := dynatraceClientBuilder.dynakube.Namespace
 	apiReader := dynatraceClientBuilder.apiReader
 
 	opts := newOptions(dynatraceClientBuilder.context())
+	opts.appendContext()
+	opts.appendTimeout(dynatraceClientBuilder.timeout)
 	opts.appendCertCheck(dynatraceClientBuilder.dynakube.Spec.SkipCertCheck)
 	opts.appendNetworkZone(dynatraceClientBuilder.dynakube.Spec.NetworkZone)
 	opts.appendDisableHostsRequests(dynatraceClientBuilder.dynakube.FeatureDisableHostsRequests())
@@ -95,7 +111,25 @@ func (dynatraceClientBuilder builder) Build() (dtclient.Client, error) {
 		paasToken = apiToken
 	}
 
-	return dtclient.NewClient(dynatraceClientBuilder.dynakube.Spec.APIURL, apiToken, paasToken, opts.Opts...)
+	apiURL := dynatraceClientBuilder.dynakube.Spec.APIURL
+	newClient := func(limiter *rate.Limiter) (dtclient.Client, error) {
+		clientOpts := opts.Opts
+		if limiter != nil {
+			clientOpts = append(clientOpts, dtclient.RateLimiter(limiter))
+		}
+		return dtclient.NewClient(apiURL, apiToken, paasToken, clientOpts...)
+	}
+
+	if dynatraceClientBuilder.pool == nil {
+		return newClient(nil)
+	}
+
+	key := clientKey(namespace, dynatraceClientBuilder.dynakube.Name, apiURL, apiToken, paasToken, opts.configuration)
+	dynatraceClient, err := dynatraceClientBuilder.pool.get(key, apiURL, newClient)
+	if err != nil {
+		return nil, err
+	}
+	return dtclient.WithContext(dynatraceClient, dynatraceClientBuilder.context()), nil
 }
 
 func (dynatraceClientBuilder builder) BuildWithTokenVerification(dynaKubeStatus *dynatracev1beta1.DynaKubeStatus) (dtclient.Client, error) {
@@ -145,11 +179,14 @@ func (dynatraceClientBuilder builder) verifyTokenScopes(dynatraceClient dtclient
 
 func lastErrorFromCondition(dynaKubeStatus *dynatracev1beta1.DynaKubeStatus) error {
 	oldCondition := meta.FindStatusCondition(dynaKubeStatus.Conditions, dynatracev1beta1.TokenConditionType)
-	if oldCondition != nil && oldCondition.Reason != dynatracev1beta1.ReasonTokenReady {
-		return errors.New(oldCondition.Message)
+	if oldCondition == nil || oldCondition.Reason == dynatracev1beta1.ReasonTokenReady {
+		return nil
 	}
 
-	return nil
+	if oldCondition.Reason == dynatracev1beta1.ReasonTokenScopeMissing {
+		return token.NewMissingScopesError(oldCondition.Message)
+	}
+	return errors.New(oldCondition.Message)
 }
 
 func isLastApiCallTooRecent(dynaKubeStatus *dynatracev1beta1.DynaKubeStatus) bool {
@@ -42,6 +42,40 @@ func TestBuildDynatraceClient(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, dtc)
 	})
+	t.Run(`BuildDynatraceClient reuses pooled clients`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: testNamespace,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testEndpoint,
+			}}
+		fakeClient := fake.NewClient(instance)
+		pool := newClientPool()
+		dynatraceClientBuilder := builder{
+			apiReader: fakeClient,
+			tokens: map[string]token.Token{
+				dtclient.DynatraceApiToken:  {Value: testValue},
+				dtclient.DynatracePaasToken: {Value: testValueAlternative},
+			},
+			dynakube: *instance,
+			pool:     pool,
+		}
+
+		_, err := dynatraceClientBuilder.Build()
+		assert.NoError(t, err)
+		dtc, err := dynatraceClientBuilder.Build()
+		assert.NoError(t, err)
+		assert.NotNil(t, dtc)
+		assert.Len(t, pool.clients, 1)
+
+		dynatraceClientBuilder.tokens = map[string]token.Token{
+			dtclient.DynatraceApiToken: {Value: testValueAlternative},
+		}
+		_, err = dynatraceClientBuilder.Build()
+		assert.NoError(t, err)
+		assert.Len(t, pool.clients, 2)
+	})
 	t.Run(`BuildDynatraceClient handles nil instance`, func(t *testing.T) {
 		dtc, err := builder{}.Build()
 		assert.Nil(t, dtc)
@@ -129,3 +163,36 @@ func TestBuildDynatraceClient(t *testing.T) {
 		assert.Nil(t, dtc)
 	})
 }
+
+func TestLastErrorFromCondition(t *testing.T) {
+	t.Run("no error if tokens are ready", func(t *testing.T) {
+		status := &dynatracev1beta1.DynaKubeStatus{
+			Conditions: []v1.Condition{
+				{Type: dynatracev1beta1.TokenConditionType, Reason: dynatracev1beta1.ReasonTokenReady},
+			},
+		}
+
+		assert.NoError(t, lastErrorFromCondition(status))
+	})
+	t.Run("missing scopes are kept when using the cached result", func(t *testing.T) {
+		status := &dynatracev1beta1.DynaKubeStatus{
+			Conditions: []v1.Condition{
+				{Type: dynatracev1beta1.TokenConditionType, Reason: dynatracev1beta1.ReasonTokenScopeMissing, Message: testValue},
+			},
+		}
+
+		err := lastErrorFromCondition(status)
+
+		assert.EqualError(t, err, testValue)
+		assert.ErrorAs(t, err, &token.MissingScopesError{})
+	})
+	t.Run("other errors are returned as is", func(t *testing.T) {
+		status := &dynatracev1beta1.DynaKubeStatus{
+			Conditions: []v1.Condition{
+				{Type: dynatracev1beta1.TokenConditionType, Reason: dynatracev1beta1.ReasonTokenError, Message: testValue},
+			},
+		}
+
+		assert.EqualError(t, lastErrorFromCondition(status), testValue)
+	})
+}
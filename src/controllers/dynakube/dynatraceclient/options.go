@@ -2,6 +2,8 @@ package dynatraceclient
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
@@ -14,6 +16,9 @@ import (
 type options struct {
 	ctx  context.Context
 	Opts []dtclient.Option
+
+	// configuration records the values behind Opts, so clients built with the same values can be reused
+	configuration []string
 }
 
 func newOptions(ctx context.Context) *options {
@@ -23,18 +28,32 @@ func newOptions(ctx context.Context) *options {
 	}
 }
 
+func (opts *options) appendContext() {
+	opts.Opts = append(opts.Opts, dtclient.Context(opts.ctx))
+}
+
+func (opts *options) appendTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		opts.Opts = append(opts.Opts, dtclient.Timeout(timeout))
+		opts.configuration = append(opts.configuration, "timeout="+timeout.String())
+	}
+}
+
 func (opts *options) appendNetworkZone(networkZone string) {
 	if networkZone != "" {
 		opts.Opts = append(opts.Opts, dtclient.NetworkZone(networkZone))
+		opts.configuration = append(opts.configuration, "networkZone="+networkZone)
 	}
 }
 
 func (opts *options) appendCertCheck(skipCertCheck bool) {
 	opts.Opts = append(opts.Opts, dtclient.SkipCertificateValidation(skipCertCheck))
+	opts.configuration = append(opts.configuration, "skipCertCheck="+strconv.FormatBool(skipCertCheck))
 }
 
 func (opts *options) appendDisableHostsRequests(disableHostsRequests bool) {
 	opts.Opts = append(opts.Opts, dtclient.DisableHostsRequests(disableHostsRequests))
+	opts.configuration = append(opts.configuration, "disableHostsRequests="+strconv.FormatBool(disableHostsRequests))
 }
 
 func (opts *options) appendProxySettings(apiReader client.Reader, proxyEntry *dynatracev1beta1.DynaKubeProxy, namespace string) error {
@@ -61,8 +80,10 @@ func (opts *options) createProxyOption(apiReader client.Reader, proxyEntry *dyna
 		}
 
 		proxyOption = dtclient.Proxy(proxyURL)
+		opts.configuration = append(opts.configuration, "proxy="+proxyURL)
 	} else if proxyEntry.Value != "" {
 		proxyOption = dtclient.Proxy(proxyEntry.Value)
+		opts.configuration = append(opts.configuration, "proxy="+proxyEntry.Value)
 	}
 
 	return proxyOption, nil
@@ -94,6 +115,7 @@ func (opts *options) appendTrustedCerts(apiReader client.Reader, trustedCerts st
 			return errors.New("failed to extract certificate configmap field: missing field certs")
 		}
 		opts.Opts = append(opts.Opts, dtclient.Certs([]byte(certs.Data[dtclient.CustomCertificatesConfigMapKey])))
+		opts.configuration = append(opts.configuration, "certs="+certs.Data[dtclient.CustomCertificatesConfigMapKey])
 	}
 	return nil
 }
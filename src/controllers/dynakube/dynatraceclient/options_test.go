@@ -3,6 +3,7 @@ package dynatraceclient
 import (
 	"context"
 	"testing"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
@@ -32,6 +33,17 @@ func TestOptions(t *testing.T) {
 
 		assert.NotEmpty(t, opts.Opts)
 	})
+	t.Run(`Test append timeout`, func(t *testing.T) {
+		opts := newOptions(context.Background())
+
+		opts.appendTimeout(0)
+
+		assert.Empty(t, opts.Opts)
+
+		opts.appendTimeout(30 * time.Second)
+
+		assert.NotEmpty(t, opts.Opts)
+	})
 	t.Run(`Test append cert check`, func(t *testing.T) {
 		opts := newOptions(context.Background())
 
@@ -0,0 +1,97 @@
+package dynatraceclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// tenantRequestsPerSecond and tenantRequestBurst limit the requests of all clients talking to the same tenant,
+	// so DynaKubes sharing a tenant don't exhaust its rate limit together
+	tenantRequestsPerSecond = 5
+	tenantRequestBurst      = 20
+
+	// pooledClientIdleTimeout is the time after which an unused client is dropped from the pool,
+	// e.g. because its DynaKube was deleted or its tokens were rotated
+	pooledClientIdleTimeout = time.Hour
+)
+
+var defaultPool = newClientPool()
+
+// clientPool caches the built clients, so the connections to a tenant are reused across reconciliations.
+// A client is cached per DynaKube and configuration, a changed token or setting results in a new client.
+type clientPool struct {
+	mutex    sync.Mutex
+	clients  map[string]*pooledClient
+	limiters map[string]*rate.Limiter
+	now      func() time.Time
+}
+
+type pooledClient struct {
+	client   dtclient.Client
+	lastUsed time.Time
+}
+
+func newClientPool() *clientPool {
+	return &clientPool{
+		clients:  make(map[string]*pooledClient),
+		limiters: make(map[string]*rate.Limiter),
+		now:      time.Now,
+	}
+}
+
+// get returns the cached client for the key, or builds a new one with the rate limiter of the tenant behind apiURL
+func (pool *clientPool) get(key, apiURL string, build func(limiter *rate.Limiter) (dtclient.Client, error)) (dtclient.Client, error) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := pool.now()
+	pool.evictIdleClients(now)
+
+	if cached, ok := pool.clients[key]; ok {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+
+	dynatraceClient, err := build(pool.tenantLimiter(apiURL))
+	if err != nil {
+		return nil, err
+	}
+
+	pool.clients[key] = &pooledClient{client: dynatraceClient, lastUsed: now}
+	return dynatraceClient, nil
+}
+
+func (pool *clientPool) evictIdleClients(now time.Time) {
+	for key, cached := range pool.clients {
+		if now.Sub(cached.lastUsed) > pooledClientIdleTimeout {
+			delete(pool.clients, key)
+		}
+	}
+}
+
+func (pool *clientPool) tenantLimiter(apiURL string) *rate.Limiter {
+	tenant := strings.TrimSuffix(apiURL, "/")
+	limiter, ok := pool.limiters[tenant]
+	if !ok {
+		limiter = rate.NewLimiter(tenantRequestsPerSecond, tenantRequestBurst)
+		pool.limiters[tenant] = limiter
+	}
+	return limiter
+}
+
+// clientKey identifies a client by its DynaKube, tenant, tokens and configuration, the tokens are only kept as part of a hash
+func clientKey(namespace, name, apiURL, apiToken, paasToken string, configuration []string) string {
+	hash := sha256.New()
+	for _, value := range append([]string{namespace, name, apiURL, apiToken, paasToken}, configuration...) {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
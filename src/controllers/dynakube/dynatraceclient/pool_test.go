@@ -0,0 +1,91 @@
+package dynatraceclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+const testOtherEndpoint = "https://test-other-endpoint.com"
+
+func TestClientPool(t *testing.T) {
+	countingBuild := func(builds *int, limiters *[]*rate.Limiter) func(*rate.Limiter) (dtclient.Client, error) {
+		return func(limiter *rate.Limiter) (dtclient.Client, error) {
+			*builds++
+			*limiters = append(*limiters, limiter)
+			return &dtclient.MockDynatraceClient{}, nil
+		}
+	}
+
+	t.Run("client is reused for the same key", func(t *testing.T) {
+		pool := newClientPool()
+		builds := 0
+		var limiters []*rate.Limiter
+
+		first, err := pool.get("key", testEndpoint, countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+		second, err := pool.get("key", testEndpoint, countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, builds)
+	})
+	t.Run("clients of the same tenant share the rate limiter", func(t *testing.T) {
+		pool := newClientPool()
+		builds := 0
+		var limiters []*rate.Limiter
+
+		_, err := pool.get("key", testEndpoint, countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+		_, err = pool.get("other-key", testEndpoint+"/", countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+		_, err = pool.get("other-tenant-key", testOtherEndpoint, countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+
+		require.Len(t, limiters, 3)
+		assert.Same(t, limiters[0], limiters[1])
+		assert.NotSame(t, limiters[0], limiters[2])
+	})
+	t.Run("idle clients are evicted", func(t *testing.T) {
+		pool := newClientPool()
+		now := time.Now()
+		pool.now = func() time.Time { return now }
+		builds := 0
+		var limiters []*rate.Limiter
+
+		_, err := pool.get("key", testEndpoint, countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+
+		now = now.Add(2 * pooledClientIdleTimeout)
+		_, err = pool.get("other-key", testEndpoint, countingBuild(&builds, &limiters))
+		require.NoError(t, err)
+
+		assert.NotContains(t, pool.clients, "key")
+		assert.Contains(t, pool.clients, "other-key")
+	})
+	t.Run("failed builds are not cached", func(t *testing.T) {
+		pool := newClientPool()
+
+		_, err := pool.get("key", testEndpoint, func(*rate.Limiter) (dtclient.Client, error) {
+			return nil, errors.New("invalid url")
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, pool.clients)
+	})
+}
+
+func TestClientKey(t *testing.T) {
+	key := clientKey(testNamespace, testName, testEndpoint, testValue, testValueAlternative, []string{"networkZone=" + testNetworkZone})
+
+	assert.Equal(t, key, clientKey(testNamespace, testName, testEndpoint, testValue, testValueAlternative, []string{"networkZone=" + testNetworkZone}))
+	assert.NotEqual(t, key, clientKey(testNamespace, "other-name", testEndpoint, testValue, testValueAlternative, []string{"networkZone=" + testNetworkZone}))
+	assert.NotEqual(t, key, clientKey(testNamespace, testName, testEndpoint, "rotated-token", testValueAlternative, []string{"networkZone=" + testNetworkZone}))
+	assert.NotEqual(t, key, clientKey(testNamespace, testName, testEndpoint, testValue, testValueAlternative, nil))
+	assert.NotContains(t, key, testValue)
+}
@@ -2,6 +2,7 @@ package dynatraceclient
 
 import (
 	"context"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/token"
@@ -19,6 +20,10 @@ func (stubBuilder StubBuilder) SetContext(context.Context) Builder {
 	return stubBuilder
 }
 
+func (stubBuilder StubBuilder) SetTimeout(time.Duration) Builder {
+	return stubBuilder
+}
+
 func (stubBuilder StubBuilder) SetDynakube(dynatracev1beta1.DynaKube) Builder {
 	return stubBuilder
 }
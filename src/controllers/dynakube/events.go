@@ -0,0 +1,83 @@
+package dynakube
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	tokenInvalidEvent                    = "TokenInvalid"
+	imageVersionUpdatedEvent             = "ImageVersionUpdated"
+	apiMonitoringRegistrationFailedEvent = "ApiMonitoringRegistrationFailed"
+)
+
+// eventRecorder returns the recorder of the controller, the events are discarded if none is set
+func (controller *DynakubeController) eventRecorder() record.EventRecorder {
+	if controller.recorder == nil {
+		return &record.FakeRecorder{}
+	}
+	return controller.recorder
+}
+
+// reportTokenError sets the token condition to the error, the event is only sent once the tokens become invalid
+func (controller *DynakubeController) reportTokenError(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, err error) {
+	wasInvalid := meta.IsStatusConditionFalse(dynakube.Status.Conditions, dynatracev1beta1.TokenConditionType)
+	controller.setConditionTokenError(ctx, dynakube, err)
+	if !wasInvalid {
+		controller.sendTokenInvalidEvent(dynakube, err)
+	}
+}
+
+func (controller *DynakubeController) sendTokenInvalidEvent(dynakube *dynatracev1beta1.DynaKube, err error) {
+	controller.eventRecorder().Eventf(dynakube,
+		corev1.EventTypeWarning,
+		tokenInvalidEvent,
+		"Tokens of secret %s are invalid: %s", dynakube.Tokens(), err.Error())
+}
+
+func (controller *DynakubeController) sendImageVersionUpdatedEvent(dynakube *dynatracev1beta1.DynaKube, component string, oldStatus, newStatus dynatracev1beta1.VersionStatus) {
+	controller.eventRecorder().Eventf(dynakube,
+		corev1.EventTypeNormal,
+		imageVersionUpdatedEvent,
+		"Found update for %s image %s: version %s -> %s", component, newStatus.Image, oldStatus.Version, newStatus.Version)
+}
+
+func (controller *DynakubeController) sendApiMonitoringRegistrationFailedEvent(dynakube *dynatracev1beta1.DynaKube, clusterLabel string, err error) {
+	controller.eventRecorder().Eventf(dynakube,
+		corev1.EventTypeWarning,
+		apiMonitoringRegistrationFailedEvent,
+		"Automatic Kubernetes API monitoring could not be registered for cluster %s: %s", clusterLabel, err.Error())
+}
+
+func versionStatuses(dynakube *dynatracev1beta1.DynaKube) []dynatracev1beta1.VersionStatusNamer {
+	return []dynatracev1beta1.VersionStatusNamer{
+		&dynakube.Status.ActiveGate,
+		&dynakube.Status.OneAgent,
+		&dynakube.Status.ExtensionController,
+		&dynakube.Status.Statsd,
+	}
+}
+
+func currentVersionStatuses(dynakube *dynatracev1beta1.DynaKube) map[string]dynatracev1beta1.VersionStatus {
+	statuses := map[string]dynatracev1beta1.VersionStatus{}
+	for _, versionStatus := range versionStatuses(dynakube) {
+		statuses[versionStatus.Name()] = versionStatus.Status()
+	}
+	return statuses
+}
+
+// sendImageVersionUpdatedEvents sends an event for every component whose image hash changed compared to oldStatuses,
+// the first resolution of an image is not reported as an update
+func (controller *DynakubeController) sendImageVersionUpdatedEvents(dynakube *dynatracev1beta1.DynaKube, oldStatuses map[string]dynatracev1beta1.VersionStatus) {
+	for _, versionStatus := range versionStatuses(dynakube) {
+		oldStatus := oldStatuses[versionStatus.Name()]
+		newStatus := versionStatus.Status()
+		if oldStatus.ImageHash != "" && oldStatus.ImageHash != newStatus.ImageHash {
+			controller.sendImageVersionUpdatedEvent(dynakube, versionStatus.Name(), oldStatus, newStatus)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package dynakube
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/token"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// kubernetesSettingFinalizer makes sure the Kubernetes settings object created for the cluster is removed from the tenant
+	kubernetesSettingFinalizer = "dynatrace.com/kubernetes-setting"
+
+	// activeGateAuthTokenFinalizer makes sure the ActiveGate auth token created for the DynaKube is revoked in the tenant
+	activeGateAuthTokenFinalizer = "dynatrace.com/activegate-auth-token"
+
+	// tenantCleanupTimeout is the time after which the deletion of the DynaKube is no longer blocked by a failing cleanup
+	tenantCleanupTimeout = 5 * time.Minute
+)
+
+// The generated pull secret and custom properties secrets are owned by the DynaKube and removed by the garbage collector,
+// the finalizers only cover resources living in the tenant.
+var tenantFinalizers = []string{kubernetesSettingFinalizer, activeGateAuthTokenFinalizer}
+
+func (controller *DynakubeController) addFinalizer(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, finalizer string) error {
+	if controllerutil.ContainsFinalizer(dynakube, finalizer) {
+		return nil
+	}
+
+	// update a copy, so the status changes of the current reconciliation are not overwritten by the response
+	dynakubeWithFinalizer := dynakube.DeepCopy()
+	controllerutil.AddFinalizer(dynakubeWithFinalizer, finalizer)
+	err := controller.client.Update(ctx, dynakubeWithFinalizer)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	dynakube.Finalizers = dynakubeWithFinalizer.Finalizers
+	dynakube.ResourceVersion = dynakubeWithFinalizer.ResourceVersion
+	return nil
+}
+
+func (controller *DynakubeController) finalize(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (reconcile.Result, error) {
+	if !hasTenantFinalizer(dynakube) {
+		return reconcile.Result{}, nil
+	}
+
+	dynatraceClient, err := controller.buildFinalizerClient(ctx, dynakube)
+	if k8serrors.IsNotFound(err) {
+		// the tokens are removed together with the operator, e.g. when the namespace is deleted, so the tenant can't be reached anymore
+		logger.FromContext(ctx, log).Info("tokens of the dynakube are gone, the tenant is not cleaned up",
+			"objectID", dynakube.Status.KubernetesSettingObjectID, "authTokenID", dynakube.Status.ActiveGateAuthTokenID)
+		return controller.removeTenantFinalizers(ctx, dynakube)
+	}
+
+	if controllerutil.ContainsFinalizer(dynakube, activeGateAuthTokenFinalizer) {
+		// revoking is best effort, the token expires on its own, so it never blocks the deletion
+		if err == nil {
+			revokeActiveGateAuthToken(ctx, dynatraceClient, dynakube.Status.ActiveGateAuthTokenID)
+		}
+		controllerutil.RemoveFinalizer(dynakube, activeGateAuthTokenFinalizer)
+	}
+
+	if !controllerutil.ContainsFinalizer(dynakube, kubernetesSettingFinalizer) {
+		return reconcile.Result{}, errors.WithStack(controller.client.Update(ctx, dynakube))
+	}
+
+	if err == nil {
+		err = deleteKubernetesSetting(ctx, dynatraceClient, dynakube)
+	}
+	if err != nil {
+		if !kubeobjects.NewTimeProvider().IsOutdated(dynakube.DeletionTimestamp, tenantCleanupTimeout) {
+			logger.FromContext(ctx, log).Info("could not delete kubernetes setting, retrying", "error", err.Error())
+			return reconcile.Result{RequeueAfter: errorUpdateInterval}, errors.WithStack(controller.client.Update(ctx, dynakube))
+		}
+		logger.FromContext(ctx, log).Error(err, "could not delete kubernetes setting, it has to be removed manually",
+			"objectID", dynakube.Status.KubernetesSettingObjectID)
+	}
+
+	return controller.removeTenantFinalizers(ctx, dynakube)
+}
+
+func (controller *DynakubeController) removeTenantFinalizers(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (reconcile.Result, error) {
+	for _, finalizer := range tenantFinalizers {
+		controllerutil.RemoveFinalizer(dynakube, finalizer)
+	}
+	return reconcile.Result{}, errors.WithStack(controller.client.Update(ctx, dynakube))
+}
+
+func hasTenantFinalizer(dynakube *dynatracev1beta1.DynaKube) bool {
+	for _, finalizer := range tenantFinalizers {
+		if controllerutil.ContainsFinalizer(dynakube, finalizer) {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteKubernetesSetting(ctx context.Context, dynatraceClient dtclient.Client, dynakube *dynatracev1beta1.DynaKube) error {
+	objectID := dynakube.Status.KubernetesSettingObjectID
+	if objectID == "" {
+		return nil
+	}
+
+	err := dynatraceClient.DeleteKubernetesSetting(objectID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to delete kubernetes setting %s", objectID)
+	}
+	logger.FromContext(ctx, log).Info("deleted kubernetes setting", "objectID", objectID)
+	return nil
+}
+
+// revokeActiveGateAuthToken revokes the token with the given id and only logs failures, as the token expires on its own
+func revokeActiveGateAuthToken(ctx context.Context, dynatraceClient dtclient.Client, authTokenID string) {
+	if authTokenID == "" {
+		return
+	}
+
+	err := dynatraceClient.DeleteActiveGateAuthToken(authTokenID)
+	var serverErr dtclient.ServerError
+	if errors.As(err, &serverErr) && serverErr.Code == http.StatusForbidden {
+		logger.FromContext(ctx, log).Info("api token is not allowed to revoke the activegate auth token, it will expire on its own",
+			"authTokenID", authTokenID, "scope", dtclient.TokenScopeActiveGateTokenWrite)
+	} else if err != nil {
+		logger.FromContext(ctx, log).Info("could not revoke the activegate auth token, it will expire on its own",
+			"authTokenID", authTokenID, "error", err.Error())
+	} else {
+		logger.FromContext(ctx, log).Info("revoked activegate auth token", "authTokenID", authTokenID)
+	}
+}
+
+func (controller *DynakubeController) buildFinalizerClient(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (dtclient.Client, error) {
+	tokens, err := token.NewReader(controller.apiReader, dynakube).ReadTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.dynatraceClientBuilder.
+		SetContext(ctx).
+		SetTimeout(controller.dynatraceApiTimeout).
+		SetDynakube(*dynakube).
+		SetTokens(tokens).
+		Build()
+}
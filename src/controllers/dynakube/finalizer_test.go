@@ -0,0 +1,236 @@
+package dynakube
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dynatraceclient"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAddKubernetesSettingFinalizer(t *testing.T) {
+	mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+		dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeEntitiesRead, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite,
+			dtclient.TokenScopeActiveGateTokenCreate})
+	mockClient.On("GetActiveGateAuthToken", testName).Return(&dtclient.ActiveGateAuthTokenInfo{}, nil)
+
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationFeatureAutomaticK8sApiMonitoring: "true",
+			},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+					dynatracev1beta1.KubeMonCapability.DisplayName,
+				},
+			},
+		}}
+	controller := createFakeClientAndReconciler(mockClient, instance, testPaasToken, testAPIToken)
+
+	_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+	})
+	require.NoError(t, err)
+
+	var dynakube dynatracev1beta1.DynaKube
+	err = controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube)
+	require.NoError(t, err)
+
+	assert.True(t, controllerutil.ContainsFinalizer(&dynakube, kubernetesSettingFinalizer))
+	assert.Equal(t, testObjectID, dynakube.Status.KubernetesSettingObjectID)
+}
+
+const testAuthTokenID = "test-auth-token-id"
+
+func TestFinalize(t *testing.T) {
+	t.Run("kubernetes setting is deleted and finalizer removed", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteKubernetesSetting", testObjectID).Return(nil)
+		dynakube := createDeletedDynakube(time.Now())
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+		assert.False(t, controllerutil.ContainsFinalizer(dynakube, kubernetesSettingFinalizer))
+		mockClient.AssertCalled(t, "DeleteKubernetesSetting", testObjectID)
+	})
+	t.Run("deletion is retried if the api call fails", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteKubernetesSetting", testObjectID).Return(errors.New("api not reachable"))
+		dynakube := createDeletedDynakube(time.Now())
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, errorUpdateInterval, result.RequeueAfter)
+		assert.True(t, controllerutil.ContainsFinalizer(dynakube, kubernetesSettingFinalizer))
+	})
+	t.Run("finalizer is removed once the deletion timed out", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteKubernetesSetting", testObjectID).Return(errors.New("api not reachable"))
+		dynakube := createDeletedDynakube(time.Now().Add(-2 * tenantCleanupTimeout))
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+		assert.False(t, controllerutil.ContainsFinalizer(dynakube, kubernetesSettingFinalizer))
+	})
+	t.Run("nothing is deleted without stored object id", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		dynakube := createDeletedDynakube(time.Now())
+		dynakube.Status.KubernetesSettingObjectID = ""
+		controller := createFinalizingController(mockClient, dynakube)
+
+		_, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.False(t, controllerutil.ContainsFinalizer(dynakube, kubernetesSettingFinalizer))
+		mockClient.AssertNotCalled(t, "DeleteKubernetesSetting", testObjectID)
+	})
+	t.Run("activegate auth token is revoked and finalizers removed", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteKubernetesSetting", testObjectID).Return(nil)
+		mockClient.On("DeleteActiveGateAuthToken", testAuthTokenID).Return(nil)
+		dynakube := createDeletedDynakube(time.Now())
+		controllerutil.AddFinalizer(dynakube, activeGateAuthTokenFinalizer)
+		dynakube.Status.ActiveGateAuthTokenID = testAuthTokenID
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+		assert.Empty(t, dynakube.Finalizers)
+		mockClient.AssertCalled(t, "DeleteKubernetesSetting", testObjectID)
+		mockClient.AssertCalled(t, "DeleteActiveGateAuthToken", testAuthTokenID)
+	})
+	t.Run("missing permission to revoke the activegate auth token doesn't block the deletion", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteActiveGateAuthToken", testAuthTokenID).Return(dtclient.ServerError{Code: http.StatusForbidden, Message: "missing scope"})
+		dynakube := createDeletedDynakube(time.Now())
+		dynakube.Finalizers = []string{activeGateAuthTokenFinalizer}
+		dynakube.Status.ActiveGateAuthTokenID = testAuthTokenID
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+		assert.Empty(t, dynakube.Finalizers)
+		mockClient.AssertNotCalled(t, "DeleteKubernetesSetting", testObjectID)
+	})
+	t.Run("failing revocation of the activegate auth token doesn't block the deletion", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteKubernetesSetting", testObjectID).Return(nil)
+		mockClient.On("DeleteActiveGateAuthToken", testAuthTokenID).Return(errors.New("api not reachable"))
+		dynakube := createDeletedDynakube(time.Now())
+		controllerutil.AddFinalizer(dynakube, activeGateAuthTokenFinalizer)
+		dynakube.Status.ActiveGateAuthTokenID = testAuthTokenID
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+		assert.Empty(t, dynakube.Finalizers)
+		mockClient.AssertNumberOfCalls(t, "DeleteActiveGateAuthToken", 1)
+	})
+	t.Run("activegate auth token finalizer is removed while the kubernetes setting deletion is retried", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		mockClient.On("DeleteKubernetesSetting", testObjectID).Return(errors.New("api not reachable"))
+		mockClient.On("DeleteActiveGateAuthToken", testAuthTokenID).Return(nil)
+		dynakube := createDeletedDynakube(time.Now())
+		controllerutil.AddFinalizer(dynakube, activeGateAuthTokenFinalizer)
+		dynakube.Status.ActiveGateAuthTokenID = testAuthTokenID
+		controller := createFinalizingController(mockClient, dynakube)
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, errorUpdateInterval, result.RequeueAfter)
+		assert.Equal(t, []string{kubernetesSettingFinalizer}, dynakube.Finalizers)
+
+		var persisted dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKeyFromObject(dynakube), &persisted))
+		assert.Equal(t, []string{kubernetesSettingFinalizer}, persisted.Finalizers)
+	})
+	t.Run("finalizers are removed right away if the tokens are gone", func(t *testing.T) {
+		mockClient := &dtclient.MockDynatraceClient{}
+		dynakube := createDeletedDynakube(time.Now())
+		controllerutil.AddFinalizer(dynakube, activeGateAuthTokenFinalizer)
+		dynakube.Status.ActiveGateAuthTokenID = testAuthTokenID
+		fakeClient := fake.NewClient(dynakube)
+		controller := &DynakubeController{
+			client:    fakeClient,
+			apiReader: fakeClient,
+			dynatraceClientBuilder: &dynatraceclient.StubBuilder{
+				DynatraceClient: mockClient,
+			},
+		}
+
+		result, err := controller.finalize(context.TODO(), dynakube)
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+		assert.Empty(t, dynakube.Finalizers)
+		mockClient.AssertNotCalled(t, "DeleteKubernetesSetting", testObjectID)
+		mockClient.AssertNotCalled(t, "DeleteActiveGateAuthToken", testAuthTokenID)
+	})
+}
+
+func createDeletedDynakube(deletionTimestamp time.Time) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              testName,
+			Namespace:         testNamespace,
+			Finalizers:        []string{kubernetesSettingFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: deletionTimestamp},
+		},
+		Status: dynatracev1beta1.DynaKubeStatus{
+			KubernetesSettingObjectID: testObjectID,
+		},
+	}
+}
+
+func createFinalizingController(mockClient dtclient.Client, dynakube *dynatracev1beta1.DynaKube) *DynakubeController {
+	fakeClient := fake.NewClient(dynakube,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Data: map[string][]byte{
+				dtclient.DynatraceApiToken: []byte(testAPIToken),
+			},
+		})
+
+	return &DynakubeController{
+		client:    fakeClient,
+		apiReader: fakeClient,
+		dynatraceClientBuilder: &dynatraceclient.StubBuilder{
+			DynatraceClient: mockClient,
+		},
+	}
+}
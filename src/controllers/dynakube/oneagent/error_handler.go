@@ -1,9 +1,12 @@
 package oneagent
 
 import (
+	"context"
+
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func handlePodListError(err error, listOps []client.ListOption) {
-	log.Error(err, "failed to list pods", "listops", listOps)
+func handlePodListError(ctx context.Context, err error, listOps []client.ListOption) {
+	logger.FromContext(ctx, log).Error(err, "failed to list pods", "listops", listOps)
 }
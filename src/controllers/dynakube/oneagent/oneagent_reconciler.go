@@ -12,6 +12,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -57,7 +58,7 @@ type OneAgentReconciler struct {
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *OneAgentReconciler) Reconcile(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
-	log.Info("reconciling OneAgent")
+	logger.FromContext(ctx, log).Info("reconciling OneAgent")
 
 	err := r.reconcileRollout(ctx, dynakube)
 	if err != nil {
@@ -68,7 +69,7 @@ func (r *OneAgentReconciler) Reconcile(ctx context.Context, dynakube *dynatracev
 	if val := os.Getenv(updateEnvVar); val != "" {
 		x, err := strconv.Atoi(val)
 		if err != nil {
-			log.Info("conversion of ONEAGENT_OPERATOR_UPDATE_INTERVAL failed")
+			logger.FromContext(ctx, log).Info("conversion of ONEAGENT_OPERATOR_UPDATE_INTERVAL failed")
 		} else {
 			updInterval = time.Duration(x) * time.Minute
 		}
@@ -77,24 +78,24 @@ func (r *OneAgentReconciler) Reconcile(ctx context.Context, dynakube *dynatracev
 	now := metav1.Now()
 	if kubeobjects.IsOutdated(dynakube.Status.OneAgent.LastHostsRequestTimestamp, &now, updInterval) {
 		dynakube.Status.OneAgent.LastHostsRequestTimestamp = &now
-		log.Info("updated last host request time stamp")
+		logger.FromContext(ctx, log).Info("updated last host request time stamp")
 
 		err = r.reconcileInstanceStatuses(ctx, dynakube)
 		if err != nil {
 			return err
 		}
-		log.Info("oneagent instance statuses reconciled")
+		logger.FromContext(ctx, log).Info("oneagent instance statuses reconciled")
 	}
 
-	log.Info("reconciled " + r.feature)
+	logger.FromContext(ctx, log).Info("reconciled " + r.feature)
 	return nil
 }
 
 func (r *OneAgentReconciler) reconcileRollout(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
 	// Define a new DaemonSet object
-	dsDesired, err := r.getDesiredDaemonSet(dynakube)
+	dsDesired, err := r.getDesiredDaemonSet(ctx, dynakube)
 	if err != nil {
-		log.Info("failed to get desired daemonset")
+		logger.FromContext(ctx, log).Info("failed to get desired daemonset")
 		return err
 	}
 
@@ -103,13 +104,13 @@ func (r *OneAgentReconciler) reconcileRollout(ctx context.Context, dynakube *dyn
 		return err
 	}
 
-	updated, err := kubeobjects.CreateOrUpdateDaemonSet(r.client, log, dsDesired)
+	updated, err := kubeobjects.CreateOrUpdateDaemonSet(r.client, logger.FromContext(ctx, log), dsDesired)
 	if err != nil {
-		log.Info("failed to roll out new OneAgent DaemonSet")
+		logger.FromContext(ctx, log).Info("failed to roll out new OneAgent DaemonSet")
 		return err
 	}
 	if updated {
-		log.Info("rolled out new OneAgent DaemonSet")
+		logger.FromContext(ctx, log).Info("rolled out new OneAgent DaemonSet")
 		// remove old daemonset with feature in name
 		oldClassicDaemonset := &appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{
@@ -119,17 +120,17 @@ func (r *OneAgentReconciler) reconcileRollout(ctx context.Context, dynakube *dyn
 		}
 		err = r.client.Delete(ctx, oldClassicDaemonset)
 		if err == nil {
-			log.Info("removed oneagent daemonset with feature in name")
+			logger.FromContext(ctx, log).Info("removed oneagent daemonset with feature in name")
 		} else if !k8serrors.IsNotFound(err) {
-			log.Info("failed to remove oneagent daemonset with feature in name")
+			logger.FromContext(ctx, log).Info("failed to remove oneagent daemonset with feature in name")
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *OneAgentReconciler) getDesiredDaemonSet(dynakube *dynatracev1beta1.DynaKube) (*appsv1.DaemonSet, error) {
-	kubeSysUID, err := kubesystem.GetUID(r.apiReader)
+func (r *OneAgentReconciler) getDesiredDaemonSet(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (*appsv1.DaemonSet, error) {
+	kubeSysUID, err := kubesystem.GetUID(ctx, r.apiReader)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -184,7 +185,7 @@ func (r *OneAgentReconciler) newDaemonSetForCR(dynakube *dynatracev1beta1.DynaKu
 func (r *OneAgentReconciler) reconcileInstanceStatuses(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) error {
 	pods, listOpts, err := r.getOneagentPods(ctx, dynakube, r.feature)
 	if err != nil {
-		handlePodListError(err, listOpts)
+		handlePodListError(ctx, err, listOpts)
 	}
 
 	instanceStatuses, err := getInstanceStatuses(pods)
@@ -1,64 +1,63 @@
 package status
 
 import (
+	"context"
+
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Options struct {
-	DtClient  dtclient.Client
-	ApiReader client.Reader
+	DtClient     dtclient.Client
+	ApiReader    client.Reader
+	TimeProvider kubeobjects.TimeProvider
 }
 
-func SetDynakubeStatus(dynakube *dynatracev1beta1.DynaKube, opts Options) error {
+func SetDynakubeStatus(ctx context.Context, dynakube *dynatracev1beta1.DynaKube, opts Options) error {
 	apiReader := opts.ApiReader
 	dtClient := opts.DtClient
 
-	uid, err := kubesystem.GetUID(apiReader)
+	uid, err := kubesystem.GetUID(ctx, apiReader)
 	if err != nil {
-		log.Info("could not get cluster ID")
-		return err
+		// the cluster ID is only needed for correlation, so the previously known ID is kept instead of failing
+		logger.FromContext(ctx, log).Info("could not get cluster ID, continuing without it", "error", err.Error())
+		setKubeSystemUUIDUnavailable(dynakube, err, *opts.TimeProvider.Now())
+		uid = types.UID(dynakube.Status.KubeSystemUUID)
+	} else {
+		meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.KubeSystemUUIDConditionType)
 	}
 
 	communicationHost, err := dtClient.GetCommunicationHostForClient()
 	if err != nil {
-		log.Info("could not get communication hosts")
-		return err
-	}
-
-	connectionInfo, err := dtClient.GetOneAgentConnectionInfo()
-	if err != nil {
-		log.Info("could not get connection info")
+		logger.FromContext(ctx, log).Info("could not get communication hosts")
 		return err
 	}
 
 	latestAgentVersionUnixDefault, err := dtClient.GetLatestAgentVersion(
 		dtclient.OsUnix, dtclient.InstallerTypeDefault)
 	if err != nil {
-		log.Info("could not get agent default unix version")
+		logger.FromContext(ctx, log).Info("could not get agent default unix version")
 		return err
 	}
 
 	latestAgentVersionUnixPaas, err := dtClient.GetLatestAgentVersion(
 		dtclient.OsUnix, dtclient.InstallerTypePaaS)
 	if err != nil {
-		log.Info("could not get agent paas unix version")
+		logger.FromContext(ctx, log).Info("could not get agent paas unix version")
 		return err
 	}
 
 	communicationHostStatus := dynatracev1beta1.CommunicationHostStatus(communicationHost)
 
-	connectionInfoStatus := dynatracev1beta1.ConnectionInfoStatus{
-		CommunicationHosts:              communicationHostsToStatus(connectionInfo.CommunicationHosts),
-		TenantUUID:                      connectionInfo.TenantUUID,
-		FormattedCommunicationEndpoints: connectionInfo.Endpoints,
-	}
-
 	dynakube.Status.KubeSystemUUID = string(uid)
 	dynakube.Status.CommunicationHostForClient = communicationHostStatus
-	dynakube.Status.ConnectionInfo = connectionInfoStatus
 	dynakube.Status.LatestAgentVersionUnixDefault = latestAgentVersionUnixDefault
 	dynakube.Status.LatestAgentVersionUnixPaas = latestAgentVersionUnixPaas
 	dynakube.Status.Tokens = dynakube.Tokens()
@@ -66,12 +65,19 @@ func SetDynakubeStatus(dynakube *dynatracev1beta1.DynaKube, opts Options) error
 	return nil
 }
 
-func communicationHostsToStatus(communicationHosts []dtclient.CommunicationHost) []dynatracev1beta1.CommunicationHostStatus {
-	var communicationHostStatuses []dynatracev1beta1.CommunicationHostStatus
+func setKubeSystemUUIDUnavailable(dynakube *dynatracev1beta1.DynaKube, err error, now metav1.Time) {
+	condition := metav1.Condition{
+		Type:    dynatracev1beta1.KubeSystemUUIDConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonKubeSystemUUIDUnavailable,
+		Message: err.Error(),
+	}
 
-	for _, communicationHost := range communicationHosts {
-		communicationHostStatuses = append(communicationHostStatuses, dynatracev1beta1.CommunicationHostStatus(communicationHost))
+	oldCondition := meta.FindStatusCondition(dynakube.Status.Conditions, condition.Type)
+	if oldCondition != nil && oldCondition.Reason == condition.Reason && oldCondition.Message == condition.Message {
+		return
 	}
 
-	return communicationHostStatuses
+	condition.LastTransitionTime = now
+	meta.SetStatusCondition(&dynakube.Status.Conditions, condition)
 }
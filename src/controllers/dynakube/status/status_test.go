@@ -1,15 +1,20 @@
 package status
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -20,10 +25,6 @@ const (
 	testPort     = uint32(1234)
 	testProtocol = "test-protocol"
 
-	testAnotherHost     = "test-another-host"
-	testAnotherPort     = uint32(5678)
-	testAnotherProtocol = "test-another-protocol"
-
 	testError       = "test-error"
 	testVersion     = "1.217.12345-678910"
 	testVersionPaas = "2.217.12345-678910"
@@ -58,28 +59,10 @@ func TestSetDynakubeStatus(t *testing.T) {
 			Port:     testPort,
 		}, nil)
 
-		dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{
-			CommunicationHosts: []dtclient.CommunicationHost{
-				{
-					Protocol: testProtocol,
-					Host:     testHost,
-					Port:     testPort,
-				},
-				{
-					Protocol: testAnotherProtocol,
-					Host:     testAnotherHost,
-					Port:     testAnotherPort,
-				},
-			},
-			ConnectionInfo: dtclient.ConnectionInfo{
-				TenantUUID: testUUID,
-			},
-		}, nil)
-
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return(testVersionPaas, nil)
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 
 		assert.NoError(t, err)
 		assert.Equal(t, testUUID, instance.Status.KubeSystemUUID)
@@ -87,39 +70,59 @@ func TestSetDynakubeStatus(t *testing.T) {
 		assert.Equal(t, testHost, instance.Status.CommunicationHostForClient.Host)
 		assert.Equal(t, testPort, instance.Status.CommunicationHostForClient.Port)
 		assert.Equal(t, testProtocol, instance.Status.CommunicationHostForClient.Protocol)
-		assert.NotNil(t, instance.Status.ConnectionInfo)
-		assert.Equal(t, testUUID, instance.Status.ConnectionInfo.TenantUUID)
-		assert.NotNil(t, instance.Status.ConnectionInfo.CommunicationHosts)
-		assert.Equal(t, []dynatracev1beta1.CommunicationHostStatus{
-			{
-				Protocol: testProtocol,
-				Host:     testHost,
-				Port:     testPort,
-			},
-			{
-				Protocol: testAnotherProtocol,
-				Host:     testAnotherHost,
-				Port:     testAnotherPort,
-			},
-		}, instance.Status.ConnectionInfo.CommunicationHosts)
 		assert.NotNil(t, instance.Status.LatestAgentVersionUnixDefault)
 		assert.Equal(t, testVersion, instance.Status.LatestAgentVersionUnixDefault)
 		assert.Equal(t, testVersionPaas, instance.Status.LatestAgentVersionUnixPaas)
 	})
-	t.Run(`error querying kube system uid`, func(t *testing.T) {
-		instance := &dynatracev1beta1.DynaKube{}
+	t.Run(`error querying kube system uid keeps last known uid`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				KubeSystemUUID: testUUID,
+			},
+		}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient()
+		now := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		timeProvider := kubeobjects.NewTimeProvider()
+		timeProvider.SetNow(&now)
 		options := Options{
-			DtClient:  dtc,
-			ApiReader: clt,
+			DtClient:     dtc,
+			ApiReader:    clt,
+			TimeProvider: *timeProvider,
 		}
 
-		err := SetDynakubeStatus(instance, options)
-		assert.EqualError(t, err, "namespaces \"kube-system\" not found")
+		dtc.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{
+			Protocol: testProtocol,
+			Host:     testHost,
+			Port:     testPort,
+		}, nil)
+		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
+		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return(testVersionPaas, nil)
+
+		err := SetDynakubeStatus(context.TODO(), instance, options)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testUUID, instance.Status.KubeSystemUUID)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.KubeSystemUUIDConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonKubeSystemUUIDUnavailable, condition.Reason)
+		assert.Equal(t, "namespaces \"kube-system\" not found", condition.Message)
+		assert.Equal(t, now, condition.LastTransitionTime)
 	})
-	t.Run(`error querying communication host for client`, func(t *testing.T) {
-		instance := &dynatracev1beta1.DynaKube{}
+	t.Run(`condition is removed once kube system uid can be read`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   dynatracev1beta1.KubeSystemUUIDConditionType,
+						Status: metav1.ConditionFalse,
+						Reason: dynatracev1beta1.ReasonKubeSystemUUIDUnavailable,
+					},
+				},
+			},
+		}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -132,12 +135,17 @@ func TestSetDynakubeStatus(t *testing.T) {
 			ApiReader: clt,
 		}
 
-		dtc.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{}, fmt.Errorf(testError))
+		dtc.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{}, nil)
+		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
+		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return(testVersionPaas, nil)
 
-		err := SetDynakubeStatus(instance, options)
-		assert.EqualError(t, err, testError)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testUUID, instance.Status.KubeSystemUUID)
+		assert.Nil(t, meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.KubeSystemUUIDConditionType))
 	})
-	t.Run(`error querying connection info`, func(t *testing.T) {
+	t.Run(`error querying communication host for client`, func(t *testing.T) {
 		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
@@ -151,15 +159,9 @@ func TestSetDynakubeStatus(t *testing.T) {
 			ApiReader: clt,
 		}
 
-		dtc.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{
-			Protocol: testProtocol,
-			Host:     testHost,
-			Port:     testPort,
-		}, nil)
-
-		dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{}, fmt.Errorf(testError))
+		dtc.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{}, fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 	t.Run(`error querying latest agent version for unix / default`, func(t *testing.T) {
@@ -182,27 +184,9 @@ func TestSetDynakubeStatus(t *testing.T) {
 			Port:     testPort,
 		}, nil)
 
-		dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{
-			CommunicationHosts: []dtclient.CommunicationHost{
-				{
-					Protocol: testProtocol,
-					Host:     testHost,
-					Port:     testPort,
-				},
-				{
-					Protocol: testAnotherProtocol,
-					Host:     testAnotherHost,
-					Port:     testAnotherPort,
-				},
-			},
-			ConnectionInfo: dtclient.ConnectionInfo{
-				TenantUUID: testUUID,
-			},
-		}, nil)
-
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return("", fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 	t.Run(`error querying latest agent version for unix / paas`, func(t *testing.T) {
@@ -225,28 +209,10 @@ func TestSetDynakubeStatus(t *testing.T) {
 			Port:     testPort,
 		}, nil)
 
-		dtc.On("GetOneAgentConnectionInfo").Return(dtclient.OneAgentConnectionInfo{
-			CommunicationHosts: []dtclient.CommunicationHost{
-				{
-					Protocol: testProtocol,
-					Host:     testHost,
-					Port:     testPort,
-				},
-				{
-					Protocol: testAnotherProtocol,
-					Host:     testAnotherHost,
-					Port:     testAnotherPort,
-				},
-			},
-			ConnectionInfo: dtclient.ConnectionInfo{
-				TenantUUID: testUUID,
-			},
-		}, nil)
-
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return("", fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 }
@@ -34,6 +34,12 @@ func (token Token) setApiTokenScopes(dynakube dynatracev1beta1.DynaKube, hasPaas
 			dtclient.TokenScopeActiveGateTokenCreate)
 	}
 
+	if dynakube.NeedsNetworkZone() {
+		token.RequiredScopes = append(token.RequiredScopes,
+			dtclient.TokenScopeNetworkZonesRead,
+			dtclient.TokenScopeNetworkZonesWrite)
+	}
+
 	return token
 }
 
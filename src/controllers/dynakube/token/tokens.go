@@ -11,6 +11,19 @@ import (
 
 type Tokens map[string]Token
 
+// MissingScopesError is returned if the scopes of the tokens could be read, but some of the required ones are absent
+type MissingScopesError struct {
+	message string
+}
+
+func NewMissingScopesError(message string) MissingScopesError {
+	return MissingScopesError{message: message}
+}
+
+func (missingScopesError MissingScopesError) Error() string {
+	return missingScopesError.message
+}
+
 func (tokens Tokens) ApiToken() Token {
 	return tokens.getToken(dtclient.DynatraceApiToken)
 }
@@ -51,6 +64,7 @@ func (tokens Tokens) SetScopesForDynakube(dynakube dynatracev1beta1.DynaKube) To
 
 func (tokens Tokens) VerifyScopes(dtc dtclient.Client) error {
 	scopeErrors := make([]error, 0)
+	missingScopesErrors := make([]error, 0)
 
 	for tokenType, token := range tokens {
 		if len(token.RequiredScopes) == 0 {
@@ -67,13 +81,17 @@ func (tokens Tokens) VerifyScopes(dtc dtclient.Client) error {
 		missingScopes := token.getMissingScopes(scopes)
 
 		if len(missingScopes) > 0 {
-			scopeErrors = append(scopeErrors,
+			missingScopesErrors = append(missingScopesErrors,
 				errors.New(fmt.Sprintf("token '%s' is missing the following scopes: [ %s ]", tokenType, strings.Join(missingScopes, ", "))))
 		}
 	}
 
 	if len(scopeErrors) > 0 {
-		return concatErrors(scopeErrors)
+		return concatErrors(append(scopeErrors, missingScopesErrors...))
+	}
+
+	if len(missingScopesErrors) > 0 {
+		return NewMissingScopesError(concatErrors(missingScopesErrors).Error())
 	}
 
 	return nil
@@ -78,6 +78,36 @@ func testSetApiTokenScopes(t *testing.T) {
 			},
 			tokens.ApiToken().RequiredScopes)
 	})
+	t.Run("network zone creation", func(t *testing.T) {
+		tokens := Tokens{
+			dtclient.DynatraceApiToken: {},
+		}
+		tokens = tokens.SetScopesForDynakube(dynatracev1beta1.DynaKube{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeatureCreateNetworkZone:   "true",
+					dynatracev1beta1.AnnotationFeatureActiveGateAuthToken: "false",
+				},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				NetworkZone: "zone",
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+						dynatracev1beta1.RoutingCapability.DisplayName,
+					},
+				},
+			},
+		})
+
+		assert.Equal(t,
+			[]string{
+				dtclient.TokenScopeInstallerDownload,
+				dtclient.TokenScopeDataExport,
+				dtclient.TokenScopeNetworkZonesRead,
+				dtclient.TokenScopeNetworkZonesWrite,
+			},
+			tokens.ApiToken().RequiredScopes)
+	})
 }
 
 func testPaasTokenScopes(t *testing.T) {
@@ -142,12 +172,12 @@ func testVerifyTokenScopes(t *testing.T) {
 
 	fakeDynatraceClient.AssertNotCalled(t, "GetTokenScopes", "empty-scopes")
 	assert.NoError(t, validTokens.VerifyScopes(fakeDynatraceClient))
-	assert.EqualError(t,
-		invalidTokens.VerifyScopes(fakeDynatraceClient),
-		"token 'invalid-scopes' is missing the following scopes: [ b, d ]")
-	assert.EqualError(t,
-		apiError.VerifyScopes(fakeDynatraceClient),
-		"test api-error")
+	err := invalidTokens.VerifyScopes(fakeDynatraceClient)
+	assert.EqualError(t, err, "token 'invalid-scopes' is missing the following scopes: [ b, d ]")
+	assert.ErrorAs(t, err, &MissingScopesError{})
+	err = apiError.VerifyScopes(fakeDynatraceClient)
+	assert.EqualError(t, err, "test api-error")
+	assert.False(t, errors.As(err, &MissingScopesError{}))
 
 }
 
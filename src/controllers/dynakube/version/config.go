@@ -1,9 +1,31 @@
 package version
 
 import (
+	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
 )
 
 var (
 	log = logger.Factory.GetLogger("dynakube-version")
+
+	// pullSecretAuthsCache is shared by all DynaKubes, as the pull secrets are distinguished by namespace and name
+	pullSecretAuthsCache = dockerconfig.NewAuthsCache()
+
+	imageVersionFetchDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dynakube",
+		Name:      "image_version_fetch_duration_seconds",
+		Help:      "Duration of image version lookups in the registry",
+	}, []string{"outcome"})
 )
+
+func init() {
+	metrics.Registry.MustRegister(imageVersionFetchDurationMetric)
+}
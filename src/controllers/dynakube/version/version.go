@@ -4,24 +4,30 @@ import (
 	"context"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/Dynatrace/dynatrace-operator/src/version"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	// ProbeThreshold is the minimum time to wait between version upgrades.
-	ProbeThreshold = 15 * time.Minute
+	// ProbeThreshold is the default minimum time to wait between version upgrades.
+	ProbeThreshold = dynatracev1beta1.DefaultImageProbeInterval
 
 	TmpCAPath = "/tmp/dynatrace-operator"
 	TmpCAName = "dynatraceCustomCA.crt"
+
+	// MaxUpdateHistoryLength limits the image updates kept in the status of each component
+	MaxUpdateHistoryLength = 10
 )
 
 // VersionProviderCallback fetches the version for a given image.
@@ -31,26 +37,30 @@ type VersionProviderCallback func(string, *dockerconfig.DockerConfig) (ImageVers
 func ReconcileVersions(
 	ctx context.Context,
 	dynakube *dynatracev1beta1.DynaKube,
+	clt client.Reader,
 	apiReader client.Reader,
 	fs afero.Afero,
 	versionProvider VersionProviderCallback,
 	timeProvider kubeobjects.TimeProvider,
 ) error {
+	probeInterval := dynakube.FeatureImageProbeInterval()
+	refreshRequested := dynakube.IsImageRefreshRequested()
+
 	needsOneAgentUpdate := dynakube.NeedsOneAgent() &&
-		timeProvider.IsOutdated(dynakube.Status.OneAgent.LastUpdateProbeTimestamp, ProbeThreshold) &&
+		(refreshRequested || needsProbe(dynakube.Status.OneAgent.VersionStatus, dynakube.OneAgentImage(), probeInterval, timeProvider)) &&
 		dynakube.ShouldAutoUpdateOneAgent()
 
 	needsActiveGateUpdate := dynakube.NeedsActiveGate() &&
 		!dynakube.FeatureDisableActiveGateUpdates() &&
-		timeProvider.IsOutdated(dynakube.Status.ActiveGate.LastUpdateProbeTimestamp, ProbeThreshold)
+		(refreshRequested || needsProbe(dynakube.Status.ActiveGate.VersionStatus, dynakube.ActiveGateImage(), probeInterval, timeProvider))
 
 	needsEecUpdate := dynakube.IsStatsdActiveGateEnabled() &&
 		!dynakube.FeatureDisableActiveGateUpdates() &&
-		timeProvider.IsOutdated(dynakube.Status.ExtensionController.LastUpdateProbeTimestamp, ProbeThreshold)
+		(refreshRequested || needsProbe(dynakube.Status.ExtensionController.VersionStatus, dynakube.EecImage(), probeInterval, timeProvider))
 
 	needsStatsdUpdate := dynakube.IsStatsdActiveGateEnabled() &&
 		!dynakube.FeatureDisableActiveGateUpdates() &&
-		timeProvider.IsOutdated(dynakube.Status.Statsd.LastUpdateProbeTimestamp, ProbeThreshold)
+		(refreshRequested || needsProbe(dynakube.Status.Statsd.VersionStatus, dynakube.StatsdImage(), probeInterval, timeProvider))
 
 	if !(needsActiveGateUpdate || needsOneAgentUpdate || needsEecUpdate || needsStatsdUpdate) {
 		return nil
@@ -58,16 +68,18 @@ func ReconcileVersions(
 
 	caCertPath := path.Join(TmpCAPath, TmpCAName)
 	dockerConfig := dockerconfig.NewDockerConfig(apiReader, *dynakube)
+	dockerConfig.AuthsCache = pullSecretAuthsCache
+	dockerConfig.CachedClient = clt
 	err := dockerConfig.SetupAuths(ctx)
 	if err != nil {
-		log.Info("failed to set up auths for image version checks")
+		logger.FromContext(ctx, log).Info("failed to set up auths for image version checks")
 		return err
 	}
 	if dynakube.Spec.TrustedCAs != "" {
 		_ = os.MkdirAll(TmpCAPath, 0755)
 		err := dockerConfig.SaveCustomCAs(ctx, fs, caCertPath)
 		if err != nil {
-			log.Info("failed to save CAs locally for image version checks")
+			logger.FromContext(ctx, log).Info("failed to save CAs locally for image version checks")
 			return err
 		}
 		defer func() {
@@ -76,38 +88,80 @@ func ReconcileVersions(
 	}
 
 	now := timeProvider.Now()
+	var failedImages []string
 	if needsActiveGateUpdate {
-		err := updateImageVersion(*now, dynakube.ActiveGateImage(), &dynakube.Status.ActiveGate.VersionStatus, dockerConfig, versionProvider, true)
+		err := updateImageVersion(ctx, *now, dynakube.ActiveGateImage(), &dynakube.Status.ActiveGate.VersionStatus, dockerConfig, versionProvider, true)
 		if err != nil {
-			log.Error(err, "failed to update ActiveGate image version")
+			logger.FromContext(ctx, log).Error(err, "failed to update ActiveGate image version")
+			failedImages = append(failedImages, dynakube.ActiveGateImage())
 		}
 	}
 
 	if needsEecUpdate {
-		err := updateImageVersion(*now, dynakube.EecImage(), &dynakube.Status.ExtensionController.VersionStatus, dockerConfig, versionProvider, true)
+		err := updateImageVersion(ctx, *now, dynakube.EecImage(), &dynakube.Status.ExtensionController.VersionStatus, dockerConfig, versionProvider, true)
 		if err != nil {
-			log.Error(err, "Failed to update Extension Controller image version")
+			logger.FromContext(ctx, log).Error(err, "Failed to update Extension Controller image version")
+			failedImages = append(failedImages, dynakube.EecImage())
 		}
 	}
 
 	if needsStatsdUpdate {
-		err := updateImageVersion(*now, dynakube.StatsdImage(), &dynakube.Status.Statsd.VersionStatus, dockerConfig, versionProvider, true)
+		err := updateImageVersion(ctx, *now, dynakube.StatsdImage(), &dynakube.Status.Statsd.VersionStatus, dockerConfig, versionProvider, true)
 		if err != nil {
-			log.Error(err, "Failed to update StatsD image version")
+			logger.FromContext(ctx, log).Error(err, "Failed to update StatsD image version")
+			failedImages = append(failedImages, dynakube.StatsdImage())
 		}
 	}
 
 	if needsOneAgentUpdate {
-		err := updateImageVersion(*now, dynakube.OneAgentImage(), &dynakube.Status.OneAgent.VersionStatus, dockerConfig, versionProvider, false)
+		err := updateImageVersion(ctx, *now, dynakube.OneAgentImage(), &dynakube.Status.OneAgent.VersionStatus, dockerConfig, versionProvider, false)
 		if err != nil {
-			log.Error(err, "failed to update OneAgent image version")
+			logger.FromContext(ctx, log).Error(err, "failed to update OneAgent image version")
+			failedImages = append(failedImages, dynakube.OneAgentImage())
 		}
 	}
 
+	setImageCondition(dynakube, failedImages, *now)
 	return nil
 }
 
+// needsProbe limits registry lookups to one per probe interval, unless the image itself changed
+func needsProbe(versionStatus dynatracev1beta1.VersionStatus, img string, probeInterval time.Duration, timeProvider kubeobjects.TimeProvider) bool {
+	return versionStatus.ProbedImage != img ||
+		timeProvider.IsOutdated(versionStatus.LastUpdateProbeTimestamp, probeInterval)
+}
+
+func observeImageVersionFetch(duration time.Duration, err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeError
+	}
+	imageVersionFetchDurationMetric.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+func setImageCondition(dynakube *dynatracev1beta1.DynaKube, failedImages []string, now metav1.Time) {
+	condition := metav1.Condition{
+		Type:   dynatracev1beta1.ImageConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: dynatracev1beta1.ReasonImagesResolved,
+	}
+	if len(failedImages) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = dynatracev1beta1.ReasonImageResolutionFailed
+		condition.Message = "could not resolve the version of " + strings.Join(failedImages, ", ")
+	}
+
+	oldCondition := meta.FindStatusCondition(dynakube.Status.Conditions, condition.Type)
+	if oldCondition != nil && oldCondition.Status == condition.Status && oldCondition.Reason == condition.Reason && oldCondition.Message == condition.Message {
+		return
+	}
+
+	condition.LastTransitionTime = now
+	meta.SetStatusCondition(&dynakube.Status.Conditions, condition)
+}
+
 func updateImageVersion(
+	ctx context.Context,
 	now metav1.Time,
 	img string,
 	target *dynatracev1beta1.VersionStatus,
@@ -116,17 +170,39 @@ func updateImageVersion(
 	allowDowngrades bool,
 ) error {
 	target.LastUpdateProbeTimestamp = &now
+	target.ProbedImage = img
+	defer func() {
+		// keep the canonical reference in sync with the hash, regardless of whether the version changed
+		target.Image = pinnedImage(img, target.ImageHash)
+	}()
+
+	digest, isPinned := pinnedDigest(img)
+	if isPinned && target.ImageHash == digest && target.Version != "" {
+		// images referenced by digest are immutable, so the version was already resolved
+		return nil
+	}
 
+	fetchStart := time.Now()
 	ver, err := verProvider(img, dockerCfg)
+	observeImageVersionFetch(time.Since(fetchStart), err)
 	if err != nil {
 		return errors.WithMessage(err, "failed to get image version")
 	}
 
-	if target.Version == ver.Version {
-		return nil
+	if isPinned {
+		ver.Hash = digest
 	}
 
-	if !allowDowngrades && target.Version != "" {
+	if target.Version == ver.Version {
+		if target.ImageHash == ver.Hash || ver.Hash == "" {
+			// fills in the architectures of images resolved before they were recorded
+			if target.ImageHash == ver.Hash {
+				target.ArchitectureHashes = ver.ArchitectureHashes
+			}
+			return nil
+		}
+		// the image was rebuilt under the same version, e.g. a re-pushed tag, so only the digest changes
+	} else if !allowDowngrades && target.Version != "" {
 		if upgrade, err := version.NeedsUpgradeRaw(target.Version, ver.Version); err != nil {
 			return err
 		} else if !upgrade {
@@ -134,11 +210,30 @@ func updateImageVersion(
 		}
 	}
 
-	log.Info("update found",
+	logger.FromContext(ctx, log).Info("update found",
 		"image", img,
 		"oldVersion", target.Version, "newVersion", ver.Version,
 		"oldHash", target.ImageHash, "newHash", ver.Hash)
+	if target.ImageHash != ver.Hash {
+		appendUpdateHistory(target, dynatracev1beta1.ImageUpdate{
+			OldVersion: target.Version,
+			NewVersion: ver.Version,
+			OldHash:    target.ImageHash,
+			NewHash:    ver.Hash,
+			Timestamp:  now,
+		})
+	}
 	target.Version = ver.Version
 	target.ImageHash = ver.Hash
+	target.ArchitectureHashes = ver.ArchitectureHashes
 	return nil
 }
+
+// appendUpdateHistory adds update to the history of target, dropping the oldest entries beyond MaxUpdateHistoryLength
+func appendUpdateHistory(target *dynatracev1beta1.VersionStatus, update dynatracev1beta1.ImageUpdate) {
+	history := append(target.UpdateHistory, update)
+	if len(history) > MaxUpdateHistoryLength {
+		history = history[len(history)-MaxUpdateHistoryLength:]
+	}
+	target.UpdateHistory = history
+}
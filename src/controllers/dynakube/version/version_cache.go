@@ -0,0 +1,123 @@
+package version
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+)
+
+const (
+	// DefaultImageVersionCacheTTL is how long a resolved image version is served from memory before the registry is asked again
+	DefaultImageVersionCacheTTL = 10 * time.Minute
+)
+
+// ImageVersionCache stores resolved image versions, keyed by image and the hash of the docker config used to resolve them
+type ImageVersionCache interface {
+	Get(img string, dockerConfigHash string) (ImageVersion, bool)
+	Set(img string, dockerConfigHash string, imageVersion ImageVersion)
+}
+
+type cachedImageVersion struct {
+	dockerConfigHash string
+	imageVersion     ImageVersion
+	expiresAt        time.Time
+}
+
+type ttlImageVersionCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedImageVersion
+	now     func() time.Time
+}
+
+var _ ImageVersionCache = &ttlImageVersionCache{}
+
+// NewImageVersionCache returns an ImageVersionCache whose entries expire after ttl
+func NewImageVersionCache(ttl time.Duration) ImageVersionCache {
+	return &ttlImageVersionCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedImageVersion),
+		now:     time.Now,
+	}
+}
+
+func (cache *ttlImageVersionCache) Get(img string, dockerConfigHash string) (ImageVersion, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[img]
+	if !ok {
+		return ImageVersion{}, false
+	}
+
+	if entry.dockerConfigHash != dockerConfigHash || !cache.now().Before(entry.expiresAt) {
+		// the pull secret (or proxy, CAs) changed or the entry is too old, so the version has to be resolved again
+		delete(cache.entries, img)
+		return ImageVersion{}, false
+	}
+	return entry.imageVersion, true
+}
+
+func (cache *ttlImageVersionCache) Set(img string, dockerConfigHash string, imageVersion ImageVersion) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries[img] = cachedImageVersion{
+		dockerConfigHash: dockerConfigHash,
+		imageVersion:     imageVersion,
+		expiresAt:        cache.now().Add(cache.ttl),
+	}
+}
+
+// CachedImageVersionProvider wraps provider, so repeated lookups of the same image with the same docker config are served by cache
+func CachedImageVersionProvider(cache ImageVersionCache, provider VersionProviderCallback) VersionProviderCallback {
+	return cachedImageVersionProvider(cache, provider, true)
+}
+
+// RefreshingImageVersionProvider wraps provider, so every lookup reaches the registry and replaces the entry in cache
+func RefreshingImageVersionProvider(cache ImageVersionCache, provider VersionProviderCallback) VersionProviderCallback {
+	return cachedImageVersionProvider(cache, provider, false)
+}
+
+func cachedImageVersionProvider(cache ImageVersionCache, provider VersionProviderCallback, readCache bool) VersionProviderCallback {
+	return func(img string, dockerConfig *dockerconfig.DockerConfig) (ImageVersion, error) {
+		dockerConfigHash, err := hashDockerConfig(dockerConfig)
+		if err != nil {
+			log.Info("could not hash docker config, skipping image version cache", "image", img)
+			return provider(img, dockerConfig)
+		}
+
+		if imageVersion, ok := cache.Get(img, dockerConfigHash); readCache && ok {
+			return imageVersion, nil
+		}
+
+		imageVersion, err := provider(img, dockerConfig)
+		if err != nil {
+			return imageVersion, err
+		}
+
+		cache.Set(img, dockerConfigHash, imageVersion)
+		return imageVersion, nil
+	}
+}
+
+// hashDockerConfig only includes the fields that affect how the registry is reached, so a rotated pull secret yields a different hash
+func hashDockerConfig(dockerConfig *dockerconfig.DockerConfig) (string, error) {
+	if dockerConfig == nil {
+		return "", nil
+	}
+
+	return kubeobjects.GenerateHash(struct {
+		Auths                 map[string]dockerconfig.DockerAuth
+		TrustedCertsPath      string
+		SkipCertCheck         bool
+		SkipRegistryCertCheck bool
+	}{
+		Auths:                 dockerConfig.Auths,
+		TrustedCertsPath:      dockerConfig.TrustedCertsPath,
+		SkipCertCheck:         dockerConfig.SkipCertCheck(),
+		SkipRegistryCertCheck: skipRegistryCertCheck(dockerConfig),
+	})
+}
@@ -0,0 +1,131 @@
+package version
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCachedImage = testDockerRegistry + "/linux/activegate:1.0.0"
+
+func newTestImageVersionCache(now *time.Time) *ttlImageVersionCache {
+	cache := NewImageVersionCache(DefaultImageVersionCacheTTL).(*ttlImageVersionCache)
+	cache.now = func() time.Time {
+		return *now
+	}
+	return cache
+}
+
+func newCountingImageVersionProvider(calls *int) VersionProviderCallback {
+	return func(img string, _ *dockerconfig.DockerConfig) (ImageVersion, error) {
+		*calls++
+		return ImageVersion{Version: fmt.Sprintf("1.0.%d", *calls), Hash: img}, nil
+	}
+}
+
+func TestCachedImageVersionProvider(t *testing.T) {
+	dockerConfig := func(password string) *dockerconfig.DockerConfig {
+		return &dockerconfig.DockerConfig{
+			Auths: map[string]dockerconfig.DockerAuth{
+				testDockerRegistry: {Username: "user", Password: password},
+			},
+		}
+	}
+
+	t.Run("repeated lookups are served from memory", func(t *testing.T) {
+		now := time.Now()
+		calls := 0
+		provider := CachedImageVersionProvider(newTestImageVersionCache(&now), newCountingImageVersionProvider(&calls))
+
+		first, err := provider(testCachedImage, dockerConfig("password"))
+		require.NoError(t, err)
+		second, err := provider(testCachedImage, dockerConfig("password"))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, first, second)
+	})
+	t.Run("entries expire after the ttl", func(t *testing.T) {
+		now := time.Now()
+		calls := 0
+		provider := CachedImageVersionProvider(newTestImageVersionCache(&now), newCountingImageVersionProvider(&calls))
+
+		_, err := provider(testCachedImage, dockerConfig("password"))
+		require.NoError(t, err)
+
+		now = now.Add(DefaultImageVersionCacheTTL - time.Second)
+		_, err = provider(testCachedImage, dockerConfig("password"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+
+		now = now.Add(time.Second)
+		ver, err := provider(testCachedImage, dockerConfig("password"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, "1.0.2", ver.Version)
+	})
+	t.Run("changed pull secret invalidates the entry", func(t *testing.T) {
+		now := time.Now()
+		calls := 0
+		provider := CachedImageVersionProvider(newTestImageVersionCache(&now), newCountingImageVersionProvider(&calls))
+
+		_, err := provider(testCachedImage, dockerConfig("password"))
+		require.NoError(t, err)
+		_, err = provider(testCachedImage, dockerConfig("rotated"))
+		require.NoError(t, err)
+		_, err = provider(testCachedImage, dockerConfig("rotated"))
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("images are cached separately", func(t *testing.T) {
+		now := time.Now()
+		calls := 0
+		provider := CachedImageVersionProvider(newTestImageVersionCache(&now), newCountingImageVersionProvider(&calls))
+
+		_, err := provider(testCachedImage, nil)
+		require.NoError(t, err)
+		ver, err := provider(testDockerRegistry+"/linux/dynatrace-eec:1.0.0", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, testDockerRegistry+"/linux/dynatrace-eec:1.0.0", ver.Hash)
+	})
+	t.Run("errors are not cached", func(t *testing.T) {
+		now := time.Now()
+		calls := 0
+		provider := CachedImageVersionProvider(newTestImageVersionCache(&now), func(_ string, _ *dockerconfig.DockerConfig) (ImageVersion, error) {
+			calls++
+			return ImageVersion{}, fmt.Errorf("registry unavailable")
+		})
+
+		_, err := provider(testCachedImage, nil)
+		require.Error(t, err)
+		_, err = provider(testCachedImage, nil)
+		require.Error(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestRefreshingImageVersionProvider(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	cache := newTestImageVersionCache(&now)
+	countingProvider := newCountingImageVersionProvider(&calls)
+	_, err := CachedImageVersionProvider(cache, countingProvider)(testCachedImage, nil)
+	require.NoError(t, err)
+
+	refreshed, err := RefreshingImageVersionProvider(cache, countingProvider)(testCachedImage, nil)
+	require.NoError(t, err)
+	cached, err := CachedImageVersionProvider(cache, countingProvider)(testCachedImage, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "1.0.2", refreshed.Version)
+	assert.Equal(t, refreshed, cached)
+}
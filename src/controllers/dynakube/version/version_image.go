@@ -4,23 +4,31 @@ import (
 	"context"
 	"fmt"
 
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
+	"github.com/containers/image/v5/docker/reference"
 	"github.com/containers/image/v5/image"
 	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/transports/alltransports"
 	"github.com/containers/image/v5/types"
+	godigest "github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 )
 
 const (
 	// VersionLabel is the name of the label used on ActiveGate-provided images.
 	VersionLabel = "com.dynatrace.build-version"
+
+	linuxOS = "linux"
 )
 
 // ImageVersion includes information for a given image. Version can be empty if the corresponding label isn't set.
+// ArchitectureHashes is only set for multi-arch images, it maps the architectures of the image index to their hashes.
 type ImageVersion struct {
-	Version string
-	Hash    string
+	Version            string
+	Hash               string
+	ArchitectureHashes map[string]string
 }
 
 // ImageVersionProvider can fetch image information from img
@@ -37,7 +45,7 @@ func GetImageVersion(imageName string, dockerConfig *dockerconfig.DockerConfig)
 		return ImageVersion{}, errors.WithStack(err)
 	}
 
-	systemContext := dockerconfig.MakeSystemContext(imageReference.DockerReference(), dockerConfig)
+	systemContext := makeRegistrySystemContext(imageReference.DockerReference(), dockerConfig)
 
 	imageSource, err := imageReference.NewImageSource(context.TODO(), systemContext)
 	if err != nil {
@@ -45,7 +53,7 @@ func GetImageVersion(imageName string, dockerConfig *dockerconfig.DockerConfig)
 	}
 	defer closeImageSource(imageSource)
 
-	imageManifest, _, err := imageSource.GetManifest(context.TODO(), nil)
+	imageManifest, mimeType, err := imageSource.GetManifest(context.TODO(), nil)
 	if err != nil {
 		return ImageVersion{}, errors.WithStack(err)
 	}
@@ -55,7 +63,22 @@ func GetImageVersion(imageName string, dockerConfig *dockerconfig.DockerConfig)
 		return ImageVersion{}, errors.WithStack(err)
 	}
 
-	sourceImage, err := image.FromUnparsedImage(context.TODO(), systemContext, image.UnparsedInstance(imageSource, nil))
+	// the hash of an image index stays the pinned one, the labels are read from the image for the architecture of the operator
+	var instanceDigest *godigest.Digest
+	var architectureHashes map[string]string
+	if manifest.MIMETypeIsMultiImage(mimeType) {
+		architectureHashes, err = getArchitectureHashes(imageManifest, mimeType)
+		if err != nil {
+			return ImageVersion{}, err
+		}
+
+		instanceDigest, err = chooseInstance(imageManifest, mimeType, systemContext)
+		if err != nil {
+			return ImageVersion{}, err
+		}
+	}
+
+	sourceImage, err := image.FromUnparsedImage(context.TODO(), systemContext, image.UnparsedInstance(imageSource, instanceDigest))
 	if err != nil {
 		return ImageVersion{}, errors.WithStack(err)
 	}
@@ -68,11 +91,102 @@ func GetImageVersion(imageName string, dockerConfig *dockerconfig.DockerConfig)
 	}
 
 	return ImageVersion{
-		Hash:    digest.Encoded(),
-		Version: inspectedImage.Labels[VersionLabel], // empty if unset
+		Hash:               digest.Encoded(),
+		Version:            inspectedImage.Labels[VersionLabel], // empty if unset
+		ArchitectureHashes: architectureHashes,
 	}, nil
 }
 
+// getArchitectureHashes maps the architectures of the linux images listed in a docker manifest list or an OCI image index to their hashes
+func getArchitectureHashes(imageManifest []byte, mimeType string) (map[string]string, error) {
+	architectureHashes := map[string]string{}
+
+	switch manifest.NormalizedMIMEType(mimeType) {
+	case manifest.DockerV2ListMediaType:
+		list, err := manifest.Schema2ListFromManifest(imageManifest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, instance := range list.Manifests {
+			if instance.Platform.OS == linuxOS {
+				architectureHashes[instance.Platform.Architecture] = instance.Digest.Encoded()
+			}
+		}
+	case imgspecv1.MediaTypeImageIndex:
+		index, err := manifest.OCI1IndexFromManifest(imageManifest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, instance := range index.Manifests {
+			// attestations and other artifacts are listed without a platform
+			if instance.Platform != nil && instance.Platform.OS == linuxOS {
+				architectureHashes[instance.Platform.Architecture] = instance.Digest.Encoded()
+			}
+		}
+	default:
+		return nil, errors.Errorf("unsupported image index type: '%s'", mimeType)
+	}
+
+	return architectureHashes, nil
+}
+
+// chooseInstance returns the digest of the image in the index matching the platform of systemContext, which defaults to the one of the operator
+func chooseInstance(imageManifest []byte, mimeType string, systemContext *types.SystemContext) (*godigest.Digest, error) {
+	list, err := manifest.ListFromBlob(imageManifest, mimeType)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	instanceDigest, err := list.ChooseInstance(systemContext)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &instanceDigest, nil
+}
+
+// makeRegistrySystemContext returns the SystemContext used to look up image versions,
+// certificate validation can be skipped for it alone, e.g. for an insecure registry in a dev cluster
+func makeRegistrySystemContext(dockerReference reference.Named, dockerConfig *dockerconfig.DockerConfig) *types.SystemContext {
+	systemContext := dockerconfig.MakeSystemContext(dockerReference, dockerConfig)
+	if skipRegistryCertCheck(dockerConfig) {
+		log.Info("WARNING: certificate validation for the registry is disabled, this is insecure and must not be used in production",
+			"registry", reference.Domain(dockerReference), "featureFlag", dynatracev1beta1.AnnotationFeatureSkipRegistryCertCheck)
+		systemContext.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	}
+	return systemContext
+}
+
+func skipRegistryCertCheck(dockerConfig *dockerconfig.DockerConfig) bool {
+	return dockerConfig != nil && dockerConfig.Dynakube != nil && dockerConfig.Dynakube.FeatureSkipRegistryCertCheck()
+}
+
+// pinnedDigest returns the encoded digest of img, if the image is referenced by digest instead of a tag
+func pinnedDigest(img string) (string, bool) {
+	imageReference, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return "", false
+	}
+
+	canonicalReference, isCanonical := imageReference.(reference.Canonical)
+	if !isCanonical {
+		return "", false
+	}
+	return canonicalReference.Digest().Encoded(), true
+}
+
+// pinnedImage returns the fully-qualified reference of img pinned to the given sha256 hash, e.g. 'docker.io/dynatrace/activegate@sha256:<hash>'
+func pinnedImage(img string, hash string) string {
+	if hash == "" {
+		return ""
+	}
+
+	imageReference, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s@sha256:%s", reference.TrimNamed(imageReference).String(), hash)
+}
+
 func closeImageSource(source types.ImageSource) {
 	if source != nil {
 		// Swallow error
@@ -0,0 +1,59 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/containers/image/v5/manifest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAmd64Digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testArm64Digest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	testOtherDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+
+	testManifestList = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+  "manifests": [
+    {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "size": 1, "digest": "` + testAmd64Digest + `", "platform": {"architecture": "amd64", "os": "linux"}},
+    {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "size": 1, "digest": "` + testArm64Digest + `", "platform": {"architecture": "arm64", "os": "linux"}},
+    {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "size": 1, "digest": "` + testOtherDigest + `", "platform": {"architecture": "amd64", "os": "windows"}}
+  ]
+}`
+
+	testImageIndex = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 1, "digest": "` + testAmd64Digest + `", "platform": {"architecture": "amd64", "os": "linux"}},
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 1, "digest": "` + testOtherDigest + `", "annotations": {"vnd.docker.reference.type": "attestation-manifest"}}
+  ]
+}`
+)
+
+func TestGetArchitectureHashes(t *testing.T) {
+	t.Run("docker manifest list", func(t *testing.T) {
+		architectureHashes, err := getArchitectureHashes([]byte(testManifestList), manifest.DockerV2ListMediaType)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"amd64": "1111111111111111111111111111111111111111111111111111111111111111",
+			"arm64": "2222222222222222222222222222222222222222222222222222222222222222",
+		}, architectureHashes)
+	})
+	t.Run("oci image index skips artifacts without platform", func(t *testing.T) {
+		architectureHashes, err := getArchitectureHashes([]byte(testImageIndex), imgspecv1.MediaTypeImageIndex)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"amd64": "1111111111111111111111111111111111111111111111111111111111111111",
+		}, architectureHashes)
+	})
+	t.Run("single image", func(t *testing.T) {
+		_, err := getArchitectureHashes([]byte(`{}`), manifest.DockerV2Schema2MediaType)
+		assert.Error(t, err)
+	})
+}
@@ -13,10 +13,15 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/dockerconfig"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -33,6 +38,8 @@ const (
 	eecImagePath      = testDockerRegistry + "/linux/dynatrace-eec:latest"
 	statsdImagePath   = testDockerRegistry + "/linux/dynatrace-datasource-statsd:latest"
 	oneAgentImagePath = testDockerRegistry + "/linux/oneagent:latest"
+
+	testImageDigest = "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
 )
 
 func TestReconcile_UpdateImageVersion(t *testing.T) {
@@ -61,7 +68,7 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 		registry := newEmptyFakeRegistry()
 		fs := afero.Afero{Fs: afero.NewMemMapFs()}
 
-		err := ReconcileVersions(ctx, dynakube, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
 		assert.Error(t, err)
 	})
 
@@ -80,16 +87,48 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 			oneAgentImagePath: "1.0.0",
 		})
 
-		err := ReconcileVersions(ctx, dynakube, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
 		assert.NoError(t, err)
 		assertVersionStatusEquals(t, registry, agImagePath, *timeProvider, &dkStatus.ActiveGate)
 		assertVersionStatusEquals(t, registry, oneAgentImagePath, *timeProvider, &dkStatus.OneAgent)
 		assertVersionStatusEquals(t, registry, eecImagePath, *timeProvider, &dkStatus.ExtensionController)
 		assertVersionStatusEquals(t, registry, statsdImagePath, *timeProvider, &dkStatus.Statsd)
 
-		err = ReconcileVersions(ctx, dynakube, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		condition := meta.FindStatusCondition(dkStatus.Conditions, dynatracev1beta1.ImageConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonImagesResolved, condition.Reason)
+
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		assert.NoError(t, err)
+
+	})
+
+	t.Run("image condition lists images that could not be resolved", func(t *testing.T) {
+		dynakube := dynakubeTemplate.DeepCopy()
+		fs := afero.Afero{Fs: afero.NewMemMapFs()}
+		fakeClient := fake.NewClient()
+		timeProvider := kubeobjects.NewTimeProvider()
+		setupPullSecret(t, fakeClient, *dynakube)
+
+		now := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		timeProvider.SetNow(&now)
+
+		registry := newFakeRegistry(map[string]string{
+			agImagePath:       "1.0.0",
+			statsdImagePath:   "1.0.0",
+			oneAgentImagePath: "1.0.0",
+		})
+
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
 		assert.NoError(t, err)
 
+		condition := meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ImageConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonImageResolutionFailed, condition.Reason)
+		assert.Equal(t, "could not resolve the version of "+eecImagePath, condition.Message)
+		assert.Equal(t, now, condition.LastTransitionTime)
 	})
 
 	t.Run("some image versions were updated", func(t *testing.T) {
@@ -107,7 +146,7 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 			oneAgentImagePath: "1.0.0",
 		})
 
-		err := ReconcileVersions(ctx, dynakube, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
 		assert.NoError(t, err)
 
 		assertVersionStatusEquals(t, registry, agImagePath, *timeProvider, &dkStatus.ActiveGate)
@@ -117,7 +156,7 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 
 		registry.SetVersion(eecImagePath, "1.0.1")
 
-		err = ReconcileVersions(ctx, dynakube, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
 		assert.NoError(t, err)
 
 		assertVersionStatusEquals(t, registry, agImagePath, *timeProvider, &dkStatus.ActiveGate)
@@ -128,7 +167,7 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 		assertVersionStatusEquals(t, registry, statsdImagePath, *timeProvider, &dkStatus.Statsd)
 
 		changeTime(t, timeProvider, 15*time.Minute+1*time.Second)
-		err = ReconcileVersions(ctx, dynakube, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
 		assert.NoError(t, err)
 
 		assertVersionStatusEquals(t, registry, agImagePath, *timeProvider, &dkStatus.ActiveGate)
@@ -138,6 +177,342 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 	})
 }
 
+func TestReconcile_ProbeInterval(t *testing.T) {
+	ctx := context.Background()
+	const customImagePath = testDockerRegistry + "/linux/activegate:1.0.0"
+
+	newDynakube := func() *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+						dynatracev1beta1.CapabilityDisplayName(dynatracev1beta1.KubeMonCapability.ShortName),
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("registry is not probed again within interval", func(t *testing.T) {
+		dynakube := newDynakube()
+		fakeClient := fake.NewClient()
+		setupPullSecret(t, fakeClient, *dynakube)
+		fs := afero.Afero{Fs: afero.NewMemMapFs()}
+		timeProvider := kubeobjects.NewTimeProvider()
+		registry := newFakeRegistry(map[string]string{agImagePath: "1.0.0"})
+
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		registry.SetVersion(agImagePath, "1.0.1")
+		changeTime(t, timeProvider, dynatracev1beta1.DefaultImageProbeInterval-time.Second)
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.0.0", dynakube.Status.ActiveGate.Version)
+	})
+	t.Run("registry is probed once the custom interval passed", func(t *testing.T) {
+		dynakube := newDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureImageProbeInterval: "5m"}
+		fakeClient := fake.NewClient()
+		setupPullSecret(t, fakeClient, *dynakube)
+		fs := afero.Afero{Fs: afero.NewMemMapFs()}
+		timeProvider := kubeobjects.NewTimeProvider()
+		registry := newFakeRegistry(map[string]string{agImagePath: "1.0.0"})
+
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		registry.SetVersion(agImagePath, "1.0.1")
+		changeTime(t, timeProvider, 5*time.Minute+time.Second)
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.0.1", dynakube.Status.ActiveGate.Version)
+	})
+	t.Run("changed image is probed within interval", func(t *testing.T) {
+		dynakube := newDynakube()
+		fakeClient := fake.NewClient()
+		setupPullSecret(t, fakeClient, *dynakube)
+		fs := afero.Afero{Fs: afero.NewMemMapFs()}
+		timeProvider := kubeobjects.NewTimeProvider()
+		registry := newFakeRegistry(map[string]string{
+			agImagePath:     "1.0.0",
+			customImagePath: "1.0.0.20221116-111111",
+		})
+
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+		assert.Equal(t, agImagePath, dynakube.Status.ActiveGate.ProbedImage)
+
+		dynakube.Spec.ActiveGate.Image = customImagePath
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.0.0.20221116-111111", dynakube.Status.ActiveGate.Version)
+		assert.Equal(t, customImagePath, dynakube.Status.ActiveGate.ProbedImage)
+	})
+	t.Run("refresh annotation probes within interval", func(t *testing.T) {
+		dynakube := newDynakube()
+		fakeClient := fake.NewClient()
+		setupPullSecret(t, fakeClient, *dynakube)
+		fs := afero.Afero{Fs: afero.NewMemMapFs()}
+		timeProvider := kubeobjects.NewTimeProvider()
+		registry := newFakeRegistry(map[string]string{agImagePath: "1.0.0"})
+
+		err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		registry.SetVersion(agImagePath, "1.0.1")
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationRefreshImage: "true"}
+		err = ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.0.1", dynakube.Status.ActiveGate.Version)
+	})
+}
+
+func TestReconcile_RegistryOverride(t *testing.T) {
+	ctx := context.Background()
+
+	for _, registryOverride := range []string{"mirror.example.com/dynatrace", "mirror.example.com:5000/dynatrace"} {
+		t.Run(registryOverride, func(t *testing.T) {
+			mirroredImagePath := registryOverride + "/linux/activegate:latest"
+			dynakube := &dynatracev1beta1.DynaKube{
+				ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+				Spec: dynatracev1beta1.DynaKubeSpec{
+					APIURL:           testApiUrl,
+					RegistryOverride: registryOverride,
+					ActiveGate: dynatracev1beta1.ActiveGateSpec{
+						Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+							dynatracev1beta1.CapabilityDisplayName(dynatracev1beta1.KubeMonCapability.ShortName),
+						},
+					},
+				},
+			}
+			fakeClient := fake.NewClient()
+			setupPullSecret(t, fakeClient, *dynakube)
+			fs := afero.Afero{Fs: afero.NewMemMapFs()}
+			timeProvider := kubeobjects.NewTimeProvider()
+			registry := newFakeRegistry(map[string]string{mirroredImagePath: "1.0.0"})
+
+			err := ReconcileVersions(ctx, dynakube, fakeClient, fakeClient, fs, registry.ImageVersionExt, *timeProvider)
+			require.NoError(t, err)
+
+			assertVersionStatusEquals(t, registry, mirroredImagePath, *timeProvider, &dynakube.Status.ActiveGate)
+			assert.Equal(t, mirroredImagePath, dynakube.Status.ActiveGate.ProbedImage)
+			assert.Equal(t, pinnedImage(mirroredImagePath, dynakube.Status.ActiveGate.ImageHash), dynakube.Status.ActiveGate.Image)
+			assert.Contains(t, dynakube.Status.ActiveGate.Image, registryOverride+"/linux/activegate@sha256:")
+		})
+	}
+}
+
+func TestUpdateImageVersion(t *testing.T) {
+	const (
+		taggedImagePath   = testDockerRegistry + "/linux/activegate:1.0.0"
+		pinnedImagePath   = testDockerRegistry + "/linux/activegate@sha256:" + testImageDigest
+		resolvedVersion   = "1.0.0.20221116-111111"
+		registryImageHash = "registry-hash"
+	)
+
+	newCountingProvider := func(calls *int) VersionProviderCallback {
+		return func(_ string, _ *dockerconfig.DockerConfig) (ImageVersion, error) {
+			*calls++
+			return ImageVersion{Version: resolvedVersion, Hash: registryImageHash}, nil
+		}
+	}
+
+	t.Run("tagged image is probed on every update", func(t *testing.T) {
+		fetchesBefore := imageVersionFetchCount(t, outcomeSuccess)
+		calls := 0
+		target := dynatracev1beta1.VersionStatus{}
+		provider := newCountingProvider(&calls)
+
+		err := updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+		err = updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, fetchesBefore+2, imageVersionFetchCount(t, outcomeSuccess))
+		assert.Equal(t, resolvedVersion, target.Version)
+		assert.Equal(t, registryImageHash, target.ImageHash)
+		assert.Equal(t, testDockerRegistry+"/linux/activegate@sha256:"+registryImageHash, target.Image)
+	})
+	t.Run("pinned image is resolved once and keeps its digest", func(t *testing.T) {
+		calls := 0
+		target := dynatracev1beta1.VersionStatus{}
+		provider := newCountingProvider(&calls)
+
+		err := updateImageVersion(context.TODO(), metav1.Now(), pinnedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+		err = updateImageVersion(context.TODO(), metav1.Now(), pinnedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, resolvedVersion, target.Version)
+		assert.Equal(t, testImageDigest, target.ImageHash)
+		assert.Equal(t, pinnedImagePath, target.Image)
+		assert.NotNil(t, target.LastUpdateProbeTimestamp)
+	})
+	t.Run("architectures of multi-arch images are recorded", func(t *testing.T) {
+		architectureHashes := map[string]string{"amd64": "amd64-hash", "arm64": "arm64-hash"}
+		target := dynatracev1beta1.VersionStatus{}
+		provider := func(_ string, _ *dockerconfig.DockerConfig) (ImageVersion, error) {
+			return ImageVersion{Version: resolvedVersion, Hash: registryImageHash, ArchitectureHashes: architectureHashes}, nil
+		}
+
+		err := updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, architectureHashes, target.ArchitectureHashes)
+
+		// an image that was resolved before its architectures were recorded gets them on the next probe
+		target.ArchitectureHashes = nil
+		err = updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, architectureHashes, target.ArchitectureHashes)
+	})
+	t.Run("update history records hash changes", func(t *testing.T) {
+		registry := newFakeRegistry(map[string]string{taggedImagePath: "1.0.0"})
+		target := dynatracev1beta1.VersionStatus{}
+		firstProbe := metav1.Now()
+
+		err := updateImageVersion(context.TODO(), firstProbe, taggedImagePath, &target, nil, registry.ImageVersionExt, true)
+		require.NoError(t, err)
+		firstHash := target.ImageHash
+
+		err = updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, registry.ImageVersionExt, true)
+		require.NoError(t, err)
+		require.Len(t, target.UpdateHistory, 1)
+
+		registry.SetVersion(taggedImagePath, "1.0.1")
+		err = updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, registry.ImageVersionExt, true)
+		require.NoError(t, err)
+
+		require.Len(t, target.UpdateHistory, 2)
+		assert.Equal(t, dynatracev1beta1.ImageUpdate{NewVersion: "1.0.0", NewHash: firstHash, Timestamp: firstProbe}, target.UpdateHistory[0])
+		assert.Equal(t, "1.0.0", target.UpdateHistory[1].OldVersion)
+		assert.Equal(t, "1.0.1", target.UpdateHistory[1].NewVersion)
+		assert.Equal(t, firstHash, target.UpdateHistory[1].OldHash)
+		assert.Equal(t, target.ImageHash, target.UpdateHistory[1].NewHash)
+	})
+	t.Run("same version with a new digest updates the hash", func(t *testing.T) {
+		architectureHashes := map[string]string{"amd64": "amd64-hash"}
+		target := dynatracev1beta1.VersionStatus{Version: resolvedVersion, ImageHash: "old-hash"}
+		provider := func(_ string, _ *dockerconfig.DockerConfig) (ImageVersion, error) {
+			return ImageVersion{Version: resolvedVersion, Hash: registryImageHash, ArchitectureHashes: architectureHashes}, nil
+		}
+
+		err := updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, provider, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, resolvedVersion, target.Version)
+		assert.Equal(t, registryImageHash, target.ImageHash)
+		assert.Equal(t, architectureHashes, target.ArchitectureHashes)
+		assert.Equal(t, testDockerRegistry+"/linux/activegate@sha256:"+registryImageHash, target.Image)
+		require.Len(t, target.UpdateHistory, 1)
+		assert.Equal(t, "old-hash", target.UpdateHistory[0].OldHash)
+		assert.Equal(t, registryImageHash, target.UpdateHistory[0].NewHash)
+	})
+	t.Run("pinned image with a new digest of the same version is resolved once", func(t *testing.T) {
+		calls := 0
+		target := dynatracev1beta1.VersionStatus{Version: resolvedVersion, ImageHash: "old-hash"}
+		provider := newCountingProvider(&calls)
+
+		err := updateImageVersion(context.TODO(), metav1.Now(), pinnedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+		err = updateImageVersion(context.TODO(), metav1.Now(), pinnedImagePath, &target, nil, provider, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, testImageDigest, target.ImageHash)
+		assert.Equal(t, pinnedImagePath, target.Image)
+	})
+	t.Run("update history is not extended if only the version changed", func(t *testing.T) {
+		calls := 0
+		target := dynatracev1beta1.VersionStatus{Version: "0.9.0", ImageHash: registryImageHash}
+
+		err := updateImageVersion(context.TODO(), metav1.Now(), taggedImagePath, &target, nil, newCountingProvider(&calls), true)
+		require.NoError(t, err)
+
+		assert.Equal(t, resolvedVersion, target.Version)
+		assert.Empty(t, target.UpdateHistory)
+	})
+}
+
+func TestAppendUpdateHistory(t *testing.T) {
+	target := dynatracev1beta1.VersionStatus{}
+
+	for i := 0; i < MaxUpdateHistoryLength+5; i++ {
+		appendUpdateHistory(&target, dynatracev1beta1.ImageUpdate{NewVersion: fmt.Sprint(i)})
+	}
+
+	require.Len(t, target.UpdateHistory, MaxUpdateHistoryLength)
+	assert.Equal(t, "5", target.UpdateHistory[0].NewVersion)
+	assert.Equal(t, fmt.Sprint(MaxUpdateHistoryLength+4), target.UpdateHistory[MaxUpdateHistoryLength-1].NewVersion)
+}
+
+func imageVersionFetchCount(t *testing.T, outcome string) uint64 {
+	metric := &dto.Metric{}
+	err := imageVersionFetchDurationMetric.WithLabelValues(outcome).(prometheus.Histogram).Write(metric)
+	require.NoError(t, err)
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestPinnedImage(t *testing.T) {
+	assert.Equal(t, testDockerRegistry+"/linux/activegate@sha256:"+testImageDigest, pinnedImage(testDockerRegistry+"/linux/activegate:1.0.0", testImageDigest))
+	assert.Equal(t, testDockerRegistry+"/linux/activegate@sha256:"+testImageDigest, pinnedImage(testDockerRegistry+"/linux/activegate:latest@sha256:"+testImageDigest, testImageDigest))
+	assert.Equal(t, "docker.io/dynatrace/activegate@sha256:"+testImageDigest, pinnedImage("dynatrace/activegate", testImageDigest))
+	assert.Empty(t, pinnedImage(testDockerRegistry+"/linux/activegate:1.0.0", ""))
+	assert.Empty(t, pinnedImage("invalid image", testImageDigest))
+}
+
+func TestPinnedDigest(t *testing.T) {
+	digest, isPinned := pinnedDigest(agImagePath)
+	assert.False(t, isPinned)
+	assert.Empty(t, digest)
+
+	digest, isPinned = pinnedDigest(testDockerRegistry + "/linux/activegate@sha256:" + testImageDigest)
+	assert.True(t, isPinned)
+	assert.Equal(t, testImageDigest, digest)
+
+	digest, isPinned = pinnedDigest(testDockerRegistry + "/linux/activegate:latest@sha256:" + testImageDigest)
+	assert.True(t, isPinned)
+	assert.Equal(t, testImageDigest, digest)
+}
+
+func TestMakeRegistrySystemContext(t *testing.T) {
+	dockerReference, err := reference.ParseNormalizedNamed(agImagePath)
+	require.NoError(t, err)
+
+	t.Run("certificates are validated by default", func(t *testing.T) {
+		systemContext := makeRegistrySystemContext(dockerReference, &dockerconfig.DockerConfig{Dynakube: &dynatracev1beta1.DynaKube{}})
+
+		assert.Equal(t, types.OptionalBoolUndefined, systemContext.DockerInsecureSkipTLSVerify)
+	})
+	t.Run("certificate validation is skipped with the feature flag", func(t *testing.T) {
+		dynakube := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureSkipRegistryCertCheck: "true"},
+			},
+		}
+
+		systemContext := makeRegistrySystemContext(dockerReference, &dockerconfig.DockerConfig{Dynakube: dynakube})
+
+		assert.Equal(t, types.OptionalBoolTrue, systemContext.DockerInsecureSkipTLSVerify)
+		assert.False(t, dynakube.Spec.SkipCertCheck)
+	})
+	t.Run("nil docker config", func(t *testing.T) {
+		systemContext := makeRegistrySystemContext(dockerReference, nil)
+
+		assert.Equal(t, types.OptionalBoolUndefined, systemContext.DockerInsecureSkipTLSVerify)
+	})
+}
+
 func setupPullSecret(t *testing.T, fakeClient client.Client, dynakube dynatracev1beta1.DynaKube) {
 	data, err := buildTestDockerAuth()
 	require.NoError(t, err)
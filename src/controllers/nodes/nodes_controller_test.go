@@ -166,6 +166,10 @@ func (builder mockDynatraceClientBuilder) SetContext(context.Context) dynatracec
 	return builder
 }
 
+func (builder mockDynatraceClientBuilder) SetTimeout(time.Duration) dynatraceclient.Builder {
+	return builder
+}
+
 func (builder mockDynatraceClientBuilder) SetDynakube(dynatracev1beta1.DynaKube) dynatraceclient.Builder {
 	return builder
 }
@@ -0,0 +1,151 @@
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron expression with the five standard fields: minute, hour, day of month, month and day of week
+type Schedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+
+	// restricted days are matched like in cron: if both day fields are restricted, either of them has to match
+	daysOfMonthRestricted bool
+	daysOfWeekRestricted  bool
+}
+
+type fieldBounds struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	minuteBounds     = fieldBounds{name: "minute", min: 0, max: 59}
+	hourBounds       = fieldBounds{name: "hour", min: 0, max: 23}
+	dayOfMonthBounds = fieldBounds{name: "day of month", min: 1, max: 31}
+	monthBounds      = fieldBounds{name: "month", min: 1, max: 12}
+	// 7 is accepted as an alias for sunday
+	dayOfWeekBounds = fieldBounds{name: "day of week", min: 0, max: 7}
+)
+
+// Parse parses a cron expression like "30 2 * * 1-5". Every field supports '*', values, ranges, lists and steps.
+func Parse(expression string) (*Schedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("expected 5 fields in cron expression '%s', got %d", expression, len(fields))
+	}
+
+	var schedule Schedule
+	var err error
+	if schedule.minutes, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfMonth, err = parseField(fields[2], dayOfMonthBounds); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek, err = parseField(fields[4], dayOfWeekBounds); err != nil {
+		return nil, err
+	}
+	schedule.daysOfWeek[0] = schedule.daysOfWeek[0] || schedule.daysOfWeek[7]
+
+	schedule.daysOfMonthRestricted = !strings.HasPrefix(fields[2], "*")
+	schedule.daysOfWeekRestricted = !strings.HasPrefix(fields[4], "*")
+	return &schedule, nil
+}
+
+// Matches returns true if the minute of t is one the schedule fires at
+func (schedule *Schedule) Matches(t time.Time) bool {
+	if !schedule.minutes[t.Minute()] || !schedule.hours[t.Hour()] || !schedule.months[int(t.Month())] {
+		return false
+	}
+
+	dayOfMonthMatches := schedule.daysOfMonth[t.Day()]
+	dayOfWeekMatches := schedule.daysOfWeek[int(t.Weekday())]
+	if schedule.daysOfMonthRestricted && schedule.daysOfWeekRestricted {
+		return dayOfMonthMatches || dayOfWeekMatches
+	}
+	return dayOfMonthMatches && dayOfWeekMatches
+}
+
+// IsWithin returns true if the schedule fired in the duration up to and including the minute of now
+func (schedule *Schedule) IsWithin(now time.Time, duration time.Duration) bool {
+	end := now.Truncate(time.Minute)
+	for start := end; end.Sub(start) < duration; start = start.Add(-time.Minute) {
+		if schedule.Matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseField(field string, bounds fieldBounds) ([]bool, error) {
+	values := make([]bool, bounds.max+1)
+	for _, part := range strings.Split(field, ",") {
+		if err := parsePart(part, bounds, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// parsePart marks the values of a single list entry, e.g. '*', '5', '1-5' or '*/15'
+func parsePart(part string, bounds fieldBounds, values []bool) error {
+	valueRange, stepValue, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepValue)
+		if err != nil || step < 1 {
+			return errors.Errorf("invalid step '%s' in %s field", stepValue, bounds.name)
+		}
+	}
+
+	start, end := bounds.min, bounds.max
+	if valueRange != "*" {
+		startValue, endValue, isRange := strings.Cut(valueRange, "-")
+		var err error
+		if start, err = parseValue(startValue, bounds); err != nil {
+			return err
+		}
+		end = start
+		if isRange {
+			if end, err = parseValue(endValue, bounds); err != nil {
+				return err
+			}
+		} else if hasStep {
+			end = bounds.max
+		}
+	}
+
+	if start > end {
+		return errors.Errorf("invalid range '%s' in %s field", valueRange, bounds.name)
+	}
+
+	for value := start; value <= end; value += step {
+		values[value] = true
+	}
+	return nil
+}
+
+func parseValue(value string, bounds fieldBounds) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < bounds.min || parsed > bounds.max {
+		return 0, errors.Errorf("invalid value '%s' in %s field, must be between %d and %d", value, bounds.name, bounds.min, bounds.max)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,91 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("valid expressions", func(t *testing.T) {
+		for _, expression := range []string{
+			"* * * * *",
+			"30 2 * * *",
+			"0 22 * * 1-5",
+			"*/15 0-6/2 1,15 * 7",
+			"5/10 * * 1-12 0",
+		} {
+			_, err := Parse(expression)
+			assert.NoError(t, err, expression)
+		}
+	})
+	t.Run("invalid expressions", func(t *testing.T) {
+		for _, expression := range []string{
+			"",
+			"* * * *",
+			"* * * * * *",
+			"60 * * * *",
+			"* 24 * * *",
+			"* * 0 * *",
+			"* * * 13 *",
+			"* * * * 8",
+			"5-1 * * * *",
+			"*/0 * * * *",
+			"a * * * *",
+		} {
+			_, err := Parse(expression)
+			assert.Error(t, err, expression)
+		}
+	})
+}
+
+func TestScheduleMatches(t *testing.T) {
+	// a monday
+	monday := time.Date(2022, time.October, 3, 2, 30, 0, 0, time.UTC)
+
+	t.Run("minute and hour", func(t *testing.T) {
+		schedule, err := Parse("30 2 * * *")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Matches(monday))
+		assert.False(t, schedule.Matches(monday.Add(time.Minute)))
+		assert.False(t, schedule.Matches(monday.Add(time.Hour)))
+	})
+	t.Run("steps", func(t *testing.T) {
+		schedule, err := Parse("*/15 * * * *")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Matches(monday))
+		assert.True(t, schedule.Matches(monday.Add(15*time.Minute)))
+		assert.False(t, schedule.Matches(monday.Add(5*time.Minute)))
+	})
+	t.Run("sunday as 7", func(t *testing.T) {
+		schedule, err := Parse("30 2 * * 7")
+		require.NoError(t, err)
+
+		assert.False(t, schedule.Matches(monday))
+		assert.True(t, schedule.Matches(monday.AddDate(0, 0, 6)))
+	})
+	t.Run("either restricted day field matches", func(t *testing.T) {
+		schedule, err := Parse("30 2 15 * 1")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Matches(monday))
+		assert.True(t, schedule.Matches(time.Date(2022, time.October, 15, 2, 30, 0, 0, time.UTC)))
+		assert.False(t, schedule.Matches(monday.AddDate(0, 0, 1)))
+	})
+}
+
+func TestScheduleIsWithin(t *testing.T) {
+	schedule, err := Parse("0 22 * * *")
+	require.NoError(t, err)
+	start := time.Date(2022, time.October, 3, 22, 0, 0, 0, time.UTC)
+
+	assert.True(t, schedule.IsWithin(start, 2*time.Hour))
+	assert.True(t, schedule.IsWithin(start.Add(119*time.Minute+30*time.Second), 2*time.Hour))
+	assert.False(t, schedule.IsWithin(start.Add(2*time.Hour), 2*time.Hour))
+	assert.False(t, schedule.IsWithin(start.Add(-time.Minute), 2*time.Hour))
+	assert.False(t, schedule.IsWithin(start, 0))
+}
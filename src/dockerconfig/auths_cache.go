@@ -0,0 +1,45 @@
+package dockerconfig
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AuthsCache keeps the parsed auths of pull secrets until the resourceVersion of the secret changes
+type AuthsCache struct {
+	mutex   sync.Mutex
+	entries map[types.NamespacedName]cachedAuths
+}
+
+type cachedAuths struct {
+	resourceVersion string
+	auths           map[string]DockerAuth
+}
+
+func NewAuthsCache() *AuthsCache {
+	return &AuthsCache{
+		entries: make(map[types.NamespacedName]cachedAuths),
+	}
+}
+
+func (cache *AuthsCache) get(key types.NamespacedName, resourceVersion string) (map[string]DockerAuth, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[key]
+	if !ok || resourceVersion == "" || entry.resourceVersion != resourceVersion {
+		return nil, false
+	}
+	return entry.auths, true
+}
+
+func (cache *AuthsCache) set(key types.NamespacedName, resourceVersion string, auths map[string]DockerAuth) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries[key] = cachedAuths{
+		resourceVersion: resourceVersion,
+		auths:           auths,
+	}
+}
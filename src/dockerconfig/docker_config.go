@@ -18,6 +18,11 @@ type DockerConfig struct {
 	Dynakube         *dynatracev1beta1.DynaKube
 	Auths            map[string]DockerAuth
 	TrustedCertsPath string
+
+	// AuthsCache skips reading and parsing the pull secret again while its resourceVersion is unchanged, disabled if nil.
+	// The resourceVersion is looked up through CachedClient, so an unchanged pull secret isn't read from the api server.
+	AuthsCache   *AuthsCache
+	CachedClient client.Reader
 }
 
 type DockerAuth struct {
@@ -35,21 +40,46 @@ func NewDockerConfig(apiReader client.Reader, dynakube dynatracev1beta1.DynaKube
 }
 
 func (config *DockerConfig) SetupAuths(ctx context.Context) error {
+	pullSecretKey := client.ObjectKey{Name: config.Dynakube.PullSecret(), Namespace: config.Dynakube.Namespace}
+	if dockerAuths, ok := config.getCachedAuths(ctx, pullSecretKey); ok {
+		config.Auths = dockerAuths
+		return nil
+	}
+
 	var pullSecret corev1.Secret
-	err := config.ApiReader.Get(ctx, client.ObjectKey{Name: config.Dynakube.PullSecret(), Namespace: config.Dynakube.Namespace}, &pullSecret)
+	err := config.ApiReader.Get(ctx, pullSecretKey, &pullSecret)
 	if err != nil {
 		log.Info("failed to load pull secret", "dynakube", config.Dynakube.Name)
 		return errors.WithStack(err)
 	}
+
 	dockerAuths, err := parseDockerAuthsFromSecret(&pullSecret)
 	if err != nil {
 		log.Info("failed to parse pull secret content", "dynakube", config.Dynakube.Name)
 		return err
 	}
 	config.Auths = dockerAuths
+
+	if config.AuthsCache != nil {
+		config.AuthsCache.set(pullSecretKey, pullSecret.ResourceVersion, dockerAuths)
+	}
 	return nil
 }
 
+// getCachedAuths returns the auths parsed for the resourceVersion of the pull secret that is known to the cached client,
+// a pull secret that isn't in the cache yet is treated as a cache miss
+func (config *DockerConfig) getCachedAuths(ctx context.Context, pullSecretKey client.ObjectKey) (map[string]DockerAuth, bool) {
+	if config.AuthsCache == nil || config.CachedClient == nil {
+		return nil, false
+	}
+
+	var pullSecret corev1.Secret
+	if err := config.CachedClient.Get(ctx, pullSecretKey, &pullSecret); err != nil {
+		return nil, false
+	}
+	return config.AuthsCache.get(pullSecretKey, pullSecret.ResourceVersion)
+}
+
 func (config *DockerConfig) SaveCustomCAs(
 	ctx context.Context,
 	fs afero.Afero,
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -140,6 +141,87 @@ func TestSetupAuths(t *testing.T) {
 
 }
 
+type getCountingReader struct {
+	client.Reader
+	gets int
+}
+
+func (reader *getCountingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	reader.gets++
+	return reader.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestSetupAuthsCached(t *testing.T) {
+	dynakube := dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testName,
+		},
+	}
+	newPullSecret := func(password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: dynakube.PullSecret(),
+			},
+			Data: map[string][]byte{
+				".dockerconfigjson": []byte(
+					fmt.Sprintf(`{ "auths": { "%s": { "username": "%s", "password": "%s" } } }`, testKey, testName, password)),
+			},
+		}
+	}
+
+	t.Run("parsed auths are reused while the resource version is unchanged", func(t *testing.T) {
+		pullSecret := newPullSecret(testValue)
+		cachedClient := fake.NewClient(pullSecret)
+		apiReader := &getCountingReader{Reader: cachedClient}
+		require.NoError(t, cachedClient.Get(context.TODO(), client.ObjectKeyFromObject(pullSecret), pullSecret))
+
+		cache := NewAuthsCache()
+		cachedAuths := map[string]DockerAuth{testKey: {Username: "cached", Password: "cached"}}
+		cache.set(client.ObjectKeyFromObject(pullSecret), pullSecret.ResourceVersion, cachedAuths)
+
+		dockerConfig := NewDockerConfig(apiReader, dynakube)
+		dockerConfig.AuthsCache = cache
+		dockerConfig.CachedClient = cachedClient
+		err := dockerConfig.SetupAuths(context.TODO())
+
+		require.NoError(t, err)
+		assert.Equal(t, cachedAuths, dockerConfig.Auths)
+		assert.Zero(t, apiReader.gets)
+	})
+	t.Run("pull secret missing from the cached client is read from the api server", func(t *testing.T) {
+		apiReader := &getCountingReader{Reader: fake.NewClient(newPullSecret(testValue))}
+
+		dockerConfig := NewDockerConfig(apiReader, dynakube)
+		dockerConfig.AuthsCache = NewAuthsCache()
+		dockerConfig.CachedClient = fake.NewClient()
+		require.NoError(t, dockerConfig.SetupAuths(context.TODO()))
+
+		assert.Equal(t, testValue, dockerConfig.Auths[testKey].Password)
+		assert.Equal(t, 1, apiReader.gets)
+	})
+	t.Run("rotated pull secret is parsed again", func(t *testing.T) {
+		pullSecret := newPullSecret(testValue)
+		apiReader := fake.NewClient(pullSecret)
+		cache := NewAuthsCache()
+
+		dockerConfig := NewDockerConfig(apiReader, dynakube)
+		dockerConfig.AuthsCache = cache
+		dockerConfig.CachedClient = apiReader
+		require.NoError(t, dockerConfig.SetupAuths(context.TODO()))
+		assert.Equal(t, testValue, dockerConfig.Auths[testKey].Password)
+
+		require.NoError(t, apiReader.Get(context.TODO(), client.ObjectKeyFromObject(pullSecret), pullSecret))
+		pullSecret.Data = newPullSecret("rotated").Data
+		require.NoError(t, apiReader.Update(context.TODO(), pullSecret))
+
+		dockerConfig = NewDockerConfig(apiReader, dynakube)
+		dockerConfig.AuthsCache = cache
+		dockerConfig.CachedClient = apiReader
+		require.NoError(t, dockerConfig.SetupAuths(context.TODO()))
+		assert.Equal(t, "rotated", dockerConfig.Auths[testKey].Password)
+	})
+}
+
 func TestParseDockerAuthsFromSecret(t *testing.T) {
 	t.Run("parseDockerAuthsFromSecret handles nil secret", func(t *testing.T) {
 		auths, err := parseDockerAuthsFromSecret(nil)
@@ -8,6 +8,8 @@ import (
 )
 
 // MakeSystemContext returns a SystemConfig for the given image and Dockerconfig.
+// Registry lookups go through the proxy set in the environment of the operator, not the proxy of the DynaKube,
+// as containers/image only supports a proxy per lookup since v5.25.
 func MakeSystemContext(dockerReference reference.Named, dockerConfig *DockerConfig) *types.SystemContext {
 	if dockerReference == nil || dockerConfig == nil {
 		return &types.SystemContext{}
@@ -3,7 +3,10 @@ package dtclient
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -48,6 +51,48 @@ func (dtc *dynatraceClient) GetActiveGateAuthToken(dynakubeName string) (*Active
 	return authTokenInfo, nil
 }
 
+func (dtc *dynatraceClient) DeleteActiveGateAuthToken(tokenID string) error {
+	if tokenID == "" {
+		return errors.New("no activegate auth token id given")
+	}
+
+	req, err := dtc.createBaseRequest(dtc.getActiveGateAuthTokenObjectUrl(tokenID), http.MethodDelete, dtc.apiToken, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making delete request to dynatrace api: %s", err.Error())
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	resData, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK &&
+		res.StatusCode != http.StatusNoContent {
+		return dtc.handleErrorResponseFromAPI(resData, res.StatusCode)
+	}
+
+	return nil
+}
+
+// ActiveGateAuthTokenID returns the id of the given token, which consists of its prefix and public part
+func ActiveGateAuthTokenID(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
 func (dtc *dynatraceClient) createAuthTokenRequest(dynakubeName string) (*http.Request, error) {
 	body := &ActiveGateAuthTokenParams{
 		Name:           dynakubeName,
@@ -60,7 +105,7 @@ func (dtc *dynatraceClient) createAuthTokenRequest(dynakubeName string) (*http.R
 		return nil, err
 	}
 
-	request, err := createBaseRequest(
+	request, err := dtc.createBaseRequest(
 		dtc.getActiveGateAuthTokenUrl(),
 		http.MethodPost,
 		dtc.apiToken,
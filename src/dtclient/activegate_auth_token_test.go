@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,3 +47,55 @@ func TestGetActiveGateAuthTokenInfo(t *testing.T) {
 		assert.Equal(t, "dynatrace server error 500: error retrieving tenant info", err.Error())
 	})
 }
+
+func TestDeleteActiveGateAuthToken(t *testing.T) {
+	deleteHandler := func(statusCode int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodDelete || r.URL.Path != activeGateAuthTokenUrl+"/"+activeGateAuthTokenResponse.TokenId {
+				writeError(w, http.StatusBadRequest)
+				return
+			}
+			if statusCode >= http.StatusBadRequest && statusCode != http.StatusNotFound {
+				writeError(w, statusCode)
+				return
+			}
+			w.WriteHeader(statusCode)
+		}
+	}
+
+	t.Run("DeleteActiveGateAuthToken works", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, deleteHandler(http.StatusNoContent))
+		defer dynatraceServer.Close()
+
+		assert.NoError(t, dynatraceClient.DeleteActiveGateAuthToken(activeGateAuthTokenResponse.TokenId))
+	})
+	t.Run("DeleteActiveGateAuthToken ignores already removed tokens", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, deleteHandler(http.StatusNotFound))
+		defer dynatraceServer.Close()
+
+		assert.NoError(t, dynatraceClient.DeleteActiveGateAuthToken(activeGateAuthTokenResponse.TokenId))
+	})
+	t.Run("DeleteActiveGateAuthToken requires a token id", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, deleteHandler(http.StatusNoContent))
+		defer dynatraceServer.Close()
+
+		assert.Error(t, dynatraceClient.DeleteActiveGateAuthToken(""))
+	})
+	t.Run("DeleteActiveGateAuthToken handle server error", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, deleteHandler(http.StatusForbidden))
+		defer dynatraceServer.Close()
+
+		err := dynatraceClient.DeleteActiveGateAuthToken(activeGateAuthTokenResponse.TokenId)
+		assert.Error(t, err)
+
+		var serverErr ServerError
+		assert.ErrorAs(t, err, &serverErr)
+		assert.Equal(t, http.StatusForbidden, serverErr.Code)
+	})
+}
+
+func TestActiveGateAuthTokenID(t *testing.T) {
+	assert.Equal(t, "dt0g02.PUBLIC", ActiveGateAuthTokenID("dt0g02.PUBLIC.SECRET"))
+	assert.Empty(t, ActiveGateAuthTokenID("invalid"))
+	assert.Empty(t, ActiveGateAuthTokenID(""))
+}
@@ -1,14 +1,17 @@
 package dtclient
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -68,17 +71,36 @@ type Client interface {
 	// CreateOrUpdateKubernetesSetting returns the object id of the created k8s settings if successful, or an api error otherwise
 	CreateOrUpdateKubernetesSetting(name, kubeSystemUUID, scope string) (string, error)
 
+	// UpdateKubernetesSettingLabel changes the label of the given k8s settings object and keeps its other properties,
+	// or returns an api error otherwise
+	UpdateKubernetesSettingLabel(setting KubernetesSettingObject, clusterLabel string) error
+
 	// GetMonitoredEntitiesForKubeSystemUUID returns a (possibly empty) list of k8s monitored entities for the given uuid,
 	// or an api error otherwise
 	GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID string) ([]MonitoredEntity, error)
 
-	// GetSettingsForMonitoredEntities returns the settings response with the number of settings objects,
+	// GetSettingsForMonitoredEntities returns the settings response with the number of settings objects and their labels,
 	// or an api error otherwise
 	GetSettingsForMonitoredEntities(monitoredEntities []MonitoredEntity) (GetSettingsResponse, error)
 
+	// DeleteKubernetesSetting deletes the k8s settings object with the given object id,
+	// deleting an already removed object is not considered an error
+	DeleteKubernetesSetting(objectID string) error
+
 	// GetSettingsForMonitoredEntities returns the settings response with the number of settings objects,
 	// or an api error otherwise
 	GetActiveGateAuthToken(dynakubeName string) (*ActiveGateAuthTokenInfo, error)
+
+	// DeleteActiveGateAuthToken revokes the ActiveGate auth token with the given id,
+	// deleting an already removed token is not considered an error
+	DeleteActiveGateAuthToken(tokenID string) error
+
+	// CreateNetworkZone creates the network zone with the default settings, unless it exists already
+	CreateNetworkZone(networkZone string) error
+
+	// CheckConnection returns nil if the Dynatrace API can be reached with the api token,
+	// or the network or server error otherwise
+	CheckConnection() error
 }
 
 // Known OS values.
@@ -108,6 +130,9 @@ const (
 	TokenScopeSettingsRead          = "settings.read"
 	TokenScopeSettingsWrite         = "settings.write"
 	TokenScopeActiveGateTokenCreate = "activeGateTokenManagement.create"
+	TokenScopeActiveGateTokenWrite  = "activeGateTokenManagement.write"
+	TokenScopeNetworkZonesRead      = "networkZones.read"
+	TokenScopeNetworkZonesWrite     = "networkZones.write"
 )
 
 // NewClient creates a REST client for the given API base URL and authentication tokens.
@@ -143,6 +168,12 @@ func NewClient(url, apiToken, paasToken string, opts ...Option) (Client, error)
 		opt(dc)
 	}
 
+	// wrapped after the options, as they configure the underlying transport
+	if dc.rateLimiter != nil {
+		dc.httpClient.Transport = rateLimitRoundTripper{limiter: dc.rateLimiter, next: dc.httpClient.Transport}
+	}
+	dc.httpClient.Transport = metricsRoundTripper{next: dc.httpClient.Transport}
+
 	return dc, nil
 }
 
@@ -196,6 +227,42 @@ func NetworkZone(networkZone string) Option {
 	}
 }
 
+// Timeout creates an Option that limits the duration of every request made by the client, zero means no timeout.
+func Timeout(timeout time.Duration) Option {
+	return func(c *dynatraceClient) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// Context creates an Option that attaches ctx to every request made by the client, so they are aborted once ctx is cancelled.
+func Context(ctx context.Context) Option {
+	return func(c *dynatraceClient) {
+		c.ctx = ctx
+	}
+}
+
+// RateLimiter creates an Option that delays the requests of the client until limiter allows them,
+// the limiter can be shared by all clients talking to the same tenant.
+func RateLimiter(limiter *rate.Limiter) Option {
+	return func(c *dynatraceClient) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithContext returns a copy of client that attaches ctx to its requests instead of the context it was created with.
+// The copy shares the connections of client, but starts with an empty host cache, so cached clients don't serve outdated hosts.
+func WithContext(client Client, ctx context.Context) Client {
+	dtc, ok := client.(*dynatraceClient)
+	if !ok {
+		return client
+	}
+
+	clientWithContext := *dtc
+	clientWithContext.ctx = ctx
+	clientWithContext.hostCache = make(map[string]hostInfo)
+	return &clientWithContext
+}
+
 func DisableHostsRequests(disabledHostsRequests bool) Option {
 	return func(c *dynatraceClient) {
 		c.disableHostsRequests = disabledHostsRequests
@@ -1,10 +1,14 @@
 package dtclient
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewClient(t *testing.T) {
@@ -83,3 +87,42 @@ func TestCerts(t *testing.T) {
 	certs(&dtc)
 	assert.NotNil(t, transport.TLSClientConfig.RootCAs)
 }
+
+func TestTimeout(t *testing.T) {
+	dynatraceServer := createHangingDynatraceServer(t)
+
+	dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, Timeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = dtc.GetTokenScopes(apiToken)
+	assert.Error(t, err)
+}
+
+func TestContext(t *testing.T) {
+	dynatraceServer := createHangingDynatraceServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, Context(ctx))
+	require.NoError(t, err)
+
+	cancel()
+	_, err = dtc.GetTokenScopes(apiToken)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// createHangingDynatraceServer returns a server that doesn't answer until the request is aborted or the test is done,
+// the server can't rely on noticing the aborted request alone, as the connection may be kept open by the client
+func createHangingDynatraceServer(t *testing.T) *httptest.Server {
+	release := make(chan struct{})
+	dynatraceServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		dynatraceServer.Close()
+	})
+	return dynatraceServer
+}
@@ -2,8 +2,29 @@ package dtclient
 
 import (
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var (
 	log = logger.Factory.GetLogger("dtclient")
+
+	apiRequestDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "dynatrace",
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of requests to the Dynatrace API by method and status code, the code is 'error' if no response was received",
+	}, []string{"method", "code"})
+
+	apiRequestErrorsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "api",
+		Name:      "request_errors_total",
+		Help:      "Number of requests to the Dynatrace API that failed or were answered with an error status code",
+	}, []string{"method", "code"})
 )
+
+func init() {
+	metrics.Registry.MustRegister(apiRequestDurationMetric)
+	metrics.Registry.MustRegister(apiRequestErrorsMetric)
+}
@@ -0,0 +1,18 @@
+package dtclient
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CheckConnection requests the server time, the cheapest request authenticated by the api token,
+// so networking and token problems are reported before anything is deployed
+func (dtc *dynatraceClient) CheckConnection() error {
+	resp, err := dtc.makeRequest(dtc.getServerTimeUrl(), dynatraceApiToken)
+	if err != nil {
+		return errors.WithMessage(err, "dynatrace api is not reachable")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	_, err = dtc.getServerResponseData(resp)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,53 @@
+package dtclient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serverTimeHandler(status int) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/v1/time" || request.Header.Get("Authorization") != fmt.Sprintf("Api-Token %s", apiToken) {
+			writeError(writer, http.StatusBadRequest)
+			return
+		}
+		if status != http.StatusOK {
+			writeError(writer, status)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("1665000000000"))
+	}
+}
+
+func TestCheckConnection(t *testing.T) {
+	t.Run("reachable", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, serverTimeHandler(http.StatusOK))
+		defer dynatraceServer.Close()
+
+		assert.NoError(t, dynatraceClient.CheckConnection())
+	})
+	t.Run("server error contains the http status", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, serverTimeHandler(http.StatusUnauthorized))
+		defer dynatraceServer.Close()
+
+		err := dynatraceClient.CheckConnection()
+
+		require.Error(t, err)
+		assert.Equal(t, ServerError{Code: http.StatusUnauthorized, Message: "error received from server"}, errors.Cause(err))
+	})
+	t.Run("network error", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, serverTimeHandler(http.StatusOK))
+		dynatraceServer.Close()
+
+		err := dynatraceClient.CheckConnection()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dynatrace api is not reachable")
+	})
+}
@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 type hostInfo struct {
@@ -30,6 +32,12 @@ type dynatraceClient struct {
 
 	httpClient *http.Client
 
+	// rateLimiter delays the requests of the client, it is usually shared by all clients of a tenant
+	rateLimiter *rate.Limiter
+
+	// ctx is attached to every request, so requests are aborted once it is cancelled
+	ctx context.Context
+
 	hostCache map[string]hostInfo
 
 	// Set for testing purposes, leave the default zero value to use the current time.
@@ -44,10 +52,17 @@ const (
 	installerUrlToken // in this case we don't care about the token
 )
 
+func (dtc *dynatraceClient) context() context.Context {
+	if dtc.ctx == nil {
+		return context.Background()
+	}
+	return dtc.ctx
+}
+
 // makeRequest does an HTTP request by formatting the URL from the given arguments and returns the response.
 // The response body must be closed by the caller when no longer used.
 func (dtc *dynatraceClient) makeRequest(url string, tokenType tokenType) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(dtc.context(), "GET", url, nil)
 	if err != nil {
 		return nil, errors.WithMessage(err, "error initializing http request")
 	}
@@ -76,8 +91,8 @@ func (dtc *dynatraceClient) makeRequest(url string, tokenType tokenType) (*http.
 	return dtc.httpClient.Do(req)
 }
 
-func createBaseRequest(url, method, apiToken string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body)
+func (dtc *dynatraceClient) createBaseRequest(url, method, apiToken string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(dtc.context(), method, url, body)
 	if err != nil {
 		return nil, errors.WithMessage(err, "error initializing http request")
 	}
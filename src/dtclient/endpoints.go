@@ -1,6 +1,9 @@
 package dtclient
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 func (dtc *dynatraceClient) getAgentUrl(os, installerType, flavor, arch, version string, technologies []string) string {
 	url := fmt.Sprintf("%s/v1/deployment/installer/agent/%s/%s/version/%s?flavor=%s&arch=%s&bitness=64",
@@ -48,6 +51,10 @@ func (dtc *dynatraceClient) getSettingsUrl(validate bool) string {
 	return fmt.Sprintf("%s/v2/settings/objects%s", dtc.url, validationQuery)
 }
 
+func (dtc *dynatraceClient) getSettingsObjectUrl(objectID string) string {
+	return fmt.Sprintf("%s/v2/settings/objects/%s", dtc.url, objectID)
+}
+
 func (dtc *dynatraceClient) getProcessModuleConfigUrl() string {
 	return fmt.Sprintf("%s/v1/deployment/installer/agent/processmoduleconfig", dtc.url)
 }
@@ -64,6 +71,18 @@ func (dtc *dynatraceClient) getActiveGateAuthTokenUrl() string {
 	return fmt.Sprintf("%s/v2/activeGateTokens", dtc.url)
 }
 
+func (dtc *dynatraceClient) getActiveGateAuthTokenObjectUrl(tokenID string) string {
+	return fmt.Sprintf("%s/v2/activeGateTokens/%s", dtc.url, url.PathEscape(tokenID))
+}
+
+func (dtc *dynatraceClient) getNetworkZoneUrl(networkZone string) string {
+	return fmt.Sprintf("%s/v2/networkZones/%s", dtc.url, url.PathEscape(networkZone))
+}
+
+func (dtc *dynatraceClient) getServerTimeUrl() string {
+	return fmt.Sprintf("%s/v1/time", dtc.url)
+}
+
 func appendTechnologies(url string, technologies []string) string {
 	for _, tech := range technologies {
 		url = fmt.Sprintf("%s&include=%s", url, tech)
@@ -11,6 +11,11 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	kubernetesSettingsSchemaId      = "builtin:cloud.kubernetes"
+	kubernetesSettingsSchemaVersion = "1.0.27"
+)
+
 type postKubernetesSettings struct {
 	Label                           string `json:"label"`
 	ClusterIdEnabled                bool   `json:"clusterIdEnabled"`
@@ -30,6 +35,11 @@ type postKubernetesSettingsBody struct {
 	Value         postKubernetesSettings `json:"value"`
 }
 
+type putKubernetesSettingsBody struct {
+	SchemaVersion string                 `json:"schemaVersion"`
+	Value         map[string]interface{} `json:"value"`
+}
+
 type monitoredEntitiesResponse struct {
 	TotalCount int               `json:"totalCount"`
 	PageSize   int               `json:"pageSize"`
@@ -43,7 +53,22 @@ type MonitoredEntity struct {
 }
 
 type GetSettingsResponse struct {
-	TotalCount int `json:"totalCount"`
+	TotalCount int                       `json:"totalCount"`
+	Items      []KubernetesSettingObject `json:"items"`
+}
+
+// KubernetesSettingObject keeps the complete value of the settings object,
+// so updates don't reset the properties changed by users in the tenant
+type KubernetesSettingObject struct {
+	ObjectId      string                 `json:"objectId"`
+	SchemaVersion string                 `json:"schemaVersion,omitempty"`
+	Value         map[string]interface{} `json:"value"`
+}
+
+// Label returns the cluster label of the settings object
+func (setting KubernetesSettingObject) Label() string {
+	label, _ := setting.Value["label"].(string)
+	return label
 }
 
 type postSettingsResponse struct {
@@ -74,19 +99,9 @@ func (dtc *dynatraceClient) CreateOrUpdateKubernetesSetting(clusterLabel, kubeSy
 
 	body := []postKubernetesSettingsBody{
 		{
-			SchemaId:      "builtin:cloud.kubernetes",
-			SchemaVersion: "1.0.27",
-			Value: postKubernetesSettings{
-				Enabled:                         true,
-				Label:                           clusterLabel,
-				ClusterIdEnabled:                true,
-				ClusterId:                       kubeSystemUUID,
-				CloudApplicationPipelineEnabled: true,
-				OpenMetricsPipelineEnabled:      false,
-				EventProcessingActive:           false,
-				FilterEvents:                    false,
-				EventProcessingV2Active:         false,
-			},
+			SchemaId:      kubernetesSettingsSchemaId,
+			SchemaVersion: kubernetesSettingsSchemaVersion,
+			Value:         newKubernetesSettings(clusterLabel, kubeSystemUUID),
 		},
 	}
 
@@ -99,7 +114,7 @@ func (dtc *dynatraceClient) CreateOrUpdateKubernetesSetting(clusterLabel, kubeSy
 		return "", err
 	}
 
-	req, err := createBaseRequest(dtc.getSettingsUrl(false), http.MethodPost, dtc.apiToken, bytes.NewReader(bodyData))
+	req, err := dtc.createBaseRequest(dtc.getSettingsUrl(false), http.MethodPost, dtc.apiToken, bytes.NewReader(bodyData))
 	if err != nil {
 		return "", err
 	}
@@ -132,12 +147,73 @@ func (dtc *dynatraceClient) CreateOrUpdateKubernetesSetting(clusterLabel, kubeSy
 	return resDataJson[0].ObjectId, nil
 }
 
+func (dtc *dynatraceClient) UpdateKubernetesSettingLabel(setting KubernetesSettingObject, clusterLabel string) error {
+	if setting.ObjectId == "" {
+		return errors.New("no settings object id given")
+	}
+
+	value := make(map[string]interface{}, len(setting.Value)+1)
+	for key, property := range setting.Value {
+		value[key] = property
+	}
+	value["label"] = clusterLabel
+
+	schemaVersion := setting.SchemaVersion
+	if schemaVersion == "" {
+		schemaVersion = kubernetesSettingsSchemaVersion
+	}
+
+	bodyData, err := json.Marshal(putKubernetesSettingsBody{
+		SchemaVersion: schemaVersion,
+		Value:         value,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := dtc.createBaseRequest(dtc.getSettingsObjectUrl(setting.ObjectId), http.MethodPut, dtc.apiToken, bytes.NewReader(bodyData))
+	if err != nil {
+		return err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making put request to dynatrace api: %s", err.Error())
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	resData, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return dtc.handleErrorResponseFromAPI(resData, res.StatusCode)
+	}
+
+	return nil
+}
+
+func newKubernetesSettings(clusterLabel, kubeSystemUUID string) postKubernetesSettings {
+	return postKubernetesSettings{
+		Enabled:                         true,
+		Label:                           clusterLabel,
+		ClusterIdEnabled:                true,
+		ClusterId:                       kubeSystemUUID,
+		CloudApplicationPipelineEnabled: true,
+		OpenMetricsPipelineEnabled:      false,
+		EventProcessingActive:           false,
+		FilterEvents:                    false,
+		EventProcessingV2Active:         false,
+	}
+}
+
 func (dtc *dynatraceClient) GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID string) ([]MonitoredEntity, error) {
 	if kubeSystemUUID == "" {
 		return nil, errors.New("no kube-system namespace UUID given")
 	}
 
-	req, err := createBaseRequest(dtc.getEntitiesUrl(), http.MethodGet, dtc.apiToken, nil)
+	req, err := dtc.createBaseRequest(dtc.getEntitiesUrl(), http.MethodGet, dtc.apiToken, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -174,14 +250,15 @@ func (dtc *dynatraceClient) GetSettingsForMonitoredEntities(monitoredEntities []
 		scopes = append(scopes, entity.EntityId)
 	}
 
-	req, err := createBaseRequest(dtc.getSettingsUrl(true), http.MethodGet, dtc.apiToken, nil)
+	req, err := dtc.createBaseRequest(dtc.getSettingsUrl(true), http.MethodGet, dtc.apiToken, nil)
 	if err != nil {
 		return GetSettingsResponse{}, err
 	}
 
 	q := req.URL.Query()
-	q.Add("schemaIds", "builtin:cloud.kubernetes")
+	q.Add("schemaIds", kubernetesSettingsSchemaId)
 	q.Add("scopes", strings.Join(scopes, ","))
+	q.Add("fields", "objectId,value,schemaVersion")
 	req.URL.RawQuery = q.Encode()
 
 	res, err := dtc.httpClient.Do(req)
@@ -199,6 +276,39 @@ func (dtc *dynatraceClient) GetSettingsForMonitoredEntities(monitoredEntities []
 	return resDataJson, nil
 }
 
+func (dtc *dynatraceClient) DeleteKubernetesSetting(objectID string) error {
+	if objectID == "" {
+		return errors.New("no settings object id given")
+	}
+
+	req, err := dtc.createBaseRequest(dtc.getSettingsObjectUrl(objectID), http.MethodDelete, dtc.apiToken, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making delete request to dynatrace api: %s", err.Error())
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	resData, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK &&
+		res.StatusCode != http.StatusNoContent {
+		return dtc.handleErrorResponseFromAPI(resData, res.StatusCode)
+	}
+
+	return nil
+}
+
 func (dtc *dynatraceClient) unmarshalToJson(res *http.Response, resDataJson interface{}) error {
 	resData, err := dtc.getServerResponseData(res)
 
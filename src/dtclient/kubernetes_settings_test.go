@@ -258,6 +258,156 @@ func TestDynatraceClient_CreateOrUpdateKubernetesSetting(t *testing.T) {
 	})
 }
 
+func TestDynatraceClient_DeleteKubernetesSetting(t *testing.T) {
+	t.Run(`delete settings object with the given id`, func(t *testing.T) {
+		// arrange
+		dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectHandler(http.MethodDelete, http.StatusNoContent))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).DeleteKubernetesSetting(testObjectID)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run(`deleting an already removed settings object succeeds`, func(t *testing.T) {
+		// arrange
+		dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectHandler(http.MethodDelete, http.StatusNotFound))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).DeleteKubernetesSetting(testObjectID)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run(`don't delete settings object because no object id is provided`, func(t *testing.T) {
+		// arrange
+		dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectHandler(http.MethodDelete, http.StatusNoContent))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).DeleteKubernetesSetting("")
+
+		// assert
+		assert.Error(t, err)
+	})
+
+	t.Run(`don't delete settings object because of api error`, func(t *testing.T) {
+		// arrange
+		dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectHandler(http.MethodDelete, http.StatusBadRequest))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).DeleteKubernetesSetting(testObjectID)
+
+		// assert
+		assert.Error(t, err)
+	})
+}
+
+func TestDynatraceClient_UpdateKubernetesSettingLabel(t *testing.T) {
+	setting := KubernetesSettingObject{
+		ObjectId:      testObjectID,
+		SchemaVersion: "1.0.30",
+		Value: map[string]interface{}{
+			"label":                      "previous-label",
+			"clusterId":                  testUID,
+			"openMetricsPipelineEnabled": true,
+			"filterEvents":               true,
+		},
+	}
+
+	t.Run(`only the label of the settings object is changed`, func(t *testing.T) {
+		// arrange
+		var putBody putKubernetesSettingsBody
+		dynatraceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut || r.URL.Path != "/v2/settings/objects/"+testObjectID {
+				writeError(w, http.StatusBadRequest)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&putBody)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).UpdateKubernetesSettingLabel(setting, testName)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "1.0.30", putBody.SchemaVersion)
+		assert.Equal(t, map[string]interface{}{
+			"label":                      testName,
+			"clusterId":                  testUID,
+			"openMetricsPipelineEnabled": true,
+			"filterEvents":               true,
+		}, putBody.Value)
+		assert.Equal(t, "previous-label", setting.Label())
+	})
+
+	t.Run(`don't update settings object because no object id is provided`, func(t *testing.T) {
+		// arrange
+		dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectHandler(http.MethodPut, http.StatusOK))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).UpdateKubernetesSettingLabel(KubernetesSettingObject{}, testName)
+
+		// assert
+		assert.Error(t, err)
+	})
+
+	t.Run(`don't update settings object because of api error`, func(t *testing.T) {
+		// arrange
+		dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectHandler(http.MethodPut, http.StatusBadRequest))
+		defer dynatraceServer.Close()
+
+		skipCert := SkipCertificateValidation(true)
+		dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, skipCert)
+		require.NoError(t, err)
+		require.NotNil(t, dtc)
+
+		// act
+		err = dtc.(*dynatraceClient).UpdateKubernetesSettingLabel(setting, testName)
+
+		// assert
+		assert.Error(t, err)
+	})
+}
+
 func createMonitoredEntitiesForTesting() []MonitoredEntity {
 	return []MonitoredEntity{
 		{EntityId: "KUBERNETES_CLUSTER-0E30FE4BF2007587", DisplayName: "operator test entity 1", LastSeenTms: 1639483869085},
@@ -387,3 +537,19 @@ func mockDynatraceServerSettingsHandler(totalCount int, objectId string, isError
 		}
 	}
 }
+
+func mockDynatraceServerSettingsObjectHandler(method string, statusCode int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method || r.URL.Path != "/v2/settings/objects/"+testObjectID {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+
+		if statusCode >= http.StatusBadRequest && statusCode != http.StatusNotFound {
+			writeError(w, statusCode)
+			return
+		}
+
+		w.WriteHeader(statusCode)
+	}
+}
@@ -0,0 +1,33 @@
+package dtclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const requestErrorCode = "error"
+
+// metricsRoundTripper records the latency and the errors of every request to the Dynatrace API
+type metricsRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (roundTripper metricsRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := roundTripper.next.RoundTrip(request)
+	observeApiRequest(request.Method, response, err, time.Since(start))
+	return response, err
+}
+
+func observeApiRequest(method string, response *http.Response, err error, duration time.Duration) {
+	code := requestErrorCode
+	if err == nil && response != nil {
+		code = strconv.Itoa(response.StatusCode)
+	}
+
+	apiRequestDurationMetric.WithLabelValues(method, code).Observe(duration.Seconds())
+	if code == requestErrorCode || response.StatusCode >= http.StatusBadRequest {
+		apiRequestErrorsMetric.WithLabelValues(method, code).Inc()
+	}
+}
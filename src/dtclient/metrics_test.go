@@ -0,0 +1,49 @@
+package dtclient
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRoundTripper(t *testing.T) {
+	t.Run("error status codes are counted", func(t *testing.T) {
+		dynatraceServer, _ := createTestDynatraceClient(t, http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}), "")
+		defer dynatraceServer.Close()
+		errorCount := testutil.ToFloat64(apiRequestErrorsMetric.WithLabelValues(http.MethodGet, "503"))
+
+		client := http.Client{Transport: metricsRoundTripper{next: http.DefaultTransport}}
+		response, err := client.Get(dynatraceServer.URL)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+
+		assert.Equal(t, errorCount+1, testutil.ToFloat64(apiRequestErrorsMetric.WithLabelValues(http.MethodGet, "503")))
+	})
+	t.Run("successful requests are not counted as errors", func(t *testing.T) {
+		errorCount := testutil.ToFloat64(apiRequestErrorsMetric.WithLabelValues(http.MethodGet, "200"))
+
+		observeApiRequest(http.MethodGet, &http.Response{StatusCode: http.StatusOK}, nil, time.Second)
+
+		assert.Equal(t, errorCount, testutil.ToFloat64(apiRequestErrorsMetric.WithLabelValues(http.MethodGet, "200")))
+	})
+	t.Run("requests without response are counted as errors", func(t *testing.T) {
+		errorCount := testutil.ToFloat64(apiRequestErrorsMetric.WithLabelValues(http.MethodPost, requestErrorCode))
+
+		observeApiRequest(http.MethodPost, nil, errors.New("connection refused"), time.Second)
+
+		assert.Equal(t, errorCount+1, testutil.ToFloat64(apiRequestErrorsMetric.WithLabelValues(http.MethodPost, requestErrorCode)))
+	})
+	t.Run("clients record their requests", func(t *testing.T) {
+		dtc, err := NewClient("https://test.dynatrace.com", apiToken, paasToken)
+		require.NoError(t, err)
+
+		assert.IsType(t, metricsRoundTripper{}, dtc.(*dynatraceClient).httpClient.Transport)
+	})
+}
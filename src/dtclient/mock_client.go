@@ -76,6 +76,16 @@ func (o *MockDynatraceClient) CreateOrUpdateKubernetesSetting(name string, kubeS
 	return args.String(0), args.Error(1)
 }
 
+func (o *MockDynatraceClient) UpdateKubernetesSettingLabel(setting KubernetesSettingObject, clusterLabel string) error {
+	args := o.Called(setting, clusterLabel)
+	return args.Error(0)
+}
+
+func (o *MockDynatraceClient) DeleteKubernetesSetting(objectID string) error {
+	args := o.Called(objectID)
+	return args.Error(0)
+}
+
 func (o *MockDynatraceClient) GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID string) ([]MonitoredEntity, error) {
 	args := o.Called(kubeSystemUUID)
 	return args.Get(0).([]MonitoredEntity), args.Error(1)
@@ -90,3 +100,18 @@ func (o *MockDynatraceClient) GetActiveGateAuthToken(dynakubeName string) (*Acti
 	args := o.Called(dynakubeName)
 	return args.Get(0).(*ActiveGateAuthTokenInfo), args.Error(1)
 }
+
+func (o *MockDynatraceClient) DeleteActiveGateAuthToken(tokenID string) error {
+	args := o.Called(tokenID)
+	return args.Error(0)
+}
+
+func (o *MockDynatraceClient) CreateNetworkZone(networkZone string) error {
+	args := o.Called(networkZone)
+	return args.Error(0)
+}
+
+func (o *MockDynatraceClient) CheckConnection() error {
+	args := o.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,72 @@
+package dtclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// networkZoneParams leaves the fallback mode and the alternative zones to the defaults of the Dynatrace API
+type networkZoneParams struct {
+	AlternativeZones []string `json:"alternativeZones"`
+}
+
+func (dtc *dynatraceClient) CreateNetworkZone(networkZone string) error {
+	if networkZone == "" {
+		return errors.New("no network zone given")
+	}
+
+	exists, err := dtc.networkZoneExists(networkZone)
+	if err != nil || exists {
+		return err
+	}
+
+	bodyData, err := json.Marshal(networkZoneParams{AlternativeZones: []string{}})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	request, err := dtc.createBaseRequest(dtc.getNetworkZoneUrl(networkZone), http.MethodPut, dtc.apiToken, bytes.NewReader(bodyData))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+
+	response, err := dtc.httpClient.Do(request)
+	if err != nil {
+		return errors.WithMessage(err, "error making put request to dynatrace api")
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	_, err = dtc.getServerResponseData(response)
+	if err != nil {
+		return err
+	}
+
+	log.Info("created network zone", "networkZone", networkZone)
+	return nil
+}
+
+func (dtc *dynatraceClient) networkZoneExists(networkZone string) (bool, error) {
+	request, err := dtc.createBaseRequest(dtc.getNetworkZoneUrl(networkZone), http.MethodGet, dtc.apiToken, nil)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := dtc.httpClient.Do(request)
+	if err != nil {
+		return false, errors.WithMessage(err, "error making get request to dynatrace api")
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	_, err = dtc.getServerResponseData(response)
+	return err == nil, err
+}
@@ -0,0 +1,66 @@
+package dtclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testNetworkZone    = "test-zone"
+	networkZoneApiPath = "/v2/networkZones/" + testNetworkZone
+)
+
+func networkZoneHandler(existing bool, methods *[]string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != networkZoneApiPath {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*methods = append(*methods, request.Method)
+
+		switch {
+		case request.Method == http.MethodGet && existing:
+			_, _ = writer.Write([]byte(`{"id":"` + testNetworkZone + `"}`))
+		case request.Method == http.MethodGet:
+			writer.WriteHeader(http.StatusNotFound)
+		case request.Method == http.MethodPut:
+			writer.WriteHeader(http.StatusCreated)
+			_, _ = writer.Write([]byte(`{"id":"` + testNetworkZone + `"}`))
+		}
+	}
+}
+
+func TestCreateNetworkZone(t *testing.T) {
+	t.Run("missing network zone is created", func(t *testing.T) {
+		var methods []string
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, networkZoneHandler(false, &methods))
+		defer dynatraceServer.Close()
+
+		require.NoError(t, dynatraceClient.CreateNetworkZone(testNetworkZone))
+		assert.Equal(t, []string{http.MethodGet, http.MethodPut}, methods)
+	})
+	t.Run("existing network zone is kept", func(t *testing.T) {
+		var methods []string
+		dynatraceServer, dynatraceClient := createTestDynatraceClientWithFunc(t, networkZoneHandler(true, &methods))
+		defer dynatraceServer.Close()
+
+		require.NoError(t, dynatraceClient.CreateNetworkZone(testNetworkZone))
+		assert.Equal(t, []string{http.MethodGet}, methods)
+	})
+	t.Run("server error", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClient(t, tenantInternalServerError(networkZoneApiPath), "")
+		defer dynatraceServer.Close()
+
+		err := dynatraceClient.CreateNetworkZone(testNetworkZone)
+		assert.EqualError(t, err, "dynatrace server error 500: error retrieving tenant info")
+	})
+	t.Run("empty network zone", func(t *testing.T) {
+		dynatraceServer, dynatraceClient := createTestDynatraceClient(t, tenantInternalServerError(networkZoneApiPath), "")
+		defer dynatraceServer.Close()
+
+		assert.Error(t, dynatraceClient.CreateNetworkZone(""))
+	})
+}
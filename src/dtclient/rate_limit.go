@@ -0,0 +1,22 @@
+package dtclient
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitRoundTripper delays every request to the Dynatrace API until the limiter allows it,
+// so clients sharing a limiter don't exceed the request rate of their tenant together
+type rateLimitRoundTripper struct {
+	limiter *rate.Limiter
+	next    http.RoundTripper
+}
+
+func (roundTripper rateLimitRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	if err := roundTripper.limiter.Wait(request.Context()); err != nil {
+		return nil, errors.WithMessage(err, "request to the dynatrace api was rate limited")
+	}
+	return roundTripper.next.RoundTrip(request)
+}
@@ -0,0 +1,49 @@
+package dtclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type testContextKey struct{}
+
+func TestRateLimiter(t *testing.T) {
+	dynatraceServer, _ := createTestDynatraceClient(t, connectionInfoServerHandler(activeGateAuthTokenUrl, activeGateAuthTokenResponse), "")
+	defer dynatraceServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// a single request per hour, so the second request can't be sent within the deadline of the context
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken, RateLimiter(limiter), Context(ctx))
+	require.NoError(t, err)
+
+	_, err = dtc.GetActiveGateAuthToken(dynakubeName)
+	require.NoError(t, err)
+
+	_, err = dtc.GetActiveGateAuthToken(dynakubeName)
+	assert.Error(t, err)
+}
+
+func TestWithContext(t *testing.T) {
+	dtc, err := NewClient("https://test-endpoint.com/api", apiToken, paasToken)
+	require.NoError(t, err)
+	dtc.(*dynatraceClient).hostCache["1.2.3.4"] = hostInfo{entityID: "HOST-1"}
+
+	ctx := context.WithValue(context.Background(), testContextKey{}, "test")
+	clientWithContext := WithContext(dtc, ctx).(*dynatraceClient)
+
+	assert.Equal(t, ctx, clientWithContext.context())
+	assert.Same(t, dtc.(*dynatraceClient).httpClient, clientWithContext.httpClient)
+	assert.Empty(t, clientWithContext.hostCache)
+	assert.NotEmpty(t, dtc.(*dynatraceClient).hostCache)
+
+	mockClient := &MockDynatraceClient{}
+	assert.Same(t, mockClient, WithContext(mockClient, ctx))
+}
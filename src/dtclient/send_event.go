@@ -41,7 +41,7 @@ func (dtc *dynatraceClient) SendEvent(eventData *EventData) error {
 		return errors.WithStack(err)
 	}
 
-	req, err := http.NewRequest("POST", dtc.getEventsUrl(), bytes.NewBuffer(jsonStr))
+	req, err := http.NewRequestWithContext(dtc.context(), "POST", dtc.getEventsUrl(), bytes.NewBuffer(jsonStr))
 	if err != nil {
 		return fmt.Errorf("error initializing http request: %s", err.Error())
 	}
@@ -7,7 +7,7 @@ import (
 )
 
 type scalarType interface {
-	bool | int | int64 | time.Time | metav1.Time
+	bool | int | int32 | int64 | time.Time | metav1.Time
 }
 
 func Of[T scalarType](i T) *T {
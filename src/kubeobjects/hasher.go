@@ -1,6 +1,8 @@
 package kubeobjects
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"hash/fnv"
 	"strconv"
@@ -11,12 +13,30 @@ import (
 
 const AnnotationHash = dynatracev1beta1.InternalFlagPrefix + "template-hash"
 
+// hashLength is the number of bytes of the sha256 sum used for the hash, wide enough to rule out collisions in practice
+const hashLength = 16
+
+// legacyHashMaxLength is the number of digits of the largest fnv32 hash, which was used by previous operator versions
+const legacyHashMaxLength = 10
+
+// GenerateHash returns a hash of the JSON representation of ds.
+// The result is stable across restarts, since encoding/json sorts map keys.
 func GenerateHash(ds interface{}) (string, error) {
 	data, err := json.Marshal(ds)
 	if err != nil {
 		return "", err
 	}
 
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:hashLength]), nil
+}
+
+func generateLegacyHash(ds interface{}) (string, error) {
+	data, err := json.Marshal(ds)
+	if err != nil {
+		return "", err
+	}
+
 	hasher := fnv.New32()
 	_, err = hasher.Write(data)
 	if err != nil {
@@ -38,8 +58,41 @@ func IsDifferent(a, b interface{}) (bool, error) {
 	return hashA != hashB, nil
 }
 
-func IsHashAnnotationDifferent(a, b metav1.Object) bool {
-	return getHash(a) != getHash(b)
+// IsHashAnnotationDifferent compares the hash annotation of the current object with the one of the desired object.
+// Objects annotated by a previous operator version carry a fnv32 hash, which is compared with the fnv32 hash of the desired object instead,
+// so an operator upgrade doesn't update every workload just because the hash algorithm changed.
+func IsHashAnnotationDifferent(current, desired metav1.Object) bool {
+	currentHash := getHash(current)
+	if !isLegacyHash(currentHash) {
+		return currentHash != getHash(desired)
+	}
+
+	legacyHash, err := generateLegacyHashWithoutAnnotation(desired)
+	if err != nil {
+		return true
+	}
+	return currentHash != legacyHash
+}
+
+// generateLegacyHashWithoutAnnotation hashes the desired object the way it was before its hash annotation was set
+func generateLegacyHashWithoutAnnotation(desired metav1.Object) (string, error) {
+	annotations := desired.GetAnnotations()
+	desiredHash, hasHash := annotations[AnnotationHash]
+	if hasHash {
+		delete(annotations, AnnotationHash)
+		defer func() {
+			annotations[AnnotationHash] = desiredHash
+		}()
+	}
+	return generateLegacyHash(desired)
+}
+
+func isLegacyHash(hash string) bool {
+	if hash == "" || len(hash) > legacyHashMaxLength {
+		return false
+	}
+	_, err := strconv.ParseUint(hash, 10, 32)
+	return err == nil
 }
 
 func getHash(a metav1.Object) string {
@@ -0,0 +1,82 @@
+package kubeobjects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerateHash(t *testing.T) {
+	t.Run("hash is independent of map insertion order", func(t *testing.T) {
+		first := map[string]string{}
+		second := map[string]string{}
+		keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+		for i := range keys {
+			first[keys[i]] = keys[i]
+			second[keys[len(keys)-1-i]] = keys[len(keys)-1-i]
+		}
+
+		firstHash, err := GenerateHash(first)
+		require.NoError(t, err)
+		secondHash, err := GenerateHash(second)
+		require.NoError(t, err)
+
+		assert.Equal(t, firstHash, secondHash)
+	})
+	t.Run("hash uses truncated sha256", func(t *testing.T) {
+		hash, err := GenerateHash("test")
+		require.NoError(t, err)
+
+		assert.Len(t, hash, 2*hashLength)
+	})
+	t.Run("different input results in different hash", func(t *testing.T) {
+		isDifferent, err := IsDifferent(map[string]string{"a": "b"}, map[string]string{"a": "c"})
+		require.NoError(t, err)
+
+		assert.True(t, isDifferent)
+	})
+}
+
+func newHashedDaemonSet(t *testing.T, nodeName string, hashFunc func(interface{}) (string, error)) *appsv1.DaemonSet {
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace, Annotations: map[string]string{}},
+	}
+	daemonSet.Spec.Template.Spec.NodeName = nodeName
+
+	hash, err := hashFunc(daemonSet)
+	require.NoError(t, err)
+	daemonSet.Annotations[AnnotationHash] = hash
+	return daemonSet
+}
+
+func TestIsHashAnnotationDifferent(t *testing.T) {
+	t.Run("same hash is not different", func(t *testing.T) {
+		current := newHashedDaemonSet(t, "a", GenerateHash)
+		desired := newHashedDaemonSet(t, "a", GenerateHash)
+
+		assert.False(t, IsHashAnnotationDifferent(current, desired))
+	})
+	t.Run("changed object is different", func(t *testing.T) {
+		current := newHashedDaemonSet(t, "a", GenerateHash)
+		desired := newHashedDaemonSet(t, "b", GenerateHash)
+
+		assert.True(t, IsHashAnnotationDifferent(current, desired))
+	})
+	t.Run("unchanged object hashed by a previous version is not different", func(t *testing.T) {
+		current := newHashedDaemonSet(t, "a", generateLegacyHash)
+		desired := newHashedDaemonSet(t, "a", GenerateHash)
+		desiredHash := desired.Annotations[AnnotationHash]
+
+		assert.False(t, IsHashAnnotationDifferent(current, desired))
+		assert.Equal(t, desiredHash, desired.Annotations[AnnotationHash])
+	})
+	t.Run("changed object hashed by a previous version is different", func(t *testing.T) {
+		current := newHashedDaemonSet(t, "a", generateLegacyHash)
+		desired := newHashedDaemonSet(t, "b", GenerateHash)
+
+		assert.True(t, IsHashAnnotationDifferent(current, desired))
+	})
+}
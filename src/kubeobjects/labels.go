@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/Dynatrace/dynatrace-operator/src/version"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -51,7 +52,7 @@ func NewAppLabels(appName, dynakubeName, feature, featureVersion string) *AppLab
 	return &AppLabels{
 		appMatchLabels: appMatchLabels{
 			Name:      appName,
-			CreatedBy: dynakubeName,
+			CreatedBy: createdByLabelValue(dynakubeName),
 			ManagedBy: version.AppName,
 		},
 		Component: strings.ReplaceAll(feature, "_", ""),
@@ -66,7 +67,7 @@ func NewCoreLabels(dynakubeName, component string) *CoreLabels {
 	return &CoreLabels{
 		coreMatchLabels: coreMatchLabels{
 			Name:      version.AppName,
-			CreatedBy: dynakubeName,
+			CreatedBy: createdByLabelValue(dynakubeName),
 			Component: component,
 		},
 		Version: version.Version,
@@ -110,6 +111,11 @@ func (labels *AppLabels) BuildMatchLabels() map[string]string {
 	}
 }
 
+// createdByLabelValue truncates the name of the DynaKube to the length allowed for label values
+func createdByLabelValue(dynakubeName string) string {
+	return TruncateName(dynakubeName, validation.LabelValueMaxLength)
+}
+
 func LabelsNotEqual(currentLabels, desiredLabels map[string]string) bool {
 	return !reflect.DeepEqual(
 		currentLabels,
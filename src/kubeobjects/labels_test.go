@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func TestConstructors(t *testing.T) {
@@ -47,4 +49,14 @@ func TestConstructors(t *testing.T) {
 	t.Run("verify labels for app", func(t *testing.T) {
 		assert.Equal(t, expectedAppLabels, appLabels.BuildLabels())
 	})
+	t.Run("long dynakube names are truncated to a valid label value", func(t *testing.T) {
+		longName := "dynakube-with-a-very-long-name-that-exceeds-the-kubernetes-label-limits"
+		require.Greater(t, len(longName), validation.LabelValueMaxLength)
+
+		createdBy := NewCoreLabels(longName, testComponent).BuildMatchLabels()[AppCreatedByLabel]
+
+		assert.Empty(t, validation.IsValidLabelValue(createdBy))
+		assert.Equal(t, createdBy, NewAppLabels(testComponent, longName, testComponentFeature, testComponentVersion).BuildMatchLabels()[AppCreatedByLabel])
+		assert.NotEqual(t, createdBy, NewCoreLabels(longName+"-2", testComponent).BuildMatchLabels()[AppCreatedByLabel])
+	})
 }
@@ -0,0 +1,23 @@
+package kubeobjects
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// nameHashLength is the number of hex characters of the hash suffix used for truncated names
+const nameHashLength = 8
+
+// TruncateName returns name unchanged if it fits into maxLength, otherwise it is truncated and gets a hash of the full name as suffix,
+// so it stays unique and stable across reconciles
+func TruncateName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:nameHashLength]
+	prefix := strings.TrimRight(name[:maxLength-nameHashLength-1], "-._")
+	return prefix + "-" + hash
+}
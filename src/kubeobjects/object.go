@@ -4,6 +4,7 @@ import (
 	"context"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -19,3 +20,15 @@ func Delete(ctx context.Context, client client.Client, obj client.Object) error
 	}
 	return nil
 }
+
+// DeleteIfExists only deletes obj if it can be found, so no request is sent for missing objects
+// or kinds the cluster doesn't serve, like autoscaling/v2 before Kubernetes 1.23
+func DeleteIfExists(ctx context.Context, client client.Client, obj client.Object) error {
+	err := client.Get(ctx, Key(obj), obj)
+	if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return Delete(ctx, client, obj)
+}
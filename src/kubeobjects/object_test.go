@@ -0,0 +1,68 @@
+package kubeobjects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type deleteCountingClient struct {
+	client.Client
+	getErr  error
+	deletes int
+}
+
+func (clt *deleteCountingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if clt.getErr != nil {
+		return clt.getErr
+	}
+	return clt.Client.Get(ctx, key, obj, opts...)
+}
+
+func (clt *deleteCountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	clt.deletes++
+	return clt.Client.Delete(ctx, obj, opts...)
+}
+
+func newTestHorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+	}
+}
+
+func TestDeleteIfExists(t *testing.T) {
+	t.Run("existing object is deleted", func(t *testing.T) {
+		clt := &deleteCountingClient{Client: fake.NewClient(newTestHorizontalPodAutoscaler())}
+
+		require.NoError(t, DeleteIfExists(context.TODO(), clt, newTestHorizontalPodAutoscaler()))
+
+		assert.Equal(t, 1, clt.deletes)
+		err := clt.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &autoscalingv2.HorizontalPodAutoscaler{})
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run("missing object is not deleted", func(t *testing.T) {
+		clt := &deleteCountingClient{Client: fake.NewClient()}
+
+		require.NoError(t, DeleteIfExists(context.TODO(), clt, newTestHorizontalPodAutoscaler()))
+
+		assert.Zero(t, clt.deletes)
+	})
+	t.Run("kind unknown to the cluster is ignored", func(t *testing.T) {
+		clt := &deleteCountingClient{
+			Client: fake.NewClient(),
+			getErr: &meta.NoKindMatchError{GroupKind: autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler").GroupKind()},
+		}
+
+		require.NoError(t, DeleteIfExists(context.TODO(), clt, newTestHorizontalPodAutoscaler()))
+
+		assert.Zero(t, clt.deletes)
+	})
+}
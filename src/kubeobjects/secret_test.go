@@ -334,13 +334,13 @@ func TestGetDataFromSecretName(t *testing.T) {
 			},
 		})
 
-		value, err := GetDataFromSecretName(client, types.NamespacedName{Name: testSecretName, Namespace: testNamespace}, testKey1, log)
+		value, err := GetDataFromSecretName(context.TODO(), client, types.NamespacedName{Name: testSecretName, Namespace: testNamespace}, testKey1, log)
 
 		assert.NoError(t, err)
 		assert.Equal(t, value, testValue1)
 	})
 	t.Run(`ExtractToken handles missing key`, func(t *testing.T) {
-		value, err := GetDataFromSecretName(fake.NewClient(), types.NamespacedName{Name: testSecretName, Namespace: testNamespace}, testKey1, log)
+		value, err := GetDataFromSecretName(context.TODO(), fake.NewClient(), types.NamespacedName{Name: testSecretName, Namespace: testNamespace}, testKey1, log)
 		assert.Error(t, err)
 		assert.Empty(t, value)
 	})
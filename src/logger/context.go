@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+)
+
+type correlationKey struct{}
+
+// NewCorrelationContext returns a copy of ctx that carries keysAndValues in addition to the ones already stored in ctx.
+// FromContext adds them to the loggers of the sub-reconcilers, so the log lines of one reconcile can be correlated across components.
+func NewCorrelationContext(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	parentValues := correlationValues(ctx)
+	values := make([]interface{}, 0, len(parentValues)+len(keysAndValues))
+	values = append(values, parentValues...)
+	values = append(values, keysAndValues...)
+	return context.WithValue(ctx, correlationKey{}, values)
+}
+
+// FromContext returns log with the correlation values stored in ctx, log is returned unchanged if there are none
+func FromContext(ctx context.Context, log logr.Logger) logr.Logger {
+	values := correlationValues(ctx)
+	if len(values) == 0 {
+		return log
+	}
+	return log.WithValues(values...)
+}
+
+func correlationValues(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	values, _ := ctx.Value(correlationKey{}).([]interface{})
+	return values
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCapturingLogger(logLines *[]string) logr.Logger {
+	return funcr.New(func(_, args string) {
+		*logLines = append(*logLines, args)
+	}, funcr.Options{})
+}
+
+func TestFromContext(t *testing.T) {
+	t.Run(`correlation values are added to the logger`, func(t *testing.T) {
+		var logLines []string
+		ctx := NewCorrelationContext(context.TODO(), "dynakube", "test-name", "reconcileID", "test-id")
+
+		FromContext(ctx, newCapturingLogger(&logLines)).Info("test")
+
+		require.Len(t, logLines, 1)
+		assert.Contains(t, logLines[0], `"dynakube"="test-name"`)
+		assert.Contains(t, logLines[0], `"reconcileID"="test-id"`)
+	})
+	t.Run(`nested contexts keep the values of their parent`, func(t *testing.T) {
+		var logLines []string
+		parent := NewCorrelationContext(context.TODO(), "dynakube", "test-name")
+		ctx := NewCorrelationContext(parent, "capability", "routing")
+		sibling := NewCorrelationContext(parent, "capability", "kubemon")
+
+		FromContext(ctx, newCapturingLogger(&logLines)).Info("test")
+		FromContext(sibling, newCapturingLogger(&logLines)).Info("test")
+
+		require.Len(t, logLines, 2)
+		assert.Contains(t, logLines[0], `"dynakube"="test-name"`)
+		assert.Contains(t, logLines[0], `"capability"="routing"`)
+		assert.Contains(t, logLines[1], `"capability"="kubemon"`)
+	})
+	t.Run(`logger is unchanged without correlation values`, func(t *testing.T) {
+		var logLines []string
+		log := newCapturingLogger(&logLines)
+
+		FromContext(context.TODO(), log).Info("test")
+
+		require.Len(t, logLines, 1)
+		assert.NotContains(t, logLines[0], `"dynakube"`)
+	})
+}
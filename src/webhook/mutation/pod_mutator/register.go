@@ -103,7 +103,7 @@ func getWebhookContainerImage(webhookPod corev1.Pod) (string, error) {
 func getClusterID(apiReader client.Reader) (string, error) {
 	var clusterUID types.UID
 	var err error
-	if clusterUID, err = kubesystem.GetUID(apiReader); err != nil {
+	if clusterUID, err = kubesystem.GetUID(context.TODO(), apiReader); err != nil {
 		return "", errors.WithStack(err)
 	}
 	log.Info("got cluster UID", "clusterUID", clusterUID)
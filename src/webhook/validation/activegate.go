@@ -4,7 +4,10 @@ import (
 	"fmt"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 const (
@@ -17,6 +20,19 @@ Make sure you correctly specify the ActiveGate capabilities in your custom resou
 
 	errorDuplicateActiveGateCapability = `The DynaKube's specification tries to specify duplicate capabilities in the ActiveGate section, duplicate capability=%s.
 Make sure you don't duplicate an Activegate capability in your custom resource.
+`
+
+	errorConflictingCustomPropertiesSources = `The DynaKube's specification tries to set the ActiveGate custom properties from more than one source, which is not supported.
+Make sure you only use one of value, valueFrom and configMapRef in the customProperties section.
+`
+	errorInvalidKubernetesMonitoringSpec = `The DynaKube's specification has an invalid kubernetesMonitoring section: %s.
+Make sure you correct the listed fields in your custom resource.
+`
+	errorReservedActiveGateEnvVar = `The DynaKube's specification tries to set the environment variable %s for the ActiveGate, which is managed by the operator.
+Make sure you remove it from the env section of your custom resource.
+`
+	errorInvalidMaintenanceWindow = `The DynaKube's specification has an invalid ActiveGate maintenance window: %s.
+Make sure you set a cron expression with 5 fields as schedule and a positive duration.
 `
 	warningMissingActiveGateMemoryLimit = `ActiveGate specification missing memory limits. Can cause excess memory usage.`
 )
@@ -57,6 +73,57 @@ func invalidActiveGateCapabilities(dv *dynakubeValidator, dynakube *dynatracev1b
 	return ""
 }
 
+// conflictingCustomPropertiesSources checks the ActiveGate sections, the kubernetesMonitoring section is covered by invalidKubernetesMonitoringSpec
+func conflictingCustomPropertiesSources(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	for _, customProperties := range []*dynatracev1beta1.DynaKubeValueSource{
+		dynakube.Spec.ActiveGate.CustomProperties,
+		dynakube.Spec.Routing.CustomProperties,
+	} {
+		if customProperties.SourceCount() > 1 {
+			log.Info("requested dynakube has conflicting custom properties sources", "name", dynakube.Name, "namespace", dynakube.Namespace)
+			return errorConflictingCustomPropertiesSources
+		}
+	}
+	return ""
+}
+
+func invalidKubernetesMonitoringSpec(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	errs := dynakube.Spec.KubernetesMonitoring.Validate(field.NewPath("spec", "kubernetesMonitoring"))
+	if len(errs) > 0 {
+		log.Info("requested dynakube has an invalid kubernetes monitoring section", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return fmt.Sprintf(errorInvalidKubernetesMonitoringSpec, errs.ToAggregate().Error())
+	}
+	return ""
+}
+
+func reservedActiveGateEnvVars(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	for _, envs := range [][]corev1.EnvVar{
+		dynakube.Spec.ActiveGate.Env,
+		dynakube.Spec.Routing.Env,
+		dynakube.Spec.KubernetesMonitoring.Env,
+	} {
+		for _, env := range envs {
+			if slices.Contains(consts.ReservedEnvVars, env.Name) {
+				log.Info("requested dynakube sets a reserved ActiveGate environment variable", "name", dynakube.Name, "namespace", dynakube.Namespace, "env", env.Name)
+				return fmt.Sprintf(errorReservedActiveGateEnvVar, env.Name)
+			}
+		}
+	}
+	return ""
+}
+
+func invalidMaintenanceWindows(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	var errs field.ErrorList
+	errs = append(errs, dynakube.Spec.ActiveGate.MaintenanceWindow.Validate(field.NewPath("spec", "activeGate", "maintenanceWindow"))...)
+	errs = append(errs, dynakube.Spec.Routing.MaintenanceWindow.Validate(field.NewPath("spec", "routing", "maintenanceWindow"))...)
+	errs = append(errs, dynakube.Spec.KubernetesMonitoring.MaintenanceWindow.Validate(field.NewPath("spec", "kubernetesMonitoring", "maintenanceWindow"))...)
+	if len(errs) > 0 {
+		log.Info("requested dynakube has an invalid maintenance window", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return fmt.Sprintf(errorInvalidMaintenanceWindow, errs.ToAggregate().Error())
+	}
+	return ""
+}
+
 func missingActiveGateMemoryLimit(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
 	if dynakube.ActiveGateMode() {
 		if !memoryLimitSet(dynakube.Spec.ActiveGate.Resources) {
@@ -3,10 +3,15 @@ package validation
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/consts"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 func TestConflictingActiveGateConfiguration(t *testing.T) {
@@ -109,6 +114,183 @@ func TestInvalidActiveGateCapabilities(t *testing.T) {
 	})
 }
 
+func TestConflictingCustomPropertiesSources(t *testing.T) {
+	t.Run(`single custom properties source`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				Routing: dynatracev1beta1.RoutingSpec{
+					Enabled: true,
+					CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+						CustomProperties: &dynatracev1beta1.DynaKubeValueSource{
+							ConfigMapRef: "custom-properties",
+						},
+					},
+				},
+			},
+		})
+	})
+	t.Run(`inline value and config map`, func(t *testing.T) {
+		assertDeniedResponse(t,
+			[]string{errorConflictingCustomPropertiesSources},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: defaultDynakubeObjectMeta,
+				Spec: dynatracev1beta1.DynaKubeSpec{
+					APIURL: testApiUrl,
+					Routing: dynatracev1beta1.RoutingSpec{
+						Enabled: true,
+						CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+							CustomProperties: &dynatracev1beta1.DynaKubeValueSource{
+								Value:        "[connectivity]\nnetworkZone=zone",
+								ConfigMapRef: "custom-properties",
+							},
+						},
+					},
+				},
+			})
+	})
+}
+
+func TestInvalidKubernetesMonitoringSpec(t *testing.T) {
+	t.Run(`valid kubernetes monitoring section`, func(t *testing.T) {
+		replicas := int32(1)
+		assertAllowedResponseWithoutWarnings(t, &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				KubernetesMonitoring: dynatracev1beta1.KubernetesMonitoringSpec{
+					Enabled: true,
+					CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+						Image:    "registry.example.com/activegate:1.2.3",
+						Replicas: &replicas,
+					},
+				},
+			},
+		})
+	})
+	t.Run(`negative replicas`, func(t *testing.T) {
+		replicas := int32(-1)
+		expectedErr := field.Invalid(field.NewPath("spec", "kubernetesMonitoring", "replicas"), replicas, "must be greater than or equal to 0")
+		assertDeniedResponse(t,
+			[]string{fmt.Sprintf(errorInvalidKubernetesMonitoringSpec, expectedErr.Error())},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: defaultDynakubeObjectMeta,
+				Spec: dynatracev1beta1.DynaKubeSpec{
+					APIURL: testApiUrl,
+					KubernetesMonitoring: dynatracev1beta1.KubernetesMonitoringSpec{
+						Enabled: true,
+						CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+							Replicas: &replicas,
+						},
+					},
+				},
+			})
+	})
+}
+
+func TestInvalidMaintenanceWindows(t *testing.T) {
+	t.Run(`valid maintenance window`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				KubernetesMonitoring: dynatracev1beta1.KubernetesMonitoringSpec{
+					Enabled: true,
+					CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+						AutoUpdate: address.Of(true),
+						MaintenanceWindow: &dynatracev1beta1.MaintenanceWindow{
+							Schedule: "0 22 * * 1-5",
+							Duration: metav1.Duration{Duration: 2 * time.Hour},
+						},
+					},
+				},
+			},
+		})
+	})
+	t.Run(`invalid schedule`, func(t *testing.T) {
+		window := &dynatracev1beta1.MaintenanceWindow{
+			Schedule: "every night",
+			Duration: metav1.Duration{Duration: 2 * time.Hour},
+		}
+		assertDeniedResponse(t,
+			[]string{fmt.Sprintf(errorInvalidMaintenanceWindow, window.Validate(field.NewPath("spec", "routing", "maintenanceWindow")).ToAggregate().Error())},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: defaultDynakubeObjectMeta,
+				Spec: dynatracev1beta1.DynaKubeSpec{
+					APIURL: testApiUrl,
+					Routing: dynatracev1beta1.RoutingSpec{
+						Enabled: true,
+						CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+							MaintenanceWindow: window,
+						},
+					},
+				},
+			})
+	})
+}
+
+func TestReservedActiveGateEnvVars(t *testing.T) {
+	t.Run(`custom env vars are allowed`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				KubernetesMonitoring: dynatracev1beta1.KubernetesMonitoringSpec{
+					Enabled: true,
+					CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+						Env: []corev1.EnvVar{
+							{Name: "CUSTOM_ENV", Value: "custom"},
+							{Name: "CUSTOM_ENV_FROM_CONFIGMAP", ValueFrom: &corev1.EnvVarSource{
+								ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "activegate-env"},
+									Key:                  "custom",
+								},
+							}},
+						},
+					},
+				},
+			},
+		})
+	})
+	t.Run(`reserved env var`, func(t *testing.T) {
+		assertDeniedResponse(t,
+			[]string{fmt.Sprintf(errorReservedActiveGateEnvVar, consts.EnvDtCapabilities)},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: defaultDynakubeObjectMeta,
+				Spec: dynatracev1beta1.DynaKubeSpec{
+					APIURL: testApiUrl,
+					KubernetesMonitoring: dynatracev1beta1.KubernetesMonitoringSpec{
+						Enabled: true,
+						CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+							Env: []corev1.EnvVar{{Name: consts.EnvDtCapabilities, Value: "custom"}},
+						},
+					},
+				},
+			})
+	})
+	t.Run(`reserved group and network zone env vars`, func(t *testing.T) {
+		for _, envName := range []string{consts.EnvDtGroup, consts.EnvDtNetworkZone} {
+			assertDeniedResponse(t,
+				[]string{fmt.Sprintf(errorReservedActiveGateEnvVar, envName)},
+				&dynatracev1beta1.DynaKube{
+					ObjectMeta: defaultDynakubeObjectMeta,
+					Spec: dynatracev1beta1.DynaKubeSpec{
+						APIURL: testApiUrl,
+						ActiveGate: dynatracev1beta1.ActiveGateSpec{
+							Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+								dynatracev1beta1.RoutingCapability.DisplayName,
+							},
+							CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+								Env: []corev1.EnvVar{{Name: envName, Value: "custom"}},
+							},
+						},
+					},
+				})
+		}
+	})
+}
+
 func TestMissingActiveGateMemoryLimit(t *testing.T) {
 	t.Run(`memory warning in activeGate mode`, func(t *testing.T) {
 		assertAllowedResponseWithWarnings(t, 1,
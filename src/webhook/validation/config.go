@@ -19,6 +19,10 @@ var validators = []validator{
 	conflictingActiveGateConfiguration,
 	invalidActiveGateCapabilities,
 	duplicateActiveGateCapabilities,
+	conflictingCustomPropertiesSources,
+	invalidKubernetesMonitoringSpec,
+	reservedActiveGateEnvVars,
+	invalidMaintenanceWindows,
 	invalidActiveGateProxyUrl,
 	conflictingOneAgentConfiguration,
 	conflictingNodeSelector,